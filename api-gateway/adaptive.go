@@ -0,0 +1,25 @@
+package apigateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalibrateQuiz handles the request to recalibrate the item parameters of a quiz's questions from their
+// recorded answers. It must only be routed behind RequireAuthor.
+func (h *Handler) CalibrateQuiz(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	calibrations, err := h.service.CalibrateQuiz(ctx, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, calibrations)
+}
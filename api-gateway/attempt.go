@@ -0,0 +1,119 @@
+package apigateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"fasttrack/quiz-app/service"
+)
+
+// StartAttempt handles the request to open a new quiz attempt.
+func (h *Handler) StartAttempt(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	attempt, err := h.service.StartAttempt(ctx)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attempt)
+}
+
+// GetAttempt handles the request to resume an attempt.
+func (h *Handler) GetAttempt(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	attempt, err := h.service.GetAttempt(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempt)
+}
+
+// ServeQuestion handles the request to show one question of an attempt, starting its timer.
+func (h *Handler) ServeQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	question, err := h.service.ServeQuestion(ctx, c.Param("id"), questionID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// SaveAnswer handles the request to save the answer to one question of an attempt.
+func (h *Handler) SaveAnswer(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var answer service.Answer
+	if err := c.ShouldBindJSON(&answer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := h.service.SaveAnswer(ctx, c.Param("id"), answer); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// FinishAttempt handles the request to grade an attempt and returns the score and comparison.
+func (h *Handler) FinishAttempt(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	serviceResponse, err := h.service.FinishAttempt(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIResponse(serviceResponse))
+}
+
+// ReviewAttempt handles the request for a finished attempt's review: each question with the player's
+// answer, the correct answer and the explanations, when the quiz's review policy allows it.
+func (h *Handler) ReviewAttempt(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	review, err := h.service.ReviewAttempt(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// RevealHint handles the request for the next hint to one question of an attempt.
+func (h *Handler) RevealHint(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	hint, err := h.service.RevealHint(ctx, c.Param("id"), questionID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hint)
+}
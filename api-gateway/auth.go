@@ -0,0 +1,46 @@
+package apigateway
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"fasttrack/quiz-app/service"
+)
+
+// PlayerTokenHeader carries the token a player received when registering.
+const PlayerTokenHeader = "X-Player-Token"
+
+// RequireAuthor rejects requests that do not carry "Authorization: Bearer <token>".
+// An empty token disables the authoring routes entirely rather than leaving them open.
+func RequireAuthor(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Author credentials required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IdentifyPlayer attaches the player identified by the X-Player-Token header to the request context.
+// Requests without the header stay anonymous; an unknown token is rejected rather than silently ignored.
+func (h *Handler) IdentifyPlayer(c *gin.Context) {
+	token := c.GetHeader(PlayerTokenHeader)
+	if token == "" {
+		c.Next()
+		return
+	}
+
+	player, err := h.service.AuthenticatePlayer(c.Request.Context(), token)
+	if err != nil {
+		c.AbortWithStatusJSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request = c.Request.WithContext(service.WithPlayer(c.Request.Context(), player.ID))
+	c.Next()
+}
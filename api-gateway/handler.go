@@ -1,7 +1,11 @@
 package apigateway
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,8 +14,32 @@ import (
 
 // APIResponse is a simple structure for the API gateway layer response.
 type APIResponse struct {
-	Score      int    `json:"score"`
-	Comparison string `json:"comparison"`
+	Score      float64                  `json:"score"`
+	MaxScore   float64                  `json:"max_score"`
+	Percentage float64                  `json:"percentage"`
+	Comparison string                   `json:"comparison"`
+	Standing   service.Standing         `json:"standing"`
+	Results    []service.QuestionResult `json:"results"`
+	// ElapsedSeconds is how long a timed attempt took; zero for untimed submissions.
+	ElapsedSeconds float64 `json:"elapsed_seconds,omitempty"`
+}
+
+// toAPIResponse converts a service submission response to the API format.
+func toAPIResponse(response service.SubmitResponse) APIResponse {
+	return APIResponse{
+		Score:          response.Score,
+		MaxScore:       response.MaxScore,
+		Percentage:     response.Percentage,
+		Comparison:     response.Comparison,
+		Standing:       response.Standing,
+		Results:        response.Results,
+		ElapsedSeconds: response.ElapsedSeconds,
+	}
+}
+
+// SubmitRequest is the submission payload keyed by question ID.
+type SubmitRequest struct {
+	Answers []service.Answer `json:"answers"`
 }
 
 type Handler struct {
@@ -23,7 +51,7 @@ func NewHandler(service service.QuizService) *Handler {
 	return &Handler{service: service}
 }
 
-// GetQuestions handles the request for fetching questions.
+// GetQuestions handles the request for fetching questions; the answer keys are never included.
 func (h *Handler) GetQuestions(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -35,30 +63,58 @@ func (h *Handler) GetQuestions(c *gin.Context) {
 	c.JSON(http.StatusOK, questions)
 }
 
+// GetAuthorQuestions handles the request for fetching questions with their answer keys.
+// It must only be routed behind RequireAuthor.
+func (h *Handler) GetAuthorQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	questions, err := h.service.GetAuthorQuestions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
 // SubmitAnswers handles the request for submitting answers and returns the score and comparison.
+// The body is either {"answers":[{"question_id":1,"choice":2},{"question_id":3,"choices":[0,2]}]}
+// or, deprecated, a positional array of choices.
 func (h *Handler) SubmitAnswers(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	var userAnswers []int
-	if err := c.ShouldBindJSON(&userAnswers); err != nil {
+	body, err := c.GetRawData()
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
 	// Call the service layer to get the business logic response
-	serviceResponse, err := h.service.SubmitAnswers(ctx, userAnswers)
+	var serviceResponse service.SubmitResponse
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var userAnswers []int
+		if err := json.Unmarshal(trimmed, &userAnswers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		c.Header("Deprecation", "true")
+		serviceResponse, err = h.service.SubmitAnswers(ctx, userAnswers)
+	} else {
+		var request SubmitRequest
+		if err := json.Unmarshal(trimmed, &request); err != nil || request.Answers == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		serviceResponse, err = h.service.SubmitAnswersByID(ctx, request.Answers)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	// Convert service response to API response
-	apiResponse := APIResponse{
-		Score:      serviceResponse.Score,
-		Comparison: serviceResponse.Comparison,
-	}
-
-	c.JSON(http.StatusOK, apiResponse)
+	c.JSON(http.StatusOK, toAPIResponse(serviceResponse))
 }
 
 // AddQuestion handles the request to add a new question.
@@ -73,9 +129,146 @@ func (h *Handler) AddQuestion(c *gin.Context) {
 
 	err := h.service.AddQuestion(ctx, newQuestion)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Question added successfully"})
-}
\ No newline at end of file
+}
+
+// UpdateQuestion handles the request to replace an existing question.
+func (h *Handler) UpdateQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := questionID(c)
+	if !ok {
+		return
+	}
+
+	var question service.Question
+	if err := c.ShouldBindJSON(&question); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	question.ID = id // the path is authoritative
+
+	updated, err := h.service.UpdateQuestion(ctx, question)
+	if err != nil {
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// PatchQuestion handles the request to update some fields of an existing question.
+func (h *Handler) PatchQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := questionID(c)
+	if !ok {
+		return
+	}
+
+	var patch service.QuestionPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	question, err := h.service.PatchQuestion(ctx, id, patch)
+	if err != nil {
+		c.JSON(updateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+// DeleteQuestion handles the request to remove a question.
+func (h *Handler) DeleteQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := questionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteQuestion(ctx, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// questionID parses the :id path parameter, writing a 400 response if it is not an integer.
+func questionID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// updateErrorStatus maps the errors of changing an existing question: an answer key the change leaves
+// outside the alternatives conflicts with the stored question, where for a new question it is just invalid.
+func updateErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidAnswer) {
+		return http.StatusConflict
+	}
+	return errorStatus(err)
+}
+
+// errorStatus maps service errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound),
+		errors.Is(err, service.ErrAttemptNotFound),
+		errors.Is(err, service.ErrQuizNotFound),
+		errors.Is(err, service.ErrPlayerNotFound),
+		errors.Is(err, service.ErrNotOnLeaderboard),
+		errors.Is(err, service.ErrMediaNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrQuizExists),
+		errors.Is(err, service.ErrDefaultQuiz),
+		errors.Is(err, service.ErrQuestionExists),
+		errors.Is(err, service.ErrAttemptFinished),
+		errors.Is(err, service.ErrDeadlinePassed),
+		errors.Is(err, service.ErrPoolTooSmall),
+		errors.Is(err, service.ErrAnswerLocked),
+		errors.Is(err, service.ErrNoMoreHints),
+		errors.Is(err, service.ErrAttemptNotFinished):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrAttemptExpired):
+		return http.StatusGone
+	case errors.Is(err, service.ErrInvalidToken), errors.Is(err, service.ErrIdentityRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrReviewUnavailable):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrMediaTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrUnsupportedMedia):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrMediaUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrInvalidQuiz),
+		errors.Is(err, service.ErrInvalidPlayer),
+		errors.Is(err, service.ErrInvalidQuestion),
+		errors.Is(err, service.ErrInvalidAnswer),
+		errors.Is(err, service.ErrUnknownQuestion),
+		errors.Is(err, service.ErrChoiceOutOfRange),
+		errors.Is(err, service.ErrDuplicateAnswer),
+		errors.Is(err, service.ErrDuplicateChoice),
+		errors.Is(err, service.ErrWrongAnswerType),
+		errors.Is(err, service.ErrIncompleteAnswer),
+		errors.Is(err, service.ErrAttemptRequired),
+		errors.Is(err, service.ErrInvalidLeaderboard),
+		errors.Is(err, service.ErrInvalidStatistics),
+		errors.Is(err, service.ErrInvalidPractice):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
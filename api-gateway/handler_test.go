@@ -0,0 +1,662 @@
+package apigateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+	"fasttrack/quiz-app/service"
+)
+
+const testAuthorToken = "secret"
+
+// newTestRouter wires a handler over a real in-memory repository seeded with one question.
+func newTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewRepository()
+	err := repo.AddQuestion(context.Background(), repository.Question{
+		ID:            1,
+		QuestionText:  "What is the capital of France?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	})
+	require.NoError(t, err)
+
+	handler := NewHandler(service.NewQuizService(repo))
+
+	router := gin.New()
+	router.Use(handler.IdentifyPlayer)
+	router.POST("/players", handler.RegisterPlayer)
+	router.GET("/me/scores", handler.GetPlayerScores)
+	router.GET("/questions", handler.GetQuestions)
+	router.POST("/submit", handler.SubmitAnswers)
+	router.GET("/quizzes", handler.GetQuizzes)
+	router.GET("/quizzes/:id/questions", handler.GetQuizQuestions)
+	router.POST("/quizzes/:id/submit", handler.SubmitQuizAnswers)
+	router.POST("/quizzes/:id/attempts", handler.StartQuizAttempt)
+	router.POST("/attempts/:id/finish", handler.FinishAttempt)
+	router.GET("/attempts/:id/review", handler.ReviewAttempt)
+	router.POST("/attempts/:id/questions/:question_id/hints", handler.RevealHint)
+	router.GET("/quizzes/:id/leaderboard", handler.GetLeaderboard)
+	router.GET("/quizzes/:id/practice", handler.GetDueQuestions)
+	router.POST("/quizzes/:id/practice", handler.RecordReview)
+	author := router.Group("/", RequireAuthor(testAuthorToken))
+	author.GET("/author/questions", handler.GetAuthorQuestions)
+	author.POST("/add-question", handler.AddQuestion)
+	author.PUT("/questions/:id", handler.UpdateQuestion)
+	author.PATCH("/questions/:id", handler.PatchQuestion)
+	author.POST("/quizzes", handler.CreateQuiz)
+	author.PUT("/quizzes/:id", handler.UpdateQuiz)
+	author.DELETE("/quizzes/:id", handler.DeleteQuiz)
+	author.POST("/quizzes/:id/questions", handler.AddQuizQuestion)
+	author.GET("/author/quizzes/:id/statistics", handler.GetScoreStatistics)
+	author.GET("/author/quizzes/:id/items", handler.GetItemAnalysis)
+	author.POST("/author/quizzes/:id/calibrate", handler.CalibrateQuiz)
+	return router
+}
+
+func TestHandler_GetQuestions_HidesAnswerKey(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/questions", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var payload []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+	require.Len(t, payload, 1, "There should be 1 question")
+
+	assert.Equal(t, "What is the capital of France?", payload[0]["question"])
+	assert.NotContains(t, payload[0], "correct_answer", "The public payload must not expose the answer key")
+}
+
+func TestHandler_GetAuthorQuestions_RequiresToken(t *testing.T) {
+	router := newTestRouter(t)
+
+	// Without credentials
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/author/questions", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "Anonymous requests should be rejected")
+
+	// With a wrong token
+	req := httptest.NewRequest(http.MethodGet, "/author/questions", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "A wrong token should be rejected")
+
+	// With the author token the answer key is included
+	req = httptest.NewRequest(http.MethodGet, "/author/questions", nil)
+	req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var payload []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+	require.Len(t, payload, 1, "There should be 1 question")
+	assert.EqualValues(t, 2, payload[0]["correct_answer"], "Authors should see the answer key")
+}
+
+func TestHandler_SubmitAnswers(t *testing.T) {
+	router := newTestRouter(t)
+
+	// Answers keyed by question ID
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{"answers":[{"question_id":1,"choice":2}]}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1.0, response.Score, "The score should be 1")
+	assert.Equal(t, []service.QuestionResult{{QuestionID: 1, Status: service.ResultCorrect, Points: 1}}, response.Results)
+
+	// The deprecated positional form still works
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`[0]`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"), "The positional form should be flagged as deprecated")
+
+	// Unknown questions and out-of-range choices are client errors
+	for _, body := range []string{
+		`{"answers":[{"question_id":99,"choice":0}]}`,
+		`{"answers":[{"question_id":1,"choice":4}]}`,
+		`{"answers":"nope"}`,
+	} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "Body %s should be rejected", body)
+	}
+}
+
+func TestHandler_MultiSelectQuestion(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusCreated, serve("/add-question",
+		`{"id":2,"type":"multi","question":"Which are primes?","alternatives":["2","4","5"],"correct_answers":[0,2],"grading":"partial"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/add-question",
+		`{"id":3,"type":"multi","question":"Which are primes?","alternatives":["2","4"],"correct_answers":[0],"grading":"lenient"}`).Code)
+
+	rec := serve("/submit", `{"answers":[{"question_id":1,"choice":2},{"question_id":2,"choices":[0]}]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1.5, response.Score, "One correct pick out of two should earn half a point")
+	assert.Equal(t, service.QuestionResult{QuestionID: 2, Status: service.ResultPartial, Points: 0.5}, response.Results[1])
+
+	// Choices are only accepted for multi-select questions
+	assert.Equal(t, http.StatusBadRequest, serve("/submit", `{"answers":[{"question_id":1,"choices":[2]}]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/submit", `{"answers":[{"question_id":2,"choices":[0,0]}]}`).Code)
+}
+
+func TestHandler_InvalidAnswerKey(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A new question with its answer outside the alternatives is invalid
+	body := `{"id":2,"question":"What is 2 + 2?","alternatives":["3","4"],"correct_answer":5}`
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/add-question", body).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/quizzes/1/questions", body).Code)
+
+	// A change that leaves the stored answer outside the alternatives conflicts with the question
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPatch, "/questions/1", `{"alternatives":["Berlin","Madrid"]}`).Code)
+}
+
+func TestHandler_TypedQuestions(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusCreated, serve("/add-question",
+		`{"id":2,"type":"short-answer","question":"What is the chemical symbol for Gold?","accepted_answers":["Au"],"fuzzy_distance":1}`).Code)
+	require.Equal(t, http.StatusCreated, serve("/add-question",
+		`{"id":3,"type":"numeric","question":"What is 22 / 7?","numeric_answer":3.142857,"tolerance":0.01}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/add-question",
+		`{"id":4,"type":"numeric","question":"What is 1 / 3?","numeric_answer":0.333,"tolerance_mode":"percent"}`).Code)
+
+	rec := serve("/submit", `{"answers":[{"question_id":2,"text":" AU "},{"question_id":3,"number":3.14}]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 2.0, response.Score)
+
+	assert.Equal(t, http.StatusBadRequest, serve("/submit", `{"answers":[{"question_id":3,"choice":0}]}`).Code,
+		"A numeric question cannot be answered with a choice")
+
+	// Ordering and matching questions share the same contract, told apart by their type
+	require.Equal(t, http.StatusCreated, serve("/add-question",
+		`{"id":5,"type":"ordering","question":"Oldest first","alternatives":["Rome","Athens"],"correct_order":[1,0],"grading":"kendall-tau"}`).Code)
+	require.Equal(t, http.StatusCreated, serve("/add-question",
+		`{"id":6,"type":"matching","question":"Capitals","prompts":["France","Italy"],"alternatives":["Rome","Paris"],"correct_matches":[1,0]}`).Code)
+
+	rec = serve("/submit", `{"answers":[{"question_id":5,"order":[1,0]},{"question_id":6,"matches":[1,1]}]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, service.QuestionResult{QuestionID: 6, Status: service.ResultPartial, Points: 0.5}, response.Results[4])
+	assert.Equal(t, http.StatusBadRequest, serve("/submit", `{"answers":[{"question_id":5,"order":[1]}]}`).Code)
+}
+
+func TestHandler_Quizzes(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body string, author bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if author {
+			req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Creating quizzes and their questions is an authoring operation
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/quizzes", `{"id":2,"name":"Maths"}`, false).Code)
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/quizzes", `{"id":2,"name":"Maths"}`, true).Code)
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/quizzes", `{"id":2,"name":"Maths"}`, true).Code)
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/quizzes/2/questions",
+		`{"id":7,"question":"What is 2 + 2?","alternatives":["3","4"],"correct_answer":1}`, true).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/quizzes/9/questions",
+		`{"id":8,"question":"Orphan?","alternatives":["Yes"],"correct_answer":0}`, true).Code)
+
+	rec := serve(http.MethodGet, "/quizzes/2/questions", "", false)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var questions []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &questions))
+	require.Len(t, questions, 1, "Only the quiz's own question should be served")
+	assert.EqualValues(t, 7, questions[0]["id"])
+
+	// Submissions are graded against the quiz in the path
+	rec = serve(http.MethodPost, "/quizzes/2/submit", `{"answers":[{"question_id":7,"choice":1}]}`, false)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1.0, response.Score)
+	assert.Equal(t, 1, response.Standing.Of, "Scores of other quizzes should not be compared")
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/quizzes/2/submit", `{"answers":[{"question_id":1,"choice":2}]}`, false).Code)
+
+	// Each quiz picks its scoring strategy; responses carry the maximum and percentage
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/quizzes/2", `{"name":"Maths","scoring":"mystery"}`, true).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/quizzes/2", `{"name":"Maths","scoring":"weighted"}`, true).Code)
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/quizzes/2/questions",
+		`{"id":8,"question":"What is 3 x 3?","alternatives":["6","9"],"correct_answer":1,"weight":3}`, true).Code)
+	rec = serve(http.MethodPost, "/quizzes/2/submit", `{"answers":[{"question_id":8,"choice":1}]}`, false)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 3.0, response.Score)
+	assert.Equal(t, 4.0, response.MaxScore)
+	assert.Equal(t, 75.0, response.Percentage)
+
+	// Quizzes with draw rules are only taken through attempts, which need enough questions in each pool
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/quizzes/2", `{"name":"Maths","draw":[{"pool":"hard","count":0}]}`, true).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/quizzes/2", `{"name":"Maths","draw":[{"pool":"hard","count":1}]}`, true).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/quizzes/2/submit", `{"answers":[{"question_id":8,"choice":1}]}`, false).Code)
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/quizzes/2/attempts", "", false).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPatch, "/questions/8", `{"pool":"hard"}`, true).Code)
+	rec = serve(http.MethodPost, "/quizzes/2/attempts", "", false)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var attempt service.Attempt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &attempt))
+	require.Len(t, attempt.Questions, 1)
+	assert.Equal(t, 8, attempt.Questions[0].ID)
+
+	// The default quiz backs the original routes and cannot be deleted
+	assert.Equal(t, http.StatusConflict, serve(http.MethodDelete, "/quizzes/1", "", true).Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/quizzes/2", "", true).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/quizzes/2/questions", "", false).Code)
+}
+
+func TestHandler_UpdateQuestion_KeepsQuiz(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/quizzes", `{"id":2,"name":"Geography"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(http.MethodPost, "/quizzes/2/questions",
+		`{"id":2,"question":"What is the capital of Spain?","alternatives":["Lisbon","Madrid"],"correct_answer":1}`).Code)
+
+	// A replacement without a quiz answers with the quiz the question stays in
+	rec := serve(http.MethodPut, "/questions/2",
+		`{"question":"What is the capital of Italy?","alternatives":["Paris","Rome"],"correct_answer":1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var question service.Question
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &question))
+	assert.Equal(t, 2, question.ID)
+	assert.Equal(t, 2, question.QuizID)
+	assert.Equal(t, "What is the capital of Italy?", question.Question)
+}
+
+func TestHandler_PlayerIdentity(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(PlayerTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/players", `{"name":"Ada"}`, "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var registration service.PlayerRegistration
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &registration))
+	require.NotEmpty(t, registration.Token)
+
+	// Submissions made with the token show up in the player's history
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/submit", `{"answers":[{"question_id":1,"choice":2}]}`, registration.Token).Code)
+
+	rec = serve(http.MethodGet, "/me/scores", "", registration.Token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var scores []service.Score
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &scores))
+	require.Len(t, scores, 1)
+	assert.Equal(t, 1.0, scores[0].Score)
+	assert.Equal(t, 1.0, scores[0].MaxScore)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/me/scores", "", "").Code, "History requires an identity")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/questions", "", "forged").Code, "Unknown tokens are rejected")
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/players", `{}`, "").Code)
+}
+
+func TestHandler_GetLeaderboard(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(PlayerTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var tokens []string
+	for _, body := range []string{`{"name":"Ada"}`, `{"name":"Bob"}`} {
+		rec := serve(http.MethodPost, "/players", body, "")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var registration service.PlayerRegistration
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &registration))
+		tokens = append(tokens, registration.Token)
+	}
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/submit", `{"answers":[{"question_id":1,"choice":1}]}`, tokens[0]).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/submit", `{"answers":[{"question_id":1,"choice":2}]}`, tokens[1]).Code)
+
+	rec := serve(http.MethodGet, "/quizzes/1/leaderboard?window=daily&tie_break=fastest&limit=1", "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var board service.Leaderboard
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &board))
+	assert.Equal(t, 2, board.Players)
+	require.Len(t, board.Entries, 1)
+	assert.Equal(t, "Bob", board.Entries[0].PlayerName)
+
+	rec = serve(http.MethodGet, "/quizzes/1/leaderboard?around=me&limit=1", "", tokens[0])
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &board))
+	require.Len(t, board.Entries, 1)
+	assert.Equal(t, 2, board.Entries[0].Rank)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/quizzes/1/leaderboard?around=me", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/quizzes/1/leaderboard?window=monthly", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/quizzes/1/leaderboard?limit=ten", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/quizzes/1/leaderboard?around=you", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/quizzes/999/leaderboard", "", "").Code)
+}
+
+func TestHandler_GetScoreStatistics(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(path string, author bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if author {
+			req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, choice := range []string{"1", "2", "2"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{"answers":[{"question_id":1,"choice":`+choice+`}]}`)))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("/author/quizzes/1/statistics", false).Code, "Statistics are for authors")
+
+	rec := serve("/author/quizzes/1/statistics?buckets=2", true)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var statistics service.Statistics
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statistics))
+	assert.Equal(t, 3, statistics.Count)
+	assert.Equal(t, 100.0, statistics.Median)
+	assert.Equal(t, []service.HistogramBucket{{FromPercent: 0, ToPercent: 50, Count: 1}, {FromPercent: 50, ToPercent: 100, Count: 2}}, statistics.Histogram)
+
+	rec = serve("/author/quizzes/1/statistics?from=2000-01-01T00:00:00Z&to=2000-01-02T00:00:00Z", true)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statistics))
+	assert.Equal(t, 0, statistics.Count, "No scores were recorded in the range")
+	assert.Len(t, statistics.Histogram, service.DefaultHistogramBuckets)
+
+	assert.Equal(t, http.StatusBadRequest, serve("/author/quizzes/1/statistics?from=yesterday", true).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/author/quizzes/1/statistics?buckets=0.5", true).Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/author/quizzes/1/statistics?buckets=1000", true).Code)
+	assert.Equal(t, http.StatusNotFound, serve("/author/quizzes/9/statistics", true).Code)
+}
+
+func TestHandler_GetItemAnalysis(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, choice := range []string{"2", "2", "0"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(`{"answers":[{"question_id":1,"choice":`+choice+`}]}`)))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/author/quizzes/1/items", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "Item analysis is for authors")
+
+	req := httptest.NewRequest(http.MethodGet, "/author/quizzes/1/items", nil)
+	req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var items []service.ItemAnalysis
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, 3, items[0].Responses)
+	assert.InDelta(t, 2.0/3, items[0].Difficulty, 1e-9)
+	require.Len(t, items[0].Distractors, 4)
+	assert.Equal(t, 1, items[0].Distractors[0].Picks, "Berlin was picked once")
+	assert.True(t, items[0].Distractors[2].Correct)
+}
+
+func TestHandler_CalibrateQuiz(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/author/quizzes/1/calibrate", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "Calibration is for authors")
+
+	calibrate := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec = calibrate("/author/quizzes/1/calibrate")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var calibrations []service.ItemCalibration
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &calibrations))
+	require.Len(t, calibrations, 1)
+	assert.False(t, calibrations[0].Calibrated, "A question nobody answered cannot be calibrated")
+
+	assert.Equal(t, http.StatusNotFound, calibrate("/author/quizzes/9/calibrate").Code)
+}
+
+func TestHandler_Practice(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(PlayerTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/players", `{"name":"Ada"}`, "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var registration service.PlayerRegistration
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &registration))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/quizzes/1/practice", "", "").Code, "Practice requires an identity")
+
+	// A new question is due at once; once reviewed it is scheduled for tomorrow
+	rec = serve(http.MethodGet, "/quizzes/1/practice", "", registration.Token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var due []service.DueQuestion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &due))
+	require.Len(t, due, 1)
+	assert.Nil(t, due[0].Due)
+	assert.NotContains(t, rec.Body.String(), "correct_answer")
+
+	rec = serve(http.MethodPost, "/quizzes/1/practice", `{"question_id":1,"choice":2}`, registration.Token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var review service.Review
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &review))
+	assert.Equal(t, 1.0, review.Points)
+	assert.Equal(t, 1, review.IntervalDays)
+
+	rec = serve(http.MethodGet, "/quizzes/1/practice", "", registration.Token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	// Practice is not competitive play
+	rec = serve(http.MethodGet, "/me/scores", "", registration.Token)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/quizzes/1/practice", `{"question_id":7,"choice":0}`, registration.Token).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/quizzes/1/practice?limit=-1", "", registration.Token).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/quizzes/9/practice", "", registration.Token).Code)
+}
+
+func TestHandler_ReviewAttempt(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/quizzes/1/attempts")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var attempt service.Attempt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &attempt))
+
+	assert.Equal(t, http.StatusConflict, serve(http.MethodGet, "/attempts/"+attempt.ID+"/review").Code, "Only finished attempts can be reviewed")
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/attempts/"+attempt.ID+"/finish").Code)
+
+	rec = serve(http.MethodGet, "/attempts/"+attempt.ID+"/review")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var review service.AttemptReview
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &review))
+	require.Len(t, review.Questions, 1)
+	assert.Equal(t, service.ResultMissing, review.Questions[0].Status)
+	assert.Equal(t, 2, review.Questions[0].Correct.Choice)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/attempts/missing/review").Code)
+}
+
+func TestHandler_RevealHint(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, body string, author bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if author {
+			req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPatch, "/questions/1", `{"hints":["It is on the Seine"],"hint_penalty":0.5}`, true).Code)
+	rec := serve(http.MethodPost, "/quizzes/1/attempts", "", false)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var attempt service.Attempt
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &attempt))
+	require.Len(t, attempt.Questions, 1)
+	assert.Equal(t, 1, attempt.Questions[0].HintCount)
+	assert.NotContains(t, rec.Body.String(), "Seine", "Hints stay hidden until revealed")
+
+	rec = serve(http.MethodPost, "/attempts/"+attempt.ID+"/questions/1/hints", "", false)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var hint service.Hint
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &hint))
+	assert.Equal(t, service.Hint{QuestionID: 1, Number: 1, Text: "It is on the Seine", TextHTML: "<p>It is on the Seine</p>", Penalty: 0.5}, hint)
+
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/attempts/"+attempt.ID+"/questions/1/hints", "", false).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/attempts/"+attempt.ID+"/questions/x/hints", "", false).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/attempts/missing/questions/1/hints", "", false).Code)
+}
+
+func TestHandler_Media(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := repository.NewFileMediaStore(t.TempDir())
+	require.NoError(t, err)
+	handler := NewHandler(service.NewQuizService(repository.NewRepository(), service.WithMediaStore(store), service.WithMaxMediaSize(1024)))
+	router := gin.New()
+	router.GET("/media/:id", handler.GetMedia)
+	router.Group("/", RequireAuthor(testAuthorToken)).POST("/media", handler.UploadMedia)
+
+	upload := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/media", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+testAuthorToken)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	image := []byte("GIF89a\x01\x00\x01\x00")
+	rec := upload("image/gif", image)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var media service.Media
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &media))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, media.URL, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/gif", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, image, rec.Body.Bytes())
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, upload("text/html", []byte("<script>alert(1)</script>")).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload("application/octet-stream", append(image, make([]byte, 1024)...)).Code)
+
+	// Only authors upload
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/media", bytes.NewReader(image)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/media/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
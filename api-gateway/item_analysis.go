@@ -0,0 +1,25 @@
+package apigateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetItemAnalysis handles the request for the item statistics of a quiz's questions.
+// It must only be routed behind RequireAuthor.
+func (h *Handler) GetItemAnalysis(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetItemAnalysis(ctx, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
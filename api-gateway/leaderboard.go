@@ -0,0 +1,49 @@
+package apigateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"fasttrack/quiz-app/service"
+)
+
+// GetLeaderboard handles the request for a quiz's leaderboard. The window, tie_break and limit query
+// parameters select the board; around=me returns the entries around the calling player instead of the top.
+func (h *Handler) GetLeaderboard(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	query := service.LeaderboardQuery{
+		Window:   service.LeaderboardWindow(c.Query("window")),
+		TieBreak: service.TieBreak(c.Query("tie_break")),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		query.Limit = n
+	}
+	switch c.Query("around") {
+	case "":
+	case "me":
+		query.AroundMe = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid around; only \"me\" is supported"})
+		return
+	}
+
+	board, err := h.service.GetLeaderboard(ctx, id, query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, board)
+}
@@ -0,0 +1,45 @@
+package apigateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadMedia handles the request to upload an image; the request body is the image itself.
+func (h *Handler) UploadMedia(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// The content type is sniffed from the image; a declared one only has to agree with it
+	contentType := c.ContentType()
+	if contentType == "application/octet-stream" {
+		contentType = ""
+	}
+
+	media, err := h.service.UploadMedia(ctx, contentType, c.Request.Body)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, media)
+}
+
+// GetMedia handles the request for an uploaded image.
+func (h *Handler) GetMedia(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	media, content, err := h.service.OpenMedia(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	// Media never changes once uploaded, and browsers must not treat it as anything but the image it is
+	c.DataFromReader(http.StatusOK, media.Size, media.ContentType, content, map[string]string{
+		"Cache-Control":           "public, max-age=31536000, immutable",
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'",
+	})
+}
@@ -0,0 +1,44 @@
+package apigateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRequest is the payload for registering a player.
+type RegisterRequest struct {
+	Name string `json:"name"`
+}
+
+// RegisterPlayer handles the request to register a player and returns its token.
+func (h *Handler) RegisterPlayer(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request RegisterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	registration, err := h.service.RegisterPlayer(ctx, request.Name)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, registration)
+}
+
+// GetPlayerScores handles the request for the calling player's score history.
+func (h *Handler) GetPlayerScores(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	scores, err := h.service.GetPlayerScores(ctx)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scores)
+}
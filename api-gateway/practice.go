@@ -0,0 +1,61 @@
+package apigateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"fasttrack/quiz-app/service"
+)
+
+// GetDueQuestions handles the request for the calling player's practice questions of a quiz: those due
+// for review, then new ones. The limit query parameter caps how many are returned.
+func (h *Handler) GetDueQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	questions, err := h.service.GetDueQuestions(ctx, id, limit)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// RecordReview handles the request to grade a practice answer and schedule the question's next review.
+func (h *Handler) RecordReview(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	var answer service.Answer
+	if err := c.ShouldBindJSON(&answer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	review, err := h.service.RecordReview(ctx, id, answer)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
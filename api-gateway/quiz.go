@@ -0,0 +1,209 @@
+package apigateway
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"fasttrack/quiz-app/service"
+)
+
+// GetQuizzes handles the request for listing every quiz.
+func (h *Handler) GetQuizzes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	quizzes, err := h.service.GetQuizzes(ctx)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quizzes)
+}
+
+// GetQuiz handles the request for fetching one quiz.
+func (h *Handler) GetQuiz(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	quiz, err := h.service.GetQuiz(ctx, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quiz)
+}
+
+// CreateQuiz handles the request to add a new quiz.
+func (h *Handler) CreateQuiz(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var quiz service.Quiz
+	if err := c.ShouldBindJSON(&quiz); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := h.service.CreateQuiz(ctx, quiz); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, quiz)
+}
+
+// UpdateQuiz handles the request to replace an existing quiz's name, description and settings.
+func (h *Handler) UpdateQuiz(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	var quiz service.Quiz
+	if err := c.ShouldBindJSON(&quiz); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	quiz.ID = id // the path is authoritative
+
+	if err := h.service.UpdateQuiz(ctx, quiz); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quiz)
+}
+
+// DeleteQuiz handles the request to remove a quiz with its questions and scores.
+func (h *Handler) DeleteQuiz(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteQuiz(ctx, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetQuizQuestions handles the request for fetching a quiz's questions; the answer keys are never included.
+func (h *Handler) GetQuizQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	questions, err := h.service.GetQuizQuestions(ctx, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// GetQuizAuthorQuestions handles the request for fetching a quiz's questions with their answer keys.
+// It must only be routed behind RequireAuthor.
+func (h *Handler) GetQuizAuthorQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	questions, err := h.service.GetQuizAuthorQuestions(ctx, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, questions)
+}
+
+// AddQuizQuestion handles the request to add a question to a quiz.
+func (h *Handler) AddQuizQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	var question service.Question
+	if err := c.ShouldBindJSON(&question); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	question.QuizID = id // the path is authoritative
+
+	if err := h.service.AddQuestion(ctx, question); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+// SubmitQuizAnswers handles the request for submitting answers, keyed by question ID, to one quiz.
+func (h *Handler) SubmitQuizAnswers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	var request SubmitRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Answers == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	serviceResponse, err := h.service.SubmitQuizAnswers(ctx, id, request.Answers)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIResponse(serviceResponse))
+}
+
+// StartQuizAttempt handles the request to open a new attempt on one quiz.
+func (h *Handler) StartQuizAttempt(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.service.StartQuizAttempt(ctx, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attempt)
+}
+
+// quizID parses the :id path parameter, writing a 400 response if it is not an integer.
+func quizID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,51 @@
+package apigateway
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"fasttrack/quiz-app/service"
+)
+
+// GetScoreStatistics handles the request for a quiz's score statistics. The from and to query parameters
+// (RFC 3339 times) limit the scores to a time range; buckets sets the number of histogram buckets.
+func (h *Handler) GetScoreStatistics(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := quizID(c)
+	if !ok {
+		return
+	}
+
+	var query service.StatisticsQuery
+	for param, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + "; use an RFC 3339 time"})
+			return
+		}
+		*target = parsed
+	}
+	if buckets := c.Query("buckets"); buckets != "" {
+		n, err := strconv.Atoi(buckets)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid buckets"})
+			return
+		}
+		query.Buckets = n
+	}
+
+	statistics, err := h.service.GetScoreStatistics(ctx, id, query)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, statistics)
+}
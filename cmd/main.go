@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -18,13 +21,35 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI to interact with the quiz API",
 }
 
+// authorToken is the bearer token sent to authoring routes
+var authorToken string
+
+// playerToken identifies the player so scores are recorded under their name
+var playerToken string
+
 // addQuestionCmd represents the add-question command
 var addQuestionCmd = &cobra.Command{
 	Use:   "add-question",
 	Short: "Add a new question to the quiz",
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 4 {
+		multi, _ := cmd.Flags().GetBool("multi")
+		shortAnswer, _ := cmd.Flags().GetBool("short-answer")
+		numeric, _ := cmd.Flags().GetBool("numeric")
+		ordering, _ := cmd.Flags().GetBool("ordering")
+		matching, _ := cmd.Flags().GetBool("matching")
+
+		// Typed questions have no alternatives, so they need one argument less
+		minArgs := 4
+		if shortAnswer || numeric {
+			minArgs = 3
+		}
+		if len(args) < minArgs {
 			fmt.Println("Usage: add-question <id> <question> <correct_answer_index> <alternative_1> <alternative_2> ... <alternative_n>")
+			fmt.Println("       add-question --multi <id> <question> <correct_index,correct_index,...> <alternative_1> ... <alternative_n>")
+			fmt.Println("       add-question --short-answer <id> <question> <accepted_answer> ... <accepted_answer>")
+			fmt.Println("       add-question --numeric <id> <question> <answer>")
+			fmt.Println("       add-question --ordering <id> <question> <index,index,...> <item_1> ... <item_n>")
+			fmt.Println("       add-question --matching --prompt <prompt_1> ... --prompt <prompt_n> <id> <question> <match_1,...,match_n> <alternative_1> ... <alternative_n>")
 			os.Exit(1)
 		}
 
@@ -35,21 +60,89 @@ var addQuestionCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		questionText := args[1]
-		correctAnswerIndex, err := strconv.Atoi(args[2])
-		if err != nil {
-			fmt.Println("Invalid correct answer index:", args[2])
-			os.Exit(1)
+		// Create the question structure
+		question := map[string]interface{}{
+			"id":       id,
+			"question": args[1],
 		}
 
-		alternatives := args[3:]
+		switch {
+		case shortAnswer:
+			question["type"] = "short-answer"
+			question["accepted_answers"] = args[2:]
+			question["fuzzy_distance"], _ = cmd.Flags().GetInt("fuzzy")
+		case numeric:
+			answer, err := strconv.ParseFloat(args[2], 64)
+			if err != nil {
+				fmt.Println("Invalid numeric answer:", args[2])
+				os.Exit(1)
+			}
+			question["type"] = "numeric"
+			question["numeric_answer"] = answer
+			question["tolerance"], _ = cmd.Flags().GetFloat64("tolerance")
+			if relative, _ := cmd.Flags().GetBool("relative"); relative {
+				question["tolerance_mode"] = "relative"
+			}
+		case multi, ordering, matching:
+			// These take a comma-separated list of indices: the correct picks, the correct order,
+			// or the alternative each prompt matches
+			indices, err := parseChoices(args[2])
+			if err != nil {
+				fmt.Println("Invalid correct answer indices:", args[2])
+				os.Exit(1)
+			}
+			switch {
+			case ordering:
+				question["type"] = "ordering"
+				question["correct_order"] = indices
+			case matching:
+				question["type"] = "matching"
+				question["correct_matches"] = indices
+				question["prompts"], _ = cmd.Flags().GetStringArray("prompt")
+			default:
+				question["type"] = "multi"
+				question["correct_answers"] = indices
+			}
+			question["alternatives"] = args[3:]
+			if grading, _ := cmd.Flags().GetString("grading"); grading != "" {
+				question["grading"] = grading
+			}
+		default:
+			correctAnswerIndex, err := strconv.Atoi(args[2])
+			if err != nil {
+				fmt.Println("Invalid correct answer index:", args[2])
+				os.Exit(1)
+			}
+			question["correct_answer"] = correctAnswerIndex
+			question["alternatives"] = args[3:]
+		}
 
-		// Create the question structure
-		question := map[string]interface{}{
-			"id":             id,
-			"question":       questionText,
-			"correct_answer": correctAnswerIndex,
-			"alternatives":   alternatives,
+		if weight, _ := cmd.Flags().GetFloat64("weight"); weight != 0 {
+			question["weight"] = weight
+		}
+		if pinned, _ := cmd.Flags().GetIntSlice("pin"); len(pinned) > 0 {
+			question["pinned_alternatives"] = pinned
+		}
+		if pool, _ := cmd.Flags().GetString("pool"); pool != "" {
+			question["pool"] = pool
+		}
+		if difficulty, _ := cmd.Flags().GetFloat64("difficulty"); difficulty != 0 {
+			question["difficulty"] = difficulty
+		}
+		if discrimination, _ := cmd.Flags().GetFloat64("discrimination"); discrimination != 0 {
+			question["discrimination"] = discrimination
+		}
+		if explanation, _ := cmd.Flags().GetString("explanation"); explanation != "" {
+			question["explanation"] = explanation
+		}
+		if feedback, _ := cmd.Flags().GetStringArray("feedback"); len(feedback) > 0 {
+			question["feedback"] = feedback
+		}
+		if hints, _ := cmd.Flags().GetStringArray("hint"); len(hints) > 0 {
+			question["hints"] = hints
+		}
+		if penalty, _ := cmd.Flags().GetFloat64("hint-penalty"); penalty != 0 {
+			question["hint_penalty"] = penalty
 		}
 
 		// Convert question to JSON
@@ -59,8 +152,20 @@ var addQuestionCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Send POST request to the API
-		resp, err := http.Post("http://localhost:8080/add-question", "application/json", bytes.NewBuffer(questionJSON))
+		// Send POST request to the API; --quiz adds the question to that quiz instead of the default one
+		url := "http://localhost:8080/add-question"
+		if quizID, _ := cmd.Flags().GetInt("quiz"); quizID != 0 {
+			url = fmt.Sprintf("http://localhost:8080/quizzes/%d/questions", quizID)
+		}
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(questionJSON))
+		if err != nil {
+			fmt.Println("Error creating request:", err)
+			os.Exit(1)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		setAuthorToken(req)
+
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			fmt.Println("Error sending POST request:", err)
 			os.Exit(1)
@@ -81,7 +186,26 @@ var getQuestionsCmd = &cobra.Command{
 	Use:   "get-questions",
 	Short: "Fetches quiz questions",
 	Run: func(cmd *cobra.Command, args []string) {
-		resp, err := http.Get("http://localhost:8080/questions")
+		withAnswers, _ := cmd.Flags().GetBool("with-answers")
+		url := "http://localhost:8080/questions"
+		if withAnswers {
+			url = "http://localhost:8080/author/questions"
+		}
+		if quizID, _ := cmd.Flags().GetInt("quiz"); quizID != 0 {
+			url = fmt.Sprintf("http://localhost:8080/quizzes/%d/questions", quizID)
+			if withAnswers {
+				url = fmt.Sprintf("http://localhost:8080/author/quizzes/%d/questions", quizID)
+			}
+		}
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			fmt.Println("Error creating request:", err)
+			return
+		}
+		setAuthorToken(req)
+
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			fmt.Println("Error fetching questions:", err)
 			return
@@ -96,21 +220,67 @@ var getQuestionsCmd = &cobra.Command{
 }
 
 var submitAnswersCmd = &cobra.Command{
-	Use:   "submit-answers [answers]",
-	Short: "Submit your answers (provide them as space-separated integers)",
+	Use:   "submit-answers [question_id=choice ...]",
+	Short: "Submit your answers as question_id=choice pairs; see save-answer for other question types (space-separated choices in question order are deprecated)",
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Parse the answers from CLI arguments
-		var answers []string
-		for _, ans := range args {
-			answers = append(answers, ans)
+		var payload interface{}
+		if strings.Contains(args[0], "=") {
+			answers := make([]map[string]interface{}, 0, len(args))
+			for _, arg := range args {
+				idStr, choiceStr, _ := strings.Cut(arg, "=")
+				id, err := strconv.Atoi(idStr)
+				if err != nil {
+					fmt.Println("Invalid question ID:", idStr)
+					os.Exit(1)
+				}
+				answer, err := answerPayload(id, choiceStr)
+				if err != nil {
+					fmt.Println("Invalid choice:", choiceStr)
+					os.Exit(1)
+				}
+				answers = append(answers, answer)
+			}
+			payload = map[string]interface{}{"answers": answers}
+		} else {
+			answers := make([]int, 0, len(args))
+			for _, arg := range args {
+				choice, err := strconv.Atoi(arg)
+				if err != nil {
+					fmt.Println("Invalid choice:", arg)
+					os.Exit(1)
+				}
+				answers = append(answers, choice)
+			}
+			payload = answers
 		}
 
 		// Convert the answers to JSON format
-		answersStr := "[" + strings.Join(answers, ", ") + "]"
+		answersJSON, err := json.Marshal(payload)
+		if err != nil {
+			fmt.Println("Error encoding answers:", err)
+			os.Exit(1)
+		}
+
+		// Make the POST request; named quizzes only accept answers keyed by question ID
+		url := "http://localhost:8080/submit"
+		if quizID, _ := cmd.Flags().GetInt("quiz"); quizID != 0 {
+			if _, byID := payload.(map[string]interface{}); !byID {
+				fmt.Println("--quiz requires answers as question_id=choice pairs")
+				os.Exit(1)
+			}
+			url = fmt.Sprintf("http://localhost:8080/quizzes/%d/submit", quizID)
+		}
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(answersJSON))
+		if err != nil {
+			fmt.Println("Error creating request:", err)
+			os.Exit(1)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		setPlayerToken(req)
 
-		// Make the POST request
-		resp, err := http.Post("http://localhost:8080/submit", "application/json", strings.NewReader(answersStr))
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			fmt.Println("Error submitting answers:", err)
 			return
@@ -125,10 +295,795 @@ var submitAnswersCmd = &cobra.Command{
 	},
 }
 
+// updateQuestionCmd replaces every field of an existing question
+var updateQuestionCmd = &cobra.Command{
+	Use:   "update-question <id> <question> <correct_answer_index> <alternative_1> ... <alternative_n>",
+	Short: "Replace an existing question",
+	Args:  cobra.MinimumNArgs(4),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("Invalid question ID:", args[0])
+			os.Exit(1)
+		}
+
+		correctAnswerIndex, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Println("Invalid correct answer index:", args[2])
+			os.Exit(1)
+		}
+
+		question := map[string]interface{}{
+			"question":       args[1],
+			"correct_answer": correctAnswerIndex,
+			"alternatives":   args[3:],
+		}
+
+		sendQuestionRequest(http.MethodPut, id, question)
+	},
+}
+
+// patchQuestionCmd updates only the fields given as flags
+var patchQuestionCmd = &cobra.Command{
+	Use:   "patch-question <id> [--question text] [--correct-answer index] [--alternatives a,b,c]",
+	Short: "Update some fields of an existing question",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("Invalid question ID:", args[0])
+			os.Exit(1)
+		}
+
+		// Only send the flags the user actually set
+		patch := map[string]interface{}{}
+		if cmd.Flags().Changed("question") {
+			text, _ := cmd.Flags().GetString("question")
+			patch["question"] = text
+		}
+		if cmd.Flags().Changed("correct-answer") {
+			index, _ := cmd.Flags().GetInt("correct-answer")
+			patch["correct_answer"] = index
+		}
+		if cmd.Flags().Changed("alternatives") {
+			alternatives, _ := cmd.Flags().GetStringSlice("alternatives")
+			patch["alternatives"] = alternatives
+		}
+		if len(patch) == 0 {
+			fmt.Println("Nothing to update: set at least one of --question, --correct-answer or --alternatives")
+			os.Exit(1)
+		}
+
+		sendQuestionRequest(http.MethodPatch, id, patch)
+	},
+}
+
+// deleteQuestionCmd removes a question
+var deleteQuestionCmd = &cobra.Command{
+	Use:   "delete-question <id>",
+	Short: "Delete a question from the quiz",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("Invalid question ID:", args[0])
+			os.Exit(1)
+		}
+
+		sendQuestionRequest(http.MethodDelete, id, nil)
+	},
+}
+
+// sendQuestionRequest sends a request to /questions/<id> and prints the outcome.
+func sendQuestionRequest(method string, id int, payload map[string]interface{}) {
+	var body io.Reader
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			fmt.Println("Error encoding question:", err)
+			os.Exit(1)
+		}
+		body = bytes.NewBuffer(payloadJSON)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://localhost:8080/questions/%d", id), body)
+	if err != nil {
+		fmt.Println("Error creating request:", err)
+		os.Exit(1)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setAuthorToken(req)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error sending %s request: %v\n", method, err)
+		os.Exit(1)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	respBody, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		fmt.Println(string(respBody))
+	case http.StatusNoContent:
+		fmt.Println("Question deleted successfully!")
+	default:
+		fmt.Printf("Request failed. Status code: %d %s\n", resp.StatusCode, string(respBody))
+	}
+}
+
+// startAttemptCmd opens a new attempt and prints its ID and questions
+var startAttemptCmd = &cobra.Command{
+	Use:   "start-attempt",
+	Short: "Start a new quiz attempt",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if quizID, _ := cmd.Flags().GetInt("quiz"); quizID != 0 {
+			callAPI(http.MethodPost, fmt.Sprintf("/quizzes/%d/attempts", quizID), nil)
+			return
+		}
+		callAPI(http.MethodPost, "/attempts", nil)
+	},
+}
+
+// listQuizzesCmd prints every quiz
+var listQuizzesCmd = &cobra.Command{
+	Use:   "list-quizzes",
+	Short: "List the available quizzes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		callAPI(http.MethodGet, "/quizzes", nil)
+	},
+}
+
+// createQuizCmd adds a new, empty quiz
+var createQuizCmd = &cobra.Command{
+	Use:   "create-quiz <id> <name> [--description text] [--time-limit seconds] [--late-policy reject|auto-finalize] [--scoring strategy]",
+	Short: "Create a new quiz",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println("Invalid quiz ID:", args[0])
+			os.Exit(1)
+		}
+
+		description, _ := cmd.Flags().GetString("description")
+		timeLimit, _ := cmd.Flags().GetInt("time-limit")
+		latePolicy, _ := cmd.Flags().GetString("late-policy")
+		scoring, _ := cmd.Flags().GetString("scoring")
+		penalty, _ := cmd.Flags().GetFloat64("penalty")
+		timeBonus, _ := cmd.Flags().GetFloat64("time-bonus")
+		shuffleQuestions, _ := cmd.Flags().GetBool("shuffle-questions")
+		shuffleAlternatives, _ := cmd.Flags().GetBool("shuffle-alternatives")
+		adaptive, _ := cmd.Flags().GetBool("adaptive")
+		maxQuestions, _ := cmd.Flags().GetInt("max-questions")
+		targetError, _ := cmd.Flags().GetFloat64("target-error")
+		reviewPolicy, _ := cmd.Flags().GetString("review-policy")
+		drawFlags, _ := cmd.Flags().GetStringArray("draw")
+		draw := make([]map[string]interface{}, 0, len(drawFlags))
+		for _, flag := range drawFlags {
+			pool, count, found := strings.Cut(flag, "=")
+			n, err := strconv.Atoi(count)
+			if !found || err != nil {
+				fmt.Println("Invalid draw rule, expected <pool>=<count>:", flag)
+				os.Exit(1)
+			}
+			draw = append(draw, map[string]interface{}{"pool": pool, "count": n})
+		}
+		callAPI(http.MethodPost, "/quizzes", map[string]interface{}{
+			"id":                 id,
+			"name":               args[1],
+			"description":        description,
+			"time_limit_seconds": timeLimit,
+			"late_policy":        latePolicy,
+			"scoring":            scoring,
+			"penalty":            penalty,
+			"time_bonus":         timeBonus,
+
+			"shuffle_questions":    shuffleQuestions,
+			"shuffle_alternatives": shuffleAlternatives,
+			"draw":                 draw,
+			"adaptive":             adaptive,
+			"max_questions":        maxQuestions,
+			"target_error":         targetError,
+			"review_policy":        reviewPolicy,
+		})
+	},
+}
+
+// deleteQuizCmd removes a quiz with its questions and scores
+var deleteQuizCmd = &cobra.Command{
+	Use:   "delete-quiz <id>",
+	Short: "Delete a quiz together with its questions and scores",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			fmt.Println("Invalid quiz ID:", args[0])
+			os.Exit(1)
+		}
+
+		callAPI(http.MethodDelete, "/quizzes/"+args[0], nil)
+	},
+}
+
+// serveQuestionCmd shows one question of an attempt and starts its timer
+var serveQuestionCmd = &cobra.Command{
+	Use:   "get-question <attempt_id> <question_id>",
+	Short: "Show one question of an attempt (starts its time limit, if any)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := strconv.Atoi(args[1]); err != nil {
+			fmt.Println("Invalid question ID:", args[1])
+			os.Exit(1)
+		}
+
+		callAPI(http.MethodGet, "/attempts/"+args[0]+"/questions/"+args[1], nil)
+	},
+}
+
+// saveAnswerCmd saves one answer of an attempt
+var saveAnswerCmd = &cobra.Command{
+	Use:   "save-answer <attempt_id> <question_id> <choice|choice,choice,...|text:answer|number:answer|order:i,j,...|matches:i,j,...>",
+	Short: "Save the answer to one question of an attempt",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		questionID, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println("Invalid question ID:", args[1])
+			os.Exit(1)
+		}
+		answer, err := answerPayload(questionID, args[2])
+		if err != nil {
+			fmt.Println("Invalid choice:", args[2])
+			os.Exit(1)
+		}
+
+		callAPI(http.MethodPut, "/attempts/"+args[0]+"/answers", answer)
+	},
+}
+
+// finishAttemptCmd grades an attempt
+var finishAttemptCmd = &cobra.Command{
+	Use:   "finish-attempt <attempt_id>",
+	Short: "Finish an attempt and show the score",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		callAPI(http.MethodPost, "/attempts/"+args[0]+"/finish", nil)
+	},
+}
+
+// hintCmd reveals the next hint to a question of an attempt
+var hintCmd = &cobra.Command{
+	Use:   "hint <attempt_id> <question_id>",
+	Short: "Reveal the next hint to a question of an attempt (costs part of its points)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := strconv.Atoi(args[1]); err != nil {
+			fmt.Println("Invalid question ID:", args[1])
+			os.Exit(1)
+		}
+
+		callAPI(http.MethodPost, "/attempts/"+args[0]+"/questions/"+args[1]+"/hints", nil)
+	},
+}
+
+// reviewAttemptCmd shows a finished attempt with the correct answers and explanations
+var reviewAttemptCmd = &cobra.Command{
+	Use:   "review-attempt <attempt_id>",
+	Short: "Review a finished attempt: your answers, the correct ones and why",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		callAPI(http.MethodGet, "/attempts/"+url.PathEscape(args[0])+"/review", nil)
+	},
+}
+
+// registerCmd registers a player and prints the token to pass as --player-token
+var registerCmd = &cobra.Command{
+	Use:   "register <name>",
+	Short: "Register as a player so your scores are kept under your name",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		callAPI(http.MethodPost, "/players", map[string]string{"name": args[0]})
+	},
+}
+
+// myScoresCmd prints the player's score history
+var myScoresCmd = &cobra.Command{
+	Use:   "my-scores",
+	Short: "Show your score history (requires --player-token)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		callAPI(http.MethodGet, "/me/scores", nil)
+	},
+}
+
+// practiceCmd lists the questions of a quiz due for the player's practice
+var practiceCmd = &cobra.Command{
+	Use:   "practice <quiz_id>",
+	Short: "Show the questions of a quiz due for practice, then new ones (requires --player-token)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		path := "/quizzes/" + url.PathEscape(args[0]) + "/practice"
+		if limit != 0 {
+			path += "?limit=" + strconv.Itoa(limit)
+		}
+		callAPI(http.MethodGet, path, nil)
+	},
+}
+
+// reviewCmd records a practice answer and prints when the question is due again
+var reviewCmd = &cobra.Command{
+	Use:   "review <quiz_id> <question_id> <choice|choice,choice,...|text:answer|number:answer|order:i,j,...|matches:i,j,...>",
+	Short: "Answer a practice question and schedule its next review (requires --player-token)",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		questionID, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println("Invalid question ID:", args[1])
+			os.Exit(1)
+		}
+		answer, err := answerPayload(questionID, args[2])
+		if err != nil {
+			fmt.Println("Invalid choice:", args[2])
+			os.Exit(1)
+		}
+
+		callAPI(http.MethodPost, "/quizzes/"+url.PathEscape(args[0])+"/practice", answer)
+	},
+}
+
+// leaderboardCmd prints a quiz's leaderboard as a table
+var leaderboardCmd = &cobra.Command{
+	Use:   "leaderboard <quiz_id>",
+	Short: "Show the leaderboard of a quiz",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		window, _ := cmd.Flags().GetString("window")
+		tieBreak, _ := cmd.Flags().GetString("tie-break")
+		limit, _ := cmd.Flags().GetInt("limit")
+		aroundMe, _ := cmd.Flags().GetBool("around-me")
+
+		query := url.Values{}
+		if window != "" {
+			query.Set("window", window)
+		}
+		if tieBreak != "" {
+			query.Set("tie_break", tieBreak)
+		}
+		if limit != 0 {
+			query.Set("limit", strconv.Itoa(limit))
+		}
+		if aroundMe {
+			query.Set("around", "me")
+		}
+
+		path := "/quizzes/" + url.PathEscape(args[0]) + "/leaderboard"
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+
+		var board leaderboard
+		if err := json.Unmarshal(fetchAPI(http.MethodGet, path, nil), &board); err != nil {
+			fmt.Println("Error decoding leaderboard:", err)
+			os.Exit(1)
+		}
+		printLeaderboard(os.Stdout, board)
+	},
+}
+
+// statsCmd prints the score statistics of a quiz
+var statsCmd = &cobra.Command{
+	Use:   "stats <quiz_id>",
+	Short: "Show the score statistics of a quiz (requires --token)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := url.Values{}
+		for _, flag := range []string{"from", "to"} {
+			if value, _ := cmd.Flags().GetString(flag); value != "" {
+				query.Set(flag, value)
+			}
+		}
+		if buckets, _ := cmd.Flags().GetInt("buckets"); buckets != 0 {
+			query.Set("buckets", strconv.Itoa(buckets))
+		}
+
+		path := "/author/quizzes/" + url.PathEscape(args[0]) + "/statistics"
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+		callAPI(http.MethodGet, path, nil)
+	},
+}
+
+// itemReportCmd prints the item analysis of a quiz's questions as a table
+var itemReportCmd = &cobra.Command{
+	Use:   "item-report <quiz_id>",
+	Short: "Show per-question item statistics of a quiz (requires --token)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var items []itemAnalysis
+		if err := json.Unmarshal(fetchAPI(http.MethodGet, "/author/quizzes/"+url.PathEscape(args[0])+"/items", nil), &items); err != nil {
+			fmt.Println("Error decoding item analysis:", err)
+			os.Exit(1)
+		}
+		printItemReport(os.Stdout, items)
+	},
+}
+
+// itemAnalysis is the part of the item analysis response the CLI renders.
+type itemAnalysis struct {
+	QuestionID     int     `json:"question_id"`
+	Question       string  `json:"question"`
+	Responses      int     `json:"responses"`
+	Difficulty     float64 `json:"difficulty"`
+	Discrimination float64 `json:"discrimination"`
+	Flag           string  `json:"flag"`
+	Distractors    []struct {
+		Choice    int     `json:"choice"`
+		Correct   bool    `json:"correct"`
+		Frequency float64 `json:"frequency"`
+	} `json:"distractors"`
+}
+
+// printItemReport renders item statistics as an aligned table, one row per question.
+// Picks list every alternative's share of the responses; the correct ones are starred.
+func printItemReport(out io.Writer, items []itemAnalysis) {
+	if len(items) == 0 {
+		fmt.Fprintln(out, "The quiz has no questions")
+		return
+	}
+
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tRESPONSES\tDIFFICULTY\tDISCRIMINATION\tPICKS\tFLAG\tQUESTION")
+	for _, item := range items {
+		picks := make([]string, 0, len(item.Distractors))
+		for _, distractor := range item.Distractors {
+			star := ""
+			if distractor.Correct {
+				star = "*"
+			}
+			picks = append(picks, fmt.Sprintf("%d%s:%.0f%%", distractor.Choice, star, distractor.Frequency*100))
+		}
+		pickList := strings.Join(picks, " ")
+		if pickList == "" {
+			pickList = "-"
+		}
+		flag := item.Flag
+		if flag == "" {
+			flag = "-"
+		}
+		fmt.Fprintf(table, "%d\t%d\t%.2f\t%.2f\t%s\t%s\t%s\n",
+			item.QuestionID, item.Responses, item.Difficulty, item.Discrimination, pickList, flag, item.Question)
+	}
+	_ = table.Flush()
+}
+
+// uploadMediaCmd uploads an image that question content can then refer to
+var uploadMediaCmd = &cobra.Command{
+	Use:   "upload-media <file>",
+	Short: "Upload a PNG, JPEG, GIF or WebP image and print its URL for use in question Markdown (requires --token)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Println("Error reading file:", err)
+			os.Exit(1)
+		}
+
+		// The server recognises the image from its content
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/media", bytes.NewReader(content))
+		if err != nil {
+			fmt.Println("Error creating request:", err)
+			os.Exit(1)
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		setAuthorToken(req)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fmt.Println("Error sending POST request:", err)
+			os.Exit(1)
+		}
+		defer func(Body io.ReadCloser) {
+			_ = Body.Close()
+		}(resp.Body)
+
+		respBody, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode >= http.StatusBadRequest {
+			fmt.Printf("Request failed. Status code: %d %s\n", resp.StatusCode, string(respBody))
+			os.Exit(1)
+		}
+		fmt.Println(string(respBody))
+	},
+}
+
+// calibrateCmd recalibrates the item parameters of a quiz's questions and prints them as a table
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate <quiz_id>",
+	Short: "Calibrate the difficulty and discrimination of a quiz's questions from recorded answers (requires --token)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var calibrations []itemCalibration
+		if err := json.Unmarshal(fetchAPI(http.MethodPost, "/author/quizzes/"+url.PathEscape(args[0])+"/calibrate", nil), &calibrations); err != nil {
+			fmt.Println("Error decoding calibration:", err)
+			os.Exit(1)
+		}
+		printCalibration(os.Stdout, calibrations)
+	},
+}
+
+// itemCalibration is the calibration response for one question.
+type itemCalibration struct {
+	QuestionID     int     `json:"question_id"`
+	Responses      int     `json:"responses"`
+	Difficulty     float64 `json:"difficulty"`
+	Discrimination float64 `json:"discrimination"`
+	Calibrated     bool    `json:"calibrated"`
+}
+
+// printCalibration renders item parameters as an aligned table, one row per question.
+func printCalibration(out io.Writer, calibrations []itemCalibration) {
+	if len(calibrations) == 0 {
+		fmt.Fprintln(out, "The quiz has no questions")
+		return
+	}
+
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tRESPONSES\tDIFFICULTY\tDISCRIMINATION\tCALIBRATED")
+	for _, calibration := range calibrations {
+		calibrated := "no"
+		if calibration.Calibrated {
+			calibrated = "yes"
+		}
+		fmt.Fprintf(table, "%d\t%d\t%.2f\t%.2f\t%s\n",
+			calibration.QuestionID, calibration.Responses, calibration.Difficulty, calibration.Discrimination, calibrated)
+	}
+	_ = table.Flush()
+}
+
+// leaderboard is the part of the leaderboard response the CLI renders.
+type leaderboard struct {
+	QuizID   int    `json:"quiz_id"`
+	Window   string `json:"window"`
+	TieBreak string `json:"tie_break"`
+	Players  int    `json:"players"`
+	Entries  []struct {
+		Rank           int       `json:"rank"`
+		PlayerName     string    `json:"player_name"`
+		Score          float64   `json:"score"`
+		MaxScore       float64   `json:"max_score"`
+		ElapsedSeconds float64   `json:"elapsed_seconds"`
+		RecordedAt     time.Time `json:"recorded_at"`
+	} `json:"entries"`
+}
+
+// printLeaderboard renders a leaderboard as an aligned table.
+func printLeaderboard(out io.Writer, board leaderboard) {
+	fmt.Fprintf(out, "Quiz %d leaderboard (%s, ties by %s, %d players)\n", board.QuizID, board.Window, board.TieBreak, board.Players)
+	if len(board.Entries) == 0 {
+		fmt.Fprintln(out, "No results yet")
+		return
+	}
+
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "RANK\tPLAYER\tSCORE\tTIME\tRECORDED")
+	for _, entry := range board.Entries {
+		elapsed := "-"
+		if entry.ElapsedSeconds > 0 {
+			elapsed = time.Duration(entry.ElapsedSeconds * float64(time.Second)).Round(time.Second).String()
+		}
+		fmt.Fprintf(table, "%d\t%s\t%g/%g\t%s\t%s\n", entry.Rank, entry.PlayerName, entry.Score, entry.MaxScore, elapsed,
+			entry.RecordedAt.Local().Format("2006-01-02 15:04"))
+	}
+	_ = table.Flush()
+}
+
+// answerPayload builds the JSON answer to a question. "text:", "number:", "order:" and "matches:" prefix
+// answers to short-answer, numeric, ordering and matching questions; -1 leaves a prompt unmatched. Otherwise a value with a comma is a set of multi-select picks; write a single pick
+// on a multi-select question with a trailing comma, as in "2,".
+func answerPayload(questionID int, value string) (map[string]interface{}, error) {
+	if strings.HasPrefix(value, "text:") {
+		return map[string]interface{}{"question_id": questionID, "text": strings.TrimPrefix(value, "text:")}, nil
+	}
+	if strings.HasPrefix(value, "number:") {
+		number, err := strconv.ParseFloat(strings.TrimPrefix(value, "number:"), 64)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"question_id": questionID, "number": number}, nil
+	}
+	for _, field := range []string{"order", "matches"} {
+		if strings.HasPrefix(value, field+":") {
+			indices, err := parseChoices(strings.TrimPrefix(value, field+":"))
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"question_id": questionID, field: indices}, nil
+		}
+	}
+
+	if !strings.Contains(value, ",") {
+		choice, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"question_id": questionID, "choice": choice}, nil
+	}
+
+	choices, err := parseChoices(value)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"question_id": questionID, "choices": choices}, nil
+}
+
+// parseChoices parses a comma-separated list of alternative indices, ignoring empty entries.
+func parseChoices(value string) ([]int, error) {
+	choices := []int{}
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		choice, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		choices = append(choices, choice)
+	}
+	return choices, nil
+}
+
+// callAPI sends a JSON request to the quiz API and prints the response body.
+func callAPI(method, path string, payload interface{}) {
+	respBody := fetchAPI(method, path, payload)
+	if len(respBody) > 0 {
+		fmt.Println(string(respBody))
+	} else {
+		fmt.Println("OK")
+	}
+}
+
+// fetchAPI sends a JSON request to the quiz API and returns the response body, exiting if the request fails.
+func fetchAPI(method, path string, payload interface{}) []byte {
+	var body io.Reader
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			fmt.Println("Error encoding request:", err)
+			os.Exit(1)
+		}
+		body = bytes.NewBuffer(payloadJSON)
+	}
+
+	req, err := http.NewRequest(method, "http://localhost:8080"+path, body)
+	if err != nil {
+		fmt.Println("Error creating request:", err)
+		os.Exit(1)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setAuthorToken(req)
+	setPlayerToken(req)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("Error sending %s request: %v\n", method, err)
+		os.Exit(1)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		fmt.Printf("Request failed. Status code: %d %s\n", resp.StatusCode, string(respBody))
+		os.Exit(1)
+	}
+	return respBody
+}
+
+// setAuthorToken attaches the --token credentials required by the authoring routes.
+func setAuthorToken(req *http.Request) {
+	if authorToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authorToken)
+	}
+}
+
+// setPlayerToken identifies the player registered with --player-token, if any.
+func setPlayerToken(req *http.Request) {
+	if playerToken != "" {
+		req.Header.Set("X-Player-Token", playerToken)
+	}
+}
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&authorToken, "token", os.Getenv("QUIZ_AUTHOR_TOKEN"), "Author token for authoring commands (defaults to $QUIZ_AUTHOR_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&playerToken, "player-token", os.Getenv("QUIZ_PLAYER_TOKEN"), "Player token from register (defaults to $QUIZ_PLAYER_TOKEN)")
+	getQuestionsCmd.Flags().Bool("with-answers", false, "Include the answer keys (requires --token)")
+	addQuestionCmd.Flags().Bool("multi", false, "Add a multi-select question; the correct answer becomes a comma-separated list of indices")
+	addQuestionCmd.Flags().String("grading", "", "Grading scheme: all-or-nothing, partial or penalty (multi-select), kendall-tau (ordering), partial (matching)")
+	addQuestionCmd.Flags().Bool("short-answer", false, "Add a short-answer question; the remaining arguments are the accepted answers")
+	addQuestionCmd.Flags().Int("fuzzy", 0, "Typos tolerated in short answers")
+	addQuestionCmd.Flags().Bool("numeric", false, "Add a numeric question; the third argument is the answer")
+	addQuestionCmd.Flags().Float64("tolerance", 0, "How far a numeric answer may be off")
+	addQuestionCmd.Flags().Bool("ordering", false, "Add an ordering question; the correct answer becomes the comma-separated order of the items")
+	addQuestionCmd.Flags().Bool("matching", false, "Add a matching question; the correct answer lists the alternative each --prompt matches")
+	addQuestionCmd.Flags().StringArray("prompt", nil, "A prompt of a matching question (repeat for each prompt)")
+	addQuestionCmd.Flags().Float64("weight", 0, "How much the question counts under weighted scoring (default 1)")
+	addQuestionCmd.Flags().Bool("relative", false, "Read --tolerance as a fraction of the answer, e.g. 0.05 for 5%")
+	addQuestionCmd.Flags().String("pool", "", "Pool the quiz's draw rules pick the question from, e.g. easy-geography")
+	addQuestionCmd.Flags().Float64("difficulty", 0, "Difficulty on the ability scale, for adaptive quizzes (calibrate sets it from answers)")
+	addQuestionCmd.Flags().Float64("discrimination", 0, "Discrimination for adaptive quizzes (0 counts as 1 until calibrated)")
+	addQuestionCmd.Flags().String("explanation", "", "Why the answer is correct, shown when a finished attempt is reviewed")
+	addQuestionCmd.Flags().StringArray("feedback", nil, "A remark on each alternative, in order, shown in reviews (repeat for each alternative)")
+	addQuestionCmd.Flags().StringArray("hint", nil, "A hint players can reveal during an attempt (repeat for more, in order)")
+	addQuestionCmd.Flags().Float64("hint-penalty", 0, "Share of the question's point each revealed hint takes off, from 0 to 1")
+	addQuestionCmd.Flags().IntSlice("pin", nil, "Comma-separated alternatives that keep their position when alternatives are shuffled")
+	for _, cmd := range []*cobra.Command{addQuestionCmd, getQuestionsCmd, submitAnswersCmd, startAttemptCmd} {
+		cmd.Flags().Int("quiz", 0, "Quiz ID (defaults to the default quiz)")
+	}
+
+	createQuizCmd.Flags().String("description", "", "Quiz description")
+	createQuizCmd.Flags().Int("time-limit", 0, "Time limit in seconds (0 uses the server default)")
+	createQuizCmd.Flags().String("late-policy", "", "Late policy: reject or auto-finalize (empty uses the server default)")
+	createQuizCmd.Flags().String("scoring", "", "Scoring strategy: count (default), weighted, negative, no-blank-penalty or time-bonus")
+	createQuizCmd.Flags().Float64("penalty", 0, "Points a wrong answer costs under negative scoring (0 uses the default, 0.25)")
+	createQuizCmd.Flags().Float64("time-bonus", 0, "Share of the score finishing instantly adds under time-bonus scoring (0 uses the default, 0.5)")
+	createQuizCmd.Flags().Bool("shuffle-questions", false, "Serve the questions in a different order on every attempt")
+	createQuizCmd.Flags().Bool("shuffle-alternatives", false, "Show the alternatives in a different order on every attempt")
+	createQuizCmd.Flags().Bool("adaptive", false, "Pick each question from the player's estimated ability")
+	createQuizCmd.Flags().Int("max-questions", 0, "Most questions an adaptive attempt serves (0 uses the default, 20)")
+	createQuizCmd.Flags().Float64("target-error", 0, "Standard error at which an adaptive attempt stops (0 uses the default, 0.3)")
+	createQuizCmd.Flags().String("review-policy", "", "When players may review finished attempts: immediate (default), after-deadline or never")
+	createQuizCmd.Flags().StringArray("draw", nil, "Draw rule <pool>=<count>; every attempt draws that many questions from the pool (repeat for each pool)")
+
+	leaderboardCmd.Flags().String("window", "", "Window: all-time (default), daily or weekly")
+	leaderboardCmd.Flags().String("tie-break", "", "Tie-break: earliest (default) or fastest")
+	leaderboardCmd.Flags().Int("limit", 0, "Number of entries to show (0 uses the server default, 10)")
+	leaderboardCmd.Flags().Bool("around-me", false, "Show the entries around you instead of the top (requires --player-token)")
+
+	practiceCmd.Flags().Int("limit", 0, "Number of questions to show (0 uses the server default, 20)")
+
+	statsCmd.Flags().String("from", "", "Only scores recorded at or after this RFC 3339 time")
+	statsCmd.Flags().String("to", "", "Only scores recorded before this RFC 3339 time")
+	statsCmd.Flags().Int("buckets", 0, "Number of histogram buckets (0 uses the server default, 10)")
+
+	patchQuestionCmd.Flags().String("question", "", "New question text")
+	patchQuestionCmd.Flags().Int("correct-answer", 0, "New correct answer index")
+	patchQuestionCmd.Flags().StringSlice("alternatives", nil, "New comma-separated alternatives")
+
 	rootCmd.AddCommand(addQuestionCmd)
 	rootCmd.AddCommand(getQuestionsCmd)
 	rootCmd.AddCommand(submitAnswersCmd)
+	rootCmd.AddCommand(updateQuestionCmd)
+	rootCmd.AddCommand(patchQuestionCmd)
+	rootCmd.AddCommand(deleteQuestionCmd)
+	rootCmd.AddCommand(startAttemptCmd)
+	rootCmd.AddCommand(serveQuestionCmd)
+	rootCmd.AddCommand(saveAnswerCmd)
+	rootCmd.AddCommand(finishAttemptCmd)
+	rootCmd.AddCommand(hintCmd)
+	rootCmd.AddCommand(reviewAttemptCmd)
+	rootCmd.AddCommand(listQuizzesCmd)
+	rootCmd.AddCommand(createQuizCmd)
+	rootCmd.AddCommand(deleteQuizCmd)
+	rootCmd.AddCommand(registerCmd)
+	rootCmd.AddCommand(myScoresCmd)
+	rootCmd.AddCommand(practiceCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(leaderboardCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(itemReportCmd)
+	rootCmd.AddCommand(calibrateCmd)
+	rootCmd.AddCommand(uploadMediaCmd)
 }
 
 func main() {
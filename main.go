@@ -2,21 +2,114 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"fasttrack/quiz-app/api-gateway"
 	"fasttrack/quiz-app/repository"
 	"fasttrack/quiz-app/service"
 	"github.com/gin-gonic/gin"
+	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
+// newRepository picks the repository implementation from the QUIZ_STORE environment variable.
+// "memory" (the default) keeps everything in process, "bolt" persists to QUIZ_DB_PATH and
+// "postgres" connects to QUIZ_POSTGRES_DSN and applies pending migrations on startup. Comparisons rank
+// against score distributions cached in the process, which a second instance sharing the database would
+// make wrong, so postgres mode only starts once QUIZ_SINGLE_INSTANCE=true confirms this is the only one.
+func newRepository() (repository.Repository, func(), error) {
+	switch store := os.Getenv("QUIZ_STORE"); store {
+	case "", "memory":
+		return repository.NewRepository(), func() {}, nil
+	case "bolt":
+		path := os.Getenv("QUIZ_DB_PATH")
+		if path == "" {
+			path = "quiz.db"
+		}
+		repo, err := repository.NewBoltRepository(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, func() { _ = repo.Close() }, nil
+	case "postgres":
+		if single, _ := strconv.ParseBool(os.Getenv("QUIZ_SINGLE_INSTANCE")); !single {
+			return nil, nil, errors.New("QUIZ_STORE=postgres needs QUIZ_SINGLE_INSTANCE=true: comparisons are cached per process, so only one instance may serve the database")
+		}
+		db, err := sql.Open("pgx", os.Getenv("QUIZ_POSTGRES_DSN"))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := repository.MigrateUp(context.Background(), db); err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		return repository.NewPostgresRepository(db), func() { _ = db.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown QUIZ_STORE %q", store)
+	}
+}
+
+// serviceOptions reads the quiz timing configuration: QUIZ_TIME_LIMIT (a Go duration such as "15m")
+// and QUIZ_LATE_POLICY ("reject" or "auto-finalize"). Media uploads are enabled by QUIZ_MEDIA_DIR, the
+// directory they are stored in, and capped at QUIZ_MEDIA_MAX_BYTES.
+func serviceOptions() ([]service.Option, error) {
+	var opts []service.Option
+
+	if limit := os.Getenv("QUIZ_TIME_LIMIT"); limit != "" {
+		d, err := time.ParseDuration(limit)
+		if err != nil {
+			return nil, fmt.Errorf("QUIZ_TIME_LIMIT: %w", err)
+		}
+		opts = append(opts, service.WithTimeLimit(d))
+	}
+
+	switch policy := service.LatePolicy(os.Getenv("QUIZ_LATE_POLICY")); policy {
+	case "":
+	case service.LatePolicyReject, service.LatePolicyAutoFinalize:
+		opts = append(opts, service.WithLatePolicy(policy))
+	default:
+		return nil, fmt.Errorf("unknown QUIZ_LATE_POLICY %q", policy)
+	}
+
+	// Uploaded media is kept on the local filesystem; without a directory uploads are disabled
+	if dir := os.Getenv("QUIZ_MEDIA_DIR"); dir != "" {
+		store, err := repository.NewFileMediaStore(dir)
+		if err != nil {
+			return nil, fmt.Errorf("QUIZ_MEDIA_DIR: %w", err)
+		}
+		opts = append(opts, service.WithMediaStore(store))
+	}
+
+	if size := os.Getenv("QUIZ_MEDIA_MAX_BYTES"); size != "" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("QUIZ_MEDIA_MAX_BYTES: %q is not a positive number of bytes", size)
+		}
+		opts = append(opts, service.WithMaxMediaSize(n))
+	}
+
+	return opts, nil
+}
+
 func main2() {
 	// Initialize the repository
-	repo := repository.NewRepository()
+	repo, closeRepo, err := newRepository()
+	if err != nil {
+		log.Fatalf("Could not initialize repository: %v", err)
+	}
+	defer closeRepo()
 
 	// Initialize the service with the repository
-	svc := service.NewQuizService(repo)
+	opts, err := serviceOptions()
+	if err != nil {
+		log.Fatalf("Invalid quiz configuration: %v", err)
+	}
+	svc := service.NewQuizService(repo, opts...)
 
 	// Initialize the handler with the service
 	handler := apigateway.NewHandler(svc)
@@ -34,13 +127,52 @@ func main2() {
 	repo.AddQuestion(ctx, repository.Question{ID: 9, QuestionText: "What is the chemical symbol for Gold?", Alternatives: []string{"Au", "Ag", "Pb", "Fe"}, CorrectAnswer: 1})
 	repo.AddQuestion(ctx, repository.Question{ID: 10, QuestionText: "How many continents are there?", Alternatives: []string{"5", "6", "7", "8"}, CorrectAnswer: 2})
 
-	// Set up the Gin router
+	// Set up the Gin router; every route sees the calling player, if X-Player-Token identifies one
 	router := gin.Default()
+	router.Use(handler.IdentifyPlayer)
 
-	// Define the routes
+	// Define the player routes
 	router.GET("/questions", handler.GetQuestions)
 	router.POST("/submit", handler.SubmitAnswers)
-	router.POST("/add-question", handler.AddQuestion)
+	router.POST("/attempts", handler.StartAttempt)
+	router.GET("/attempts/:id", handler.GetAttempt)
+	router.GET("/attempts/:id/questions/:question_id", handler.ServeQuestion)
+	router.PUT("/attempts/:id/answers", handler.SaveAnswer)
+	router.POST("/attempts/:id/questions/:question_id/hints", handler.RevealHint)
+	router.POST("/attempts/:id/finish", handler.FinishAttempt)
+	router.GET("/attempts/:id/review", handler.ReviewAttempt)
+	router.GET("/quizzes", handler.GetQuizzes)
+	router.GET("/quizzes/:id", handler.GetQuiz)
+	router.GET("/quizzes/:id/questions", handler.GetQuizQuestions)
+	router.POST("/quizzes/:id/submit", handler.SubmitQuizAnswers)
+	router.POST("/quizzes/:id/attempts", handler.StartQuizAttempt)
+	router.GET("/quizzes/:id/leaderboard", handler.GetLeaderboard)
+	router.GET("/quizzes/:id/practice", handler.GetDueQuestions)
+	router.POST("/quizzes/:id/practice", handler.RecordReview)
+	router.POST("/players", handler.RegisterPlayer)
+	router.GET("/me/scores", handler.GetPlayerScores)
+	router.GET("/media/:id", handler.GetMedia)
+
+	// Define the authoring routes; these expose answer keys and require QUIZ_AUTHOR_TOKEN
+	authorToken := os.Getenv("QUIZ_AUTHOR_TOKEN")
+	if authorToken == "" {
+		log.Println("QUIZ_AUTHOR_TOKEN is not set; authoring routes are disabled")
+	}
+	author := router.Group("/", apigateway.RequireAuthor(authorToken))
+	author.GET("/author/questions", handler.GetAuthorQuestions)
+	author.POST("/add-question", handler.AddQuestion)
+	author.PUT("/questions/:id", handler.UpdateQuestion)
+	author.PATCH("/questions/:id", handler.PatchQuestion)
+	author.DELETE("/questions/:id", handler.DeleteQuestion)
+	author.POST("/quizzes", handler.CreateQuiz)
+	author.PUT("/quizzes/:id", handler.UpdateQuiz)
+	author.DELETE("/quizzes/:id", handler.DeleteQuiz)
+	author.POST("/quizzes/:id/questions", handler.AddQuizQuestion)
+	author.GET("/author/quizzes/:id/questions", handler.GetQuizAuthorQuestions)
+	author.GET("/author/quizzes/:id/statistics", handler.GetScoreStatistics)
+	author.GET("/author/quizzes/:id/items", handler.GetItemAnalysis)
+	author.POST("/author/quizzes/:id/calibrate", handler.CalibrateQuiz)
+	author.POST("/media", handler.UploadMedia)
 
 	// Start the Gin server
 	fmt.Println("Server running on port 8080...")
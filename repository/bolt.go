@@ -0,0 +1,924 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	quizzesBucket    = []byte("quizzes")
+	questionsBucket  = []byte("questions")
+	scoresBucket     = []byte("scores")
+	scoreIndexBucket = []byte("scores_by_quiz") // Quiz ID to a bucket of recording time and sequence to points and maximum
+	attemptsBucket   = []byte("attempts")
+	usersBucket      = []byte("users")
+	tokensBucket     = []byte("user_tokens") // Token hash to user ID
+	answersBucket    = []byte("answers")
+	reviewsBucket    = []byte("reviews") // User ID, a zero byte and the question ID to the review state
+)
+
+// BoltRepository is a Repository that persists questions and scores in a local bbolt file.
+// Every write runs in its own transaction and is fsynced on commit, so a crash never leaves
+// a partially written record behind and the file is recovered automatically on the next open.
+type BoltRepository struct {
+	db *bolt.DB
+}
+
+var _ Repository = (*BoltRepository)(nil)
+
+// NewBoltRepository opens (or creates) the bbolt database at path.
+func NewBoltRepository(path string) (*BoltRepository, error) {
+	// Fail fast instead of blocking forever if another process holds the file lock
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure all buckets exist before serving requests
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{quizzesBucket, questionsBucket, scoresBucket, attemptsBucket, usersBucket, tokensBucket, answersBucket, reviewsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		// Files created before the score index existed have it built from their scores
+		if tx.Bucket(scoreIndexBucket) == nil {
+			if _, err := tx.CreateBucket(scoreIndexBucket); err != nil {
+				return err
+			}
+			err := tx.Bucket(scoresBucket).ForEach(func(key, data []byte) error {
+				score, err := decodeScore(data)
+				if err != nil {
+					return err
+				}
+				return indexScore(tx, uint64(btoi(key)), score)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// Files created before quizzes existed get the default quiz their questions belong to
+		quizzes := tx.Bucket(quizzesBucket)
+		if quizzes.Get(itob(DefaultQuizID)) != nil {
+			return nil
+		}
+		data, err := json.Marshal(defaultQuiz())
+		if err != nil {
+			return err
+		}
+		return quizzes.Put(itob(DefaultQuizID), data)
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltRepository{db: db}, nil
+}
+
+// Close releases the underlying database file.
+func (b *BoltRepository) Close() error {
+	return b.db.Close()
+}
+
+// GetAllQuizzes returns all quizzes sorted by ID.
+func (b *BoltRepository) GetAllQuizzes(ctx context.Context) ([]Quiz, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var quizzes []Quiz
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(quizzesBucket).ForEach(func(_, data []byte) error {
+			var quiz Quiz
+			if err := json.Unmarshal(data, &quiz); err != nil {
+				return err
+			}
+			quizzes = append(quizzes, quiz)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(quizzes, func(i, j int) bool {
+		return quizzes[i].ID < quizzes[j].ID
+	})
+
+	return quizzes, nil
+}
+
+// GetQuiz returns a quiz by its ID.
+func (b *BoltRepository) GetQuiz(ctx context.Context, id int) (Quiz, error) {
+	if err := ctx.Err(); err != nil {
+		return Quiz{}, err
+	}
+
+	var quiz Quiz
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(quizzesBucket).Get(itob(id))
+		if data == nil {
+			return ErrQuizNotFound
+		}
+		return json.Unmarshal(data, &quiz)
+	})
+	if err != nil {
+		return Quiz{}, err
+	}
+
+	return quiz, nil
+}
+
+// AddQuiz adds a new quiz to the repository.
+func (b *BoltRepository) AddQuiz(ctx context.Context, quiz Quiz) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quizzesBucket)
+		key := itob(quiz.ID)
+
+		if bucket.Get(key) != nil {
+			return ErrQuizExists
+		}
+		if err := validateQuiz(quiz); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(quiz)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// UpdateQuiz replaces an existing quiz, matched by ID.
+func (b *BoltRepository) UpdateQuiz(ctx context.Context, quiz Quiz) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quizzesBucket)
+		key := itob(quiz.ID)
+
+		if bucket.Get(key) == nil {
+			return ErrQuizNotFound
+		}
+		if err := validateQuiz(quiz); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(quiz)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// DeleteQuiz removes a quiz with its questions, scores and attempts in one transaction.
+func (b *BoltRepository) DeleteQuiz(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(quizzesBucket)
+		key := itob(id)
+
+		if bucket.Get(key) == nil {
+			return ErrQuizNotFound
+		}
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+
+		err := deleteWhere(tx.Bucket(questionsBucket), func(data []byte) (bool, error) {
+			question, err := decodeQuestion(data)
+			return question.QuizID == id, err
+		})
+		if err != nil {
+			return err
+		}
+
+		err = deleteWhere(tx.Bucket(scoresBucket), func(data []byte) (bool, error) {
+			score, err := decodeScore(data)
+			return score.QuizID == id, err
+		})
+		if err != nil {
+			return err
+		}
+		if index := tx.Bucket(scoreIndexBucket); index.Bucket(key) != nil {
+			if err := index.DeleteBucket(key); err != nil {
+				return err
+			}
+		}
+
+		err = deleteWhere(tx.Bucket(answersBucket), func(data []byte) (bool, error) {
+			var answer AnswerRecord
+			err := json.Unmarshal(data, &answer)
+			return answer.QuizID == id, err
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteWhere(tx.Bucket(attemptsBucket), func(data []byte) (bool, error) {
+			var attempt Attempt
+			err := json.Unmarshal(data, &attempt)
+			return attempt.QuizID == id, err
+		})
+	})
+}
+
+// AddQuestion adds a new question to the repository.
+func (b *BoltRepository) AddQuestion(ctx context.Context, question Question) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(questionsBucket)
+		key := itob(question.ID)
+
+		// Check if the question already exists by ID
+		if bucket.Get(key) != nil {
+			return ErrQuestionExists
+		}
+
+		// Validate the question data
+		question.QuizID = normalizeQuizID(question.QuizID)
+		if err := validateQuestion(question); err != nil {
+			return err
+		}
+		if tx.Bucket(quizzesBucket).Get(itob(question.QuizID)) == nil {
+			return ErrQuizNotFound
+		}
+
+		data, err := json.Marshal(question)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// UpdateQuestion replaces an existing question, matched by ID.
+func (b *BoltRepository) UpdateQuestion(ctx context.Context, question Question) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(questionsBucket)
+		key := itob(question.ID)
+
+		if bucket.Get(key) == nil {
+			return ErrQuestionNotFound
+		}
+
+		question.QuizID = normalizeQuizID(question.QuizID)
+		if err := validateQuestion(question); err != nil {
+			return err
+		}
+		if tx.Bucket(quizzesBucket).Get(itob(question.QuizID)) == nil {
+			return ErrQuizNotFound
+		}
+
+		data, err := json.Marshal(question)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// DeleteQuestion removes a question by its ID.
+func (b *BoltRepository) DeleteQuestion(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(questionsBucket)
+		key := itob(id)
+
+		if bucket.Get(key) == nil {
+			return ErrQuestionNotFound
+		}
+		return bucket.Delete(key)
+	})
+}
+
+// GetAllQuestions returns all quiz questions as a sorted slice.
+func (b *BoltRepository) GetAllQuestions(ctx context.Context) ([]Question, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.questionsWhere(func(Question) bool { return true })
+}
+
+// GetQuestionsByQuiz returns the questions of one quiz as a sorted slice.
+func (b *BoltRepository) GetQuestionsByQuiz(ctx context.Context, quizID int) ([]Question, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	quizID = normalizeQuizID(quizID)
+	return b.questionsWhere(func(question Question) bool { return question.QuizID == quizID })
+}
+
+// questionsWhere returns the stored questions matching keep, sorted by ID.
+func (b *BoltRepository) questionsWhere(keep func(Question) bool) ([]Question, error) {
+	var questions []Question
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(questionsBucket).ForEach(func(_, data []byte) error {
+			question, err := decodeQuestion(data)
+			if err != nil {
+				return err
+			}
+			if keep(question) {
+				questions = append(questions, question)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys sort as unsigned integers, so sort explicitly to keep negative IDs in order
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].ID < questions[j].ID
+	})
+
+	return questions, nil
+}
+
+// GetQuestionByID returns a question by its ID.
+func (b *BoltRepository) GetQuestionByID(ctx context.Context, id int) (Question, error) {
+	if err := ctx.Err(); err != nil {
+		return Question{}, err
+	}
+
+	var question Question
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(questionsBucket).Get(itob(id))
+		if data == nil {
+			return ErrQuestionNotFound
+		}
+
+		var err error
+		question, err = decodeQuestion(data)
+		return err
+	})
+	if err != nil {
+		return Question{}, err
+	}
+
+	return question, nil
+}
+
+// AddScore adds a user's score to the repository.
+func (b *BoltRepository) AddScore(ctx context.Context, score ScoreRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return putScore(tx, score)
+	})
+}
+
+// putScore checks a score and appends it inside the transaction.
+func putScore(tx *bolt.Tx, score ScoreRecord) error {
+	score.QuizID = normalizeQuizID(score.QuizID)
+	if tx.Bucket(quizzesBucket).Get(itob(score.QuizID)) == nil {
+		return ErrQuizNotFound
+	}
+	if score.UserID != "" && tx.Bucket(usersBucket).Get([]byte(score.UserID)) == nil {
+		return ErrUserNotFound
+	}
+	data, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	bucket := tx.Bucket(scoresBucket)
+
+	// Use the bucket sequence so scores keep their insertion order
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put(itob(int(seq)), data); err != nil {
+		return err
+	}
+	return indexScore(tx, seq, score)
+}
+
+// indexScore adds a stored score, with its sequence in the scores bucket, to its quiz's score index.
+func indexScore(tx *bolt.Tx, seq uint64, score ScoreRecord) error {
+	index, err := tx.Bucket(scoreIndexBucket).CreateBucketIfNotExists(itob(normalizeQuizID(score.QuizID)))
+	if err != nil {
+		return err
+	}
+
+	key := make([]byte, 20)
+	copy(key, scoreIndexTime(score.RecordedAt))
+	binary.BigEndian.PutUint64(key[12:], seq)
+	value := make([]byte, 16)
+	binary.BigEndian.PutUint64(value, math.Float64bits(score.Score))
+	binary.BigEndian.PutUint64(value[8:], math.Float64bits(score.MaxScore))
+	return index.Put(key, value)
+}
+
+// scoreIndexTime encodes t so that keys sort by time: seconds with the sign bit flipped, then nanoseconds.
+func scoreIndexTime(t time.Time) []byte {
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint64(buf, uint64(t.Unix())^(1<<63))
+	binary.BigEndian.PutUint32(buf[8:], uint32(t.Nanosecond()))
+	return buf
+}
+
+// GetAllScores returns all the stored quiz scores.
+func (b *BoltRepository) GetAllScores(ctx context.Context) ([]ScoreRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.scoresWhere(func(ScoreRecord) bool { return true })
+}
+
+// GetScoresByQuiz returns the scores recorded for one quiz.
+func (b *BoltRepository) GetScoresByQuiz(ctx context.Context, quizID int) ([]ScoreRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	quizID = normalizeQuizID(quizID)
+	return b.scoresWhere(func(score ScoreRecord) bool { return score.QuizID == quizID })
+}
+
+// GetScoresByUser returns the scores recorded for one user.
+func (b *BoltRepository) GetScoresByUser(ctx context.Context, userID string) ([]ScoreRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.scoresWhere(func(score ScoreRecord) bool { return score.UserID == userID })
+}
+
+// GetScoreStats aggregates the scores the filter selects, reading only the quiz's index entries in the range.
+func (b *BoltRepository) GetScoreStats(ctx context.Context, filter ScoreFilter) (ScoreStats, error) {
+	if err := ctx.Err(); err != nil {
+		return ScoreStats{}, err
+	}
+
+	builder, err := newScoreStatsBuilder(filter)
+	if err != nil {
+		return ScoreStats{}, err
+	}
+
+	quizID := normalizeQuizID(filter.QuizID)
+	err = b.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(scoreIndexBucket).Bucket(itob(quizID))
+		if index == nil {
+			return nil
+		}
+
+		cursor := index.Cursor()
+		key, value := cursor.First()
+		if !filter.From.IsZero() {
+			key, value = cursor.Seek(scoreIndexTime(filter.From))
+		}
+		var to []byte
+		if !filter.To.IsZero() {
+			to = scoreIndexTime(filter.To)
+		}
+		for ; key != nil && (to == nil || bytes.Compare(key[:12], to) < 0); key, value = cursor.Next() {
+			builder.add(ScoreRecord{
+				QuizID:     quizID,
+				Score:      math.Float64frombits(binary.BigEndian.Uint64(value)),
+				MaxScore:   math.Float64frombits(binary.BigEndian.Uint64(value[8:])),
+				RecordedAt: time.Unix(int64(binary.BigEndian.Uint64(key)^(1<<63)), int64(binary.BigEndian.Uint32(key[8:12]))),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return ScoreStats{}, err
+	}
+	return builder.stats(), nil
+}
+
+// AddAnswers stores the graded answers of one result in one transaction.
+func (b *BoltRepository) AddAnswers(ctx context.Context, answers []AnswerRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return putAnswers(tx, answers)
+	})
+}
+
+// putAnswers checks and appends answers inside the transaction.
+func putAnswers(tx *bolt.Tx, answers []AnswerRecord) error {
+	bucket := tx.Bucket(answersBucket)
+	for _, answer := range answers {
+		answer.QuizID = normalizeQuizID(answer.QuizID)
+		if tx.Bucket(quizzesBucket).Get(itob(answer.QuizID)) == nil {
+			return ErrQuizNotFound
+		}
+
+		data, err := json.Marshal(answer)
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(itob(int(seq)), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddResult stores a score and its answers in one transaction.
+func (b *BoltRepository) AddResult(ctx context.Context, result Result) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := putScore(tx, result.Score); err != nil {
+			return err
+		}
+		return putAnswers(tx, result.Answers)
+	})
+}
+
+// GetItemStats aggregates the quiz's answers, decoding them one at a time in a read transaction.
+func (b *BoltRepository) GetItemStats(ctx context.Context, quizID int) ([]ItemStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items := newItemStatsBuilder(quizID)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(answersBucket).ForEach(func(_, data []byte) error {
+			var answer AnswerRecord
+			if err := json.Unmarshal(data, &answer); err != nil {
+				return err
+			}
+			items.add(answer)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items.stats(), nil
+}
+
+// scoresWhere returns the stored scores matching keep in insertion order.
+func (b *BoltRepository) scoresWhere(keep func(ScoreRecord) bool) ([]ScoreRecord, error) {
+	scores := []ScoreRecord{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(scoresBucket).ForEach(func(_, data []byte) error {
+			score, err := decodeScore(data)
+			if err != nil {
+				return err
+			}
+			if keep(score) {
+				scores = append(scores, score)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+// AddUser stores a new user and indexes it by token hash.
+func (b *BoltRepository) AddUser(ctx context.Context, user User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateUser(user); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		users := tx.Bucket(usersBucket)
+		tokens := tx.Bucket(tokensBucket)
+		key := []byte(user.ID)
+
+		if users.Get(key) != nil || tokens.Get([]byte(user.TokenHash)) != nil {
+			return ErrUserExists
+		}
+
+		data, err := json.Marshal(user)
+		if err != nil {
+			return err
+		}
+		if err := users.Put(key, data); err != nil {
+			return err
+		}
+		return tokens.Put([]byte(user.TokenHash), key)
+	})
+}
+
+// GetUser returns a user by its ID.
+func (b *BoltRepository) GetUser(ctx context.Context, id string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	var user User
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(usersBucket).Get([]byte(id))
+		if data == nil {
+			return ErrUserNotFound
+		}
+		return json.Unmarshal(data, &user)
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// GetUserByTokenHash returns the user whose token hashes to tokenHash.
+func (b *BoltRepository) GetUserByTokenHash(ctx context.Context, tokenHash string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	var user User
+	err := b.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(tokensBucket).Get([]byte(tokenHash))
+		if id == nil {
+			return ErrUserNotFound
+		}
+		data := tx.Bucket(usersBucket).Get(id)
+		if data == nil {
+			return ErrUserNotFound
+		}
+		return json.Unmarshal(data, &user)
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// CreateAttempt stores a new attempt.
+func (b *BoltRepository) CreateAttempt(ctx context.Context, attempt Attempt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(attemptsBucket)
+		key := []byte(attempt.ID)
+
+		if bucket.Get(key) != nil {
+			return ErrAttemptExists
+		}
+
+		data, err := json.Marshal(attempt)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// GetAttempt returns an attempt by its ID.
+func (b *BoltRepository) GetAttempt(ctx context.Context, id string) (Attempt, error) {
+	if err := ctx.Err(); err != nil {
+		return Attempt{}, err
+	}
+
+	var attempt Attempt
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(attemptsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrAttemptNotFound
+		}
+		return json.Unmarshal(data, &attempt)
+	})
+	if err != nil {
+		return Attempt{}, err
+	}
+
+	return attempt, nil
+}
+
+// UpdateAttempt applies fn to the attempt inside a single write transaction.
+func (b *BoltRepository) UpdateAttempt(ctx context.Context, id string, fn func(attempt *Attempt) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(attemptsBucket)
+		key := []byte(id)
+
+		data := bucket.Get(key)
+		if data == nil {
+			return ErrAttemptNotFound
+		}
+
+		var attempt Attempt
+		if err := json.Unmarshal(data, &attempt); err != nil {
+			return err
+		}
+		if err := fn(&attempt); err != nil {
+			return err
+		}
+		attempt.ID = id // the key cannot change
+
+		data, err := json.Marshal(attempt)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// FinishAttempt applies fn to the attempt and stores it with its result inside a single write transaction.
+func (b *BoltRepository) FinishAttempt(ctx context.Context, id string, fn func(attempt *Attempt) (Result, error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(attemptsBucket)
+		key := []byte(id)
+
+		data := bucket.Get(key)
+		if data == nil {
+			return ErrAttemptNotFound
+		}
+
+		var attempt Attempt
+		if err := json.Unmarshal(data, &attempt); err != nil {
+			return err
+		}
+		result, err := fn(&attempt)
+		if err != nil {
+			return err
+		}
+		if err := putScore(tx, result.Score); err != nil {
+			return err
+		}
+		if err := putAnswers(tx, result.Answers); err != nil {
+			return err
+		}
+		attempt.ID = id // the key cannot change
+
+		data, err = json.Marshal(attempt)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// GetReviewStates returns a user's review states, sorted by question ID.
+// Keys start with the user ID, so only that user's states are read.
+func (b *BoltRepository) GetReviewStates(ctx context.Context, userID string) ([]ReviewState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	states := []ReviewState{}
+	prefix := reviewPrefix(userID)
+	err := b.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(reviewsBucket).Cursor()
+		for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+			var state ReviewState
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+			states = append(states, state)
+		}
+		return nil
+	})
+	return states, err
+}
+
+// UpdateReviewState loads, updates and stores a review state in one read-write transaction.
+func (b *BoltRepository) UpdateReviewState(ctx context.Context, userID string, questionID int, fn func(state *ReviewState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(usersBucket).Get([]byte(userID)) == nil {
+			return ErrUserNotFound
+		}
+
+		bucket := tx.Bucket(reviewsBucket)
+		key := append(reviewPrefix(userID), itob(questionID)...)
+		state := ReviewState{UserID: userID, QuestionID: questionID}
+		if data := bucket.Get(key); data != nil {
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+		}
+		if err := fn(&state); err != nil {
+			return err
+		}
+		state.UserID, state.QuestionID = userID, questionID // the key cannot change
+
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, data)
+	})
+}
+
+// reviewPrefix is the start of the keys of a user's review states. User IDs never contain a zero byte.
+func reviewPrefix(userID string) []byte {
+	return append([]byte(userID), 0)
+}
+
+// deleteWhere removes every record in bucket for which match reports true.
+// Keys are collected first because bbolt does not allow deleting while iterating.
+func deleteWhere(bucket *bolt.Bucket, match func(data []byte) (bool, error)) error {
+	var keys [][]byte
+	err := bucket.ForEach(func(key, data []byte) error {
+		matched, err := match(data)
+		if matched {
+			keys = append(keys, append([]byte(nil), key...))
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeQuestion reads a stored question; questions written before quizzes existed belong to the default quiz.
+func decodeQuestion(data []byte) (Question, error) {
+	var question Question
+	err := json.Unmarshal(data, &question)
+	question.QuizID = normalizeQuizID(question.QuizID)
+	return question, err
+}
+
+// decodeScore reads a stored score; files written before scores became records hold a bare 8-byte integer.
+func decodeScore(data []byte) (ScoreRecord, error) {
+	if len(data) == 8 {
+		return ScoreRecord{QuizID: DefaultQuizID, Score: float64(btoi(data))}, nil
+	}
+
+	var score ScoreRecord
+	err := json.Unmarshal(data, &score)
+	score.QuizID = normalizeQuizID(score.QuizID)
+	return score, err
+}
+
+// itob encodes an int as an 8-byte big-endian key.
+func itob(v int) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(v))
+	return buf
+}
+
+// btoi decodes a key produced by itob.
+func btoi(buf []byte) int {
+	return int(binary.BigEndian.Uint64(buf))
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestBoltRepository(t *testing.T, path string) *BoltRepository {
+	repo, err := NewBoltRepository(path)
+	require.NoError(t, err, "Opening the bolt repository should not fail")
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestBoltRepository_AddQuestion(t *testing.T) {
+	repo := newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db"))
+
+	question := Question{
+		ID:            1,
+		QuizID:        DefaultQuizID,
+		QuestionText:  "What is the capital of France?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	}
+
+	// Add the question
+	err := repo.AddQuestion(context.Background(), question)
+	assert.NoError(t, err, "Error should be nil when adding a valid question")
+
+	// Try adding the same question again (should return an error)
+	err = repo.AddQuestion(context.Background(), question)
+	assert.ErrorIs(t, err, ErrQuestionExists, "Error should be ErrQuestionExists when adding a duplicate question")
+
+	// Try adding an invalid question
+	err = repo.AddQuestion(context.Background(), Question{ID: 2})
+	assert.ErrorIs(t, err, ErrInvalidQuestion, "Error should be ErrInvalidQuestion for a question without text")
+
+	// Retrieve it back
+	foundQuestion, err := repo.GetQuestionByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, question, foundQuestion, "The returned question should match the one added")
+
+	_, err = repo.GetQuestionByID(context.Background(), 999)
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "Error should be ErrQuestionNotFound for a non-existing question")
+}
+
+func TestBoltRepository_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.db")
+	ctx := context.Background()
+
+	// Write some data and close the database
+	repo, err := NewBoltRepository(path)
+	require.NoError(t, err)
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 2, QuestionText: "What is 2 + 2?", Alternatives: []string{"3", "4"}, CorrectAnswer: 1}))
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"2", "3"}, CorrectAnswer: 0}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 3}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 7}))
+	require.NoError(t, repo.Close())
+
+	// Reopen and verify everything is still there
+	repo = newTestBoltRepository(t, path)
+
+	questions, err := repo.GetAllQuestions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, questions, 2, "There should be 2 questions after reopening")
+	assert.Equal(t, 1, questions[0].ID, "First question should have ID 1")
+	assert.Equal(t, 2, questions[1].ID, "Second question should have ID 2")
+
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoreRecord{{QuizID: DefaultQuizID, Score: 3}, {QuizID: DefaultQuizID, Score: 7}}, scores, "Scores should be restored in insertion order")
+}
+
+func TestBoltRepository_UpdateAndDeleteQuestion(t *testing.T) {
+	repo := newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db"))
+	ctx := context.Background()
+
+	question := Question{
+		ID:            1,
+		QuizID:        DefaultQuizID,
+		QuestionText:  "What is the capital of Frence?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	}
+	assert.NoError(t, repo.AddQuestion(ctx, question))
+
+	// Update the question
+	question.QuestionText = "What is the capital of France?"
+	assert.NoError(t, repo.UpdateQuestion(ctx, question))
+
+	foundQuestion, err := repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, question, foundQuestion, "The stored question should reflect the update")
+
+	question.CorrectAnswer = 7
+	assert.ErrorIs(t, repo.UpdateQuestion(ctx, question), ErrInvalidAnswer)
+	assert.ErrorIs(t, repo.UpdateQuestion(ctx, Question{ID: 999}), ErrQuestionNotFound)
+
+	// Delete the question
+	assert.NoError(t, repo.DeleteQuestion(ctx, 1))
+	assert.ErrorIs(t, repo.DeleteQuestion(ctx, 1), ErrQuestionNotFound)
+
+	_, err = repo.GetQuestionByID(ctx, 1)
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "The deleted question should no longer be found")
+}
+
+func TestBoltRepository_Attempts(t *testing.T) {
+	repo := newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db"))
+	ctx := context.Background()
+
+	attempt := Attempt{ID: "a1", QuestionIDs: []int{1, 2}, Answers: map[int]int{}}
+	assert.NoError(t, repo.CreateAttempt(ctx, attempt))
+	assert.ErrorIs(t, repo.CreateAttempt(ctx, attempt), ErrAttemptExists, "Duplicate attempt IDs should be rejected")
+
+	err := repo.UpdateAttempt(ctx, "a1", func(attempt *Attempt) error {
+		attempt.Answers[1] = 2
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = repo.UpdateAttempt(ctx, "a1", func(attempt *Attempt) error {
+		attempt.Answers[2] = 0
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	stored, err := repo.GetAttempt(ctx, "a1")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, stored.QuestionIDs)
+	assert.Equal(t, map[int]int{1: 2}, stored.Answers, "Only the successful update should be stored")
+
+	_, err = repo.GetAttempt(ctx, "missing")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestBoltRepository_Quizzes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.db")
+	ctx := context.Background()
+
+	repo, err := NewBoltRepository(path)
+	require.NoError(t, err)
+	assert.NoError(t, repo.AddQuiz(ctx, Quiz{ID: 2, Name: "Geography", LatePolicy: "auto-finalize"}))
+	assert.ErrorIs(t, repo.AddQuiz(ctx, Quiz{ID: 2, Name: "Geography"}), ErrQuizExists)
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"2"}}))
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 2, QuizID: 2, QuestionText: "Capital of France?", Alternatives: []string{"Paris"}}))
+	assert.ErrorIs(t, repo.AddQuestion(ctx, Question{ID: 3, QuizID: 99, QuestionText: "Orphan?", Alternatives: []string{"Yes"}}), ErrQuizNotFound)
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{QuizID: 2, Score: 3}))
+	require.NoError(t, repo.Close())
+
+	// Quizzes survive a reopen and the default quiz is not duplicated
+	repo = newTestBoltRepository(t, path)
+	quizzes, err := repo.GetAllQuizzes(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Quiz{defaultQuiz(), {ID: 2, Name: "Geography", LatePolicy: "auto-finalize"}}, quizzes)
+
+	scores, err := repo.GetScoresByQuiz(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{3}, scoreValues(scores))
+
+	// Deleting a quiz removes its questions, scores and attempts
+	assert.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: "a1", QuizID: 2}))
+	assert.NoError(t, repo.DeleteQuiz(ctx, 2))
+	assert.ErrorIs(t, repo.DeleteQuiz(ctx, 2), ErrQuizNotFound)
+
+	questions, err := repo.GetAllQuestions(ctx)
+	assert.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, DefaultQuizID, questions[0].QuizID)
+	_, err = repo.GetAttempt(ctx, "a1")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+	scores, err = repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1}, scoreValues(scores))
+}
+
+func TestBoltRepository_Users(t *testing.T) {
+	repo := newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db"))
+	ctx := context.Background()
+
+	user := User{ID: "u1", Name: "Ada", TokenHash: "hash-1"}
+	assert.NoError(t, repo.AddUser(ctx, user))
+	assert.ErrorIs(t, repo.AddUser(ctx, user), ErrUserExists)
+	assert.ErrorIs(t, repo.AddUser(ctx, User{ID: "u2", Name: "Bob", TokenHash: "hash-1"}), ErrUserExists, "Token hashes must be unique")
+
+	found, err := repo.GetUserByTokenHash(ctx, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, found)
+	_, err = repo.GetUserByTokenHash(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{UserID: "u1", Score: 2, MaxScore: 3}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1}))
+	assert.ErrorIs(t, repo.AddScore(ctx, ScoreRecord{UserID: "missing", Score: 1}), ErrUserNotFound)
+
+	scores, err := repo.GetScoresByUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoreRecord{{UserID: "u1", QuizID: DefaultQuizID, Score: 2, MaxScore: 3}}, scores)
+}
+
+func TestBoltRepository_TypedQuestions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.db")
+	ctx := context.Background()
+
+	repo, err := NewBoltRepository(path)
+	require.NoError(t, err)
+	questions := []Question{
+		{
+			ID:              1,
+			QuizID:          DefaultQuizID,
+			Type:            QuestionTypeShortAnswer,
+			QuestionText:    "What is the chemical symbol for Gold?",
+			AcceptedAnswers: []string{"Au"},
+			FuzzyDistance:   1,
+		},
+		{
+			ID:            2,
+			QuizID:        DefaultQuizID,
+			Type:          QuestionTypeNumeric,
+			QuestionText:  "What is the boiling point of water in Kelvin?",
+			NumericAnswer: 373.15,
+			Tolerance:     0.5,
+			ToleranceMode: ToleranceAbsolute,
+		},
+	}
+	for _, question := range questions {
+		require.NoError(t, repo.AddQuestion(ctx, question))
+	}
+	require.NoError(t, repo.Close())
+
+	// The answer keys survive a reopen
+	repo = newTestBoltRepository(t, path)
+	stored, err := repo.GetAllQuestions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, questions, stored)
+}
+
+func TestBoltRepository_ScoreStats(t *testing.T) {
+	testScoreStats(t, newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db")))
+}
+
+func TestBoltRepository_ScoreIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz.db")
+	ctx := context.Background()
+	base := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	repo, err := NewBoltRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.AddQuiz(ctx, Quiz{ID: 2, Name: "Other"}))
+	for _, score := range []ScoreRecord{
+		{Score: 1, MaxScore: 2, RecordedAt: base},
+		{Score: 2, MaxScore: 2, RecordedAt: base.Add(time.Hour)},
+		{QuizID: 2, Score: 1, MaxScore: 1, RecordedAt: base},
+	} {
+		require.NoError(t, repo.AddScore(ctx, score))
+	}
+
+	// Files written before the index existed have it rebuilt when they are opened
+	require.NoError(t, repo.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(scoreIndexBucket)
+	}))
+	require.NoError(t, repo.Close())
+	repo = newTestBoltRepository(t, path)
+
+	stats, err := repo.GetScoreStats(ctx, ScoreFilter{QuizID: DefaultQuizID, From: base, To: base.Add(time.Hour), Buckets: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Count)
+	assert.Equal(t, 50.0, stats.Mean)
+
+	// Deleting a quiz drops its index
+	require.NoError(t, repo.DeleteQuiz(ctx, 2))
+	stats, err = repo.GetScoreStats(ctx, ScoreFilter{QuizID: 2, Buckets: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Count)
+}
+
+func TestBoltRepository_ItemStats(t *testing.T) {
+	testItemStats(t, newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db")))
+}
+
+func TestBoltRepository_ReviewStates(t *testing.T) {
+	testReviewStates(t, newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db")))
+}
+
+func TestBoltRepository_FinishAttempt(t *testing.T) {
+	testFinishAttempt(t, newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db")))
+}
+
+func TestBoltRepository_AddResult(t *testing.T) {
+	testAddResult(t, newTestBoltRepository(t, filepath.Join(t.TempDir(), "quiz.db")))
+}
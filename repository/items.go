@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"math"
+	"sort"
+)
+
+// itemStatsBuilder accumulates a quiz's answers for the repositories that aggregate in Go. It keeps running
+// sums per question, so memory grows with the number of questions rather than answers.
+type itemStatsBuilder struct {
+	quizID int
+	items  map[int]*itemSums
+}
+
+// itemSums are the running sums the item statistics of one question are derived from.
+type itemSums struct {
+	n, correct          int
+	sumX, sumY          float64 // x: the answer's points, y: its result's total
+	sumXX, sumYY, sumXY float64
+	choiceCounts        map[int]int
+}
+
+func newItemStatsBuilder(quizID int) *itemStatsBuilder {
+	return &itemStatsBuilder{quizID: normalizeQuizID(quizID), items: map[int]*itemSums{}}
+}
+
+// add counts the answer if it belongs to the builder's quiz.
+func (b *itemStatsBuilder) add(answer AnswerRecord) {
+	if normalizeQuizID(answer.QuizID) != b.quizID {
+		return
+	}
+
+	sums, ok := b.items[answer.QuestionID]
+	if !ok {
+		sums = &itemSums{choiceCounts: map[int]int{}}
+		b.items[answer.QuestionID] = sums
+	}
+
+	x, y := answer.Points, answer.Total
+	sums.n++
+	if answer.Correct {
+		sums.correct++
+	}
+	sums.sumX += x
+	sums.sumY += y
+	sums.sumXX += x * x
+	sums.sumYY += y * y
+	sums.sumXY += x * y
+	for _, choice := range answer.Choices {
+		sums.choiceCounts[choice]++
+	}
+}
+
+// stats returns the statistics of every question answered so far, by question ID.
+func (b *itemStatsBuilder) stats() []ItemStats {
+	items := make([]ItemStats, 0, len(b.items))
+	for questionID, sums := range b.items {
+		n := float64(sums.n)
+		items = append(items, ItemStats{
+			QuestionID:     questionID,
+			Responses:      sums.n,
+			Correct:        sums.correct,
+			MeanPoints:     sums.sumX / n,
+			Discrimination: correlation(n, sums.sumX, sums.sumY, sums.sumXX, sums.sumYY, sums.sumXY),
+			ChoiceCounts:   sums.choiceCounts,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].QuestionID < items[j].QuestionID })
+	return items
+}
+
+// varianceSlack treats variances this small as rounding error, so constant values correlate with nothing.
+const varianceSlack = 1e-12
+
+// correlation returns the Pearson correlation of n pairs from their sums, or zero when either side is constant,
+// as PostgreSQL's corr does.
+func correlation(n, sumX, sumY, sumXX, sumYY, sumXY float64) float64 {
+	varX := sumXX/n - (sumX/n)*(sumX/n)
+	varY := sumYY/n - (sumY/n)*(sumY/n)
+	if varX < varianceSlack || varY < varianceSlack {
+		return 0
+	}
+	return (sumXY/n - (sumX/n)*(sumY/n)) / math.Sqrt(varX*varY)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// MediaStore keeps uploaded media, such as the images question content refers to.
+type MediaStore interface {
+	// SaveMedia stores the content under media.ID, which must be unique.
+	SaveMedia(ctx context.Context, media Media, content []byte) error
+	GetMedia(ctx context.Context, id string) (Media, error)
+	// OpenMedia returns the media together with its content; the caller must close it.
+	OpenMedia(ctx context.Context, id string) (Media, io.ReadCloser, error)
+}
+
+// Media describes one stored file.
+type Media struct {
+	ID          string    `json:"id"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+var (
+	ErrMediaNotFound = errors.New("media not found")
+	ErrMediaExists   = errors.New("media already exists")
+	ErrInvalidMedia  = errors.New("invalid media: ID and content type are required")
+)
+
+// mediaID keeps IDs safe to use as file names.
+var mediaID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type fileMediaStore struct {
+	mu  sync.Mutex // Serialises saves, so two uploads cannot claim the same ID
+	dir string
+}
+
+// NewFileMediaStore keeps media in dir on the local filesystem, creating it if needed. Each file is
+// stored as <id>, next to its metadata in <id>.json.
+func NewFileMediaStore(dir string) (MediaStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &fileMediaStore{dir: dir}, nil
+}
+
+func (s *fileMediaStore) SaveMedia(ctx context.Context, media Media, content []byte) error {
+	if !mediaID.MatchString(media.ID) || media.ContentType == "" {
+		return ErrInvalidMedia
+	}
+	media.Size = int64(len(content))
+	metadata, err := json.Marshal(media)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.metadataPath(media.ID)); err == nil {
+		return ErrMediaExists
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// The metadata is written last: media without it was never completely saved
+	if err := writeFileAtomic(s.contentPath(media.ID), content); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.metadataPath(media.ID), metadata)
+}
+
+func (s *fileMediaStore) GetMedia(ctx context.Context, id string) (Media, error) {
+	if !mediaID.MatchString(id) {
+		return Media{}, ErrMediaNotFound
+	}
+
+	data, err := os.ReadFile(s.metadataPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Media{}, ErrMediaNotFound
+	}
+	if err != nil {
+		return Media{}, err
+	}
+
+	var media Media
+	if err := json.Unmarshal(data, &media); err != nil {
+		return Media{}, err
+	}
+	return media, nil
+}
+
+func (s *fileMediaStore) OpenMedia(ctx context.Context, id string) (Media, io.ReadCloser, error) {
+	media, err := s.GetMedia(ctx, id)
+	if err != nil {
+		return Media{}, nil, err
+	}
+
+	file, err := os.Open(s.contentPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Media{}, nil, ErrMediaNotFound
+	}
+	if err != nil {
+		return Media{}, nil, err
+	}
+	return media, file, nil
+}
+
+func (s *fileMediaStore) contentPath(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *fileMediaStore) metadataPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers never see a partly written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
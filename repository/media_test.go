@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMediaStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileMediaStore(dir)
+	require.NoError(t, err)
+
+	media := Media{ID: "map1", ContentType: "image/png", CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.SaveMedia(ctx, media, []byte("png bytes")))
+	assert.ErrorIs(t, store.SaveMedia(ctx, media, []byte("other")), ErrMediaExists)
+
+	// Media survives reopening the directory
+	store, err = NewFileMediaStore(dir)
+	require.NoError(t, err)
+	stored, content, err := store.OpenMedia(ctx, "map1")
+	require.NoError(t, err)
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, "png bytes", string(data))
+	media.Size = 9
+	assert.Equal(t, media, stored)
+
+	_, err = store.GetMedia(ctx, "missing")
+	assert.ErrorIs(t, err, ErrMediaNotFound)
+	_, _, err = store.OpenMedia(ctx, "../quiz.db")
+	assert.ErrorIs(t, err, ErrMediaNotFound, "IDs cannot reach outside the directory")
+	assert.ErrorIs(t, store.SaveMedia(ctx, Media{ID: "../escape", ContentType: "image/png"}, nil), ErrInvalidMedia)
+	assert.ErrorIs(t, store.SaveMedia(ctx, Media{ID: "blank"}, nil), ErrInvalidMedia)
+}
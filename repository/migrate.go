@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is one versioned schema change with its up and down scripts.
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// migrationLockID is the advisory lock key that serialises concurrent migration runs.
+const migrationLockID = 72_410_001
+
+// loadMigrations reads the embedded NNNN_name.{up,down}.sql files sorted by version.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		prefix, rest, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name.%s.sql", name, direction)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", name, err)
+		}
+
+		body, err := fs.ReadFile(migrationFiles, "migrations/"+name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &migration{Version: version, Name: strings.TrimSuffix(rest, "."+direction+".sql")}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: both up and down scripts are required", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrateUp applies every pending migration in version order.
+func MigrateUp(ctx context.Context, db *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	return withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if m.Version <= current {
+				continue
+			}
+			err := runMigration(ctx, conn, m.Up, "INSERT INTO schema_migrations (version) VALUES ($1)", m.Version)
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s up: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateDown rolls back the given number of applied migrations, newest first.
+func MigrateDown(ctx context.Context, db *sql.DB, steps int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	return withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if m.Version > current {
+				continue
+			}
+			err := runMigration(ctx, conn, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s down: %w", m.Version, m.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// withMigrationLock runs fn on a single connection holding the migration advisory lock.
+func withMigrationLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return err
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		_, unlockErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER     PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// currentVersion returns the highest applied migration version, or 0 if none.
+func currentVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var version int
+	err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// runMigration executes a migration script and records it in one transaction.
+func runMigration(ctx context.Context, conn *sql.Conn, script, record string, version int) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
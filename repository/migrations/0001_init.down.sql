@@ -0,0 +1,3 @@
+DROP TABLE scores;
+DROP TABLE alternatives;
+DROP TABLE questions;
@@ -0,0 +1,18 @@
+CREATE TABLE questions (
+    id             INTEGER PRIMARY KEY,
+    question_text  TEXT    NOT NULL,
+    correct_answer INTEGER NOT NULL
+);
+
+CREATE TABLE alternatives (
+    question_id INTEGER NOT NULL REFERENCES questions (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    text        TEXT    NOT NULL,
+    PRIMARY KEY (question_id, position)
+);
+
+CREATE TABLE scores (
+    id         BIGSERIAL   PRIMARY KEY,
+    score      INTEGER     NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
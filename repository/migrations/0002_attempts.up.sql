@@ -0,0 +1,5 @@
+CREATE TABLE attempts (
+    id         TEXT        PRIMARY KEY,
+    data       JSONB       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE scores DROP COLUMN elapsed_ms;
+ALTER TABLE questions DROP COLUMN time_limit_ms;
@@ -0,0 +1,2 @@
+ALTER TABLE questions ADD COLUMN time_limit_ms BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE scores ADD COLUMN elapsed_ms BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE scores DROP COLUMN quiz_id;
+ALTER TABLE questions DROP COLUMN quiz_id;
+DROP TABLE quizzes;
@@ -0,0 +1,16 @@
+CREATE TABLE quizzes (
+    id            INTEGER PRIMARY KEY,
+    name          TEXT    NOT NULL,
+    description   TEXT    NOT NULL DEFAULT '',
+    time_limit_ms BIGINT  NOT NULL DEFAULT 0,
+    late_policy   TEXT    NOT NULL DEFAULT ''
+);
+
+-- Existing questions and scores move into the default quiz
+INSERT INTO quizzes (id, name) VALUES (1, 'Default quiz');
+
+ALTER TABLE questions ADD COLUMN quiz_id INTEGER NOT NULL DEFAULT 1 REFERENCES quizzes (id) ON DELETE CASCADE;
+ALTER TABLE scores ADD COLUMN quiz_id INTEGER NOT NULL DEFAULT 1 REFERENCES quizzes (id) ON DELETE CASCADE;
+
+CREATE INDEX questions_quiz_id_idx ON questions (quiz_id);
+CREATE INDEX scores_quiz_id_idx ON scores (quiz_id);
@@ -0,0 +1,3 @@
+ALTER TABLE scores DROP COLUMN max_score;
+ALTER TABLE scores DROP COLUMN user_id;
+DROP TABLE users;
@@ -0,0 +1,12 @@
+CREATE TABLE users (
+    id         TEXT        PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    token_hash TEXT        NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Existing scores stay anonymous
+ALTER TABLE scores ADD COLUMN user_id TEXT REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE scores ADD COLUMN max_score INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX scores_user_id_idx ON scores (user_id);
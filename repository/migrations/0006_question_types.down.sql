@@ -0,0 +1,6 @@
+ALTER TABLE scores ALTER COLUMN max_score TYPE INTEGER USING round(max_score);
+ALTER TABLE scores ALTER COLUMN score TYPE INTEGER USING round(score);
+
+ALTER TABLE questions DROP COLUMN grading;
+ALTER TABLE questions DROP COLUMN correct_answers;
+ALTER TABLE questions DROP COLUMN type;
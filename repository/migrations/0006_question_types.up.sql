@@ -0,0 +1,8 @@
+-- Existing questions are single-choice; an empty type means the same
+ALTER TABLE questions ADD COLUMN type TEXT NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN correct_answers JSONB;
+ALTER TABLE questions ADD COLUMN grading TEXT NOT NULL DEFAULT '';
+
+-- Partial credit makes scores fractional
+ALTER TABLE scores ALTER COLUMN score TYPE DOUBLE PRECISION;
+ALTER TABLE scores ALTER COLUMN max_score TYPE DOUBLE PRECISION;
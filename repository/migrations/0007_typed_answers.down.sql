@@ -0,0 +1,5 @@
+ALTER TABLE questions DROP COLUMN tolerance_mode;
+ALTER TABLE questions DROP COLUMN tolerance;
+ALTER TABLE questions DROP COLUMN numeric_answer;
+ALTER TABLE questions DROP COLUMN fuzzy_distance;
+ALTER TABLE questions DROP COLUMN accepted_answers;
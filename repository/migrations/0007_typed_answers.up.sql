@@ -0,0 +1,6 @@
+-- Answer keys of short-answer and numeric questions; choice questions leave them at their defaults
+ALTER TABLE questions ADD COLUMN accepted_answers JSONB;
+ALTER TABLE questions ADD COLUMN fuzzy_distance INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE questions ADD COLUMN numeric_answer DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE questions ADD COLUMN tolerance DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE questions ADD COLUMN tolerance_mode TEXT NOT NULL DEFAULT '';
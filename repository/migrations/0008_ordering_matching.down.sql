@@ -0,0 +1,3 @@
+ALTER TABLE questions DROP COLUMN correct_matches;
+ALTER TABLE questions DROP COLUMN prompts;
+ALTER TABLE questions DROP COLUMN correct_order;
@@ -0,0 +1,4 @@
+-- Answer keys of ordering and matching questions; matching prompts are stored with them
+ALTER TABLE questions ADD COLUMN correct_order JSONB;
+ALTER TABLE questions ADD COLUMN prompts JSONB;
+ALTER TABLE questions ADD COLUMN correct_matches JSONB;
@@ -0,0 +1,5 @@
+ALTER TABLE questions DROP COLUMN weight;
+
+ALTER TABLE quizzes DROP COLUMN time_bonus;
+ALTER TABLE quizzes DROP COLUMN penalty;
+ALTER TABLE quizzes DROP COLUMN scoring;
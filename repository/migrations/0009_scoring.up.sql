@@ -0,0 +1,6 @@
+-- Scoring strategy per quiz; empty and zero values fall back to the service defaults
+ALTER TABLE quizzes ADD COLUMN scoring TEXT NOT NULL DEFAULT '';
+ALTER TABLE quizzes ADD COLUMN penalty DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE quizzes ADD COLUMN time_bonus DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE questions ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 0;
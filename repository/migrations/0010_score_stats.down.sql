@@ -0,0 +1,2 @@
+CREATE INDEX scores_quiz_id_idx ON scores (quiz_id);
+DROP INDEX scores_quiz_id_created_at_idx;
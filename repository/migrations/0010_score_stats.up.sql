@@ -0,0 +1,3 @@
+-- Statistics select a quiz's scores by when they were recorded
+CREATE INDEX scores_quiz_id_created_at_idx ON scores (quiz_id, created_at);
+DROP INDEX scores_quiz_id_idx;
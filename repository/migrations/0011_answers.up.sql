@@ -0,0 +1,13 @@
+-- Every graded answer, kept for item analysis. Answers outlive their question, but not their quiz.
+CREATE TABLE answers (
+    id          BIGSERIAL        PRIMARY KEY,
+    quiz_id     INTEGER          NOT NULL REFERENCES quizzes (id) ON DELETE CASCADE,
+    question_id INTEGER          NOT NULL,
+    correct     BOOLEAN          NOT NULL,
+    points      DOUBLE PRECISION NOT NULL,
+    choices     JSONB,
+    total       DOUBLE PRECISION NOT NULL,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX answers_quiz_id_question_id_idx ON answers (quiz_id, question_id);
@@ -0,0 +1,4 @@
+ALTER TABLE questions DROP COLUMN pinned_alternatives;
+
+ALTER TABLE quizzes DROP COLUMN shuffle_alternatives;
+ALTER TABLE quizzes DROP COLUMN shuffle_questions;
@@ -0,0 +1,5 @@
+-- Per-attempt shuffling; existing quizzes keep serving everything in order
+ALTER TABLE quizzes ADD COLUMN shuffle_questions BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE quizzes ADD COLUMN shuffle_alternatives BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE questions ADD COLUMN pinned_alternatives JSONB;
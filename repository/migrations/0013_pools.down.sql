@@ -0,0 +1,3 @@
+ALTER TABLE questions DROP COLUMN pool;
+
+ALTER TABLE quizzes DROP COLUMN draw;
@@ -0,0 +1,4 @@
+-- Question pools; quizzes without draw rules keep serving every question
+ALTER TABLE quizzes ADD COLUMN draw JSONB;
+
+ALTER TABLE questions ADD COLUMN pool TEXT NOT NULL DEFAULT '';
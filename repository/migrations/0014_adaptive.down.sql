@@ -0,0 +1,6 @@
+ALTER TABLE questions DROP COLUMN discrimination;
+ALTER TABLE questions DROP COLUMN difficulty;
+
+ALTER TABLE quizzes DROP COLUMN target_error;
+ALTER TABLE quizzes DROP COLUMN max_questions;
+ALTER TABLE quizzes DROP COLUMN adaptive;
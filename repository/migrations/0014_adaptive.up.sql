@@ -0,0 +1,7 @@
+-- Adaptive quizzes and the item response theory parameters of their questions
+ALTER TABLE quizzes ADD COLUMN adaptive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE quizzes ADD COLUMN max_questions INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE quizzes ADD COLUMN target_error DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE questions ADD COLUMN difficulty DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE questions ADD COLUMN discrimination DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- Spaced-repetition practice: each user's review schedule per question, kept apart from scores and answers.
+-- States outlive their question, like answers do.
+CREATE TABLE reviews (
+    user_id       TEXT             NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    question_id   INTEGER          NOT NULL,
+    reviews       INTEGER          NOT NULL DEFAULT 0,
+    lapses        INTEGER          NOT NULL DEFAULT 0,
+    repetitions   INTEGER          NOT NULL DEFAULT 0,
+    interval_ms   BIGINT           NOT NULL DEFAULT 0,
+    ease          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_reviewed TIMESTAMPTZ,
+    due           TIMESTAMPTZ,
+    PRIMARY KEY (user_id, question_id)
+);
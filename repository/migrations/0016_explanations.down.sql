@@ -0,0 +1,4 @@
+ALTER TABLE questions DROP COLUMN feedback;
+ALTER TABLE questions DROP COLUMN explanation;
+
+ALTER TABLE quizzes DROP COLUMN review_policy;
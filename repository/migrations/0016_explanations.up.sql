@@ -0,0 +1,5 @@
+-- Explanations and per-alternative feedback shown when players review finished attempts
+ALTER TABLE quizzes ADD COLUMN review_policy TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE questions ADD COLUMN explanation TEXT NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN feedback JSONB;
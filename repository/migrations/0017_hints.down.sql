@@ -0,0 +1,2 @@
+ALTER TABLE questions DROP COLUMN hint_penalty;
+ALTER TABLE questions DROP COLUMN hints;
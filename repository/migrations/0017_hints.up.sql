@@ -0,0 +1,3 @@
+-- Hints players can reveal during an attempt, each costing a share of the question's points
+ALTER TABLE questions ADD COLUMN hints JSONB;
+ALTER TABLE questions ADD COLUMN hint_penalty DOUBLE PRECISION NOT NULL DEFAULT 0;
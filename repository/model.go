@@ -1,9 +1,245 @@
 package repository
 
+import "time"
+
+// DefaultQuizID is the quiz that always exists and holds questions added without a quiz.
+const DefaultQuizID = 1
+
+// Quiz is a named set of questions with its own settings and score history.
+type Quiz struct {
+	ID          int
+	Name        string
+	Description string
+	TimeLimit   time.Duration // Zero means the service default applies
+	LatePolicy  string        // Empty means the service default applies
+	Scoring     string        // Name of the scoring strategy; empty means the service default applies
+	Penalty     float64       // Points a wrong answer costs under negative marking; zero means the service default
+	TimeBonus   float64       // Share of the score a timed attempt can add by finishing early; zero means the service default
+	// Attempts serve the questions, and the alternatives of each, in an order of their own
+	ShuffleQuestions    bool
+	ShuffleAlternatives bool
+	// Draw picks each attempt's questions at random from the quiz's pools; empty serves every question
+	Draw []DrawRule
+	// Adaptive attempts serve one question at a time, picked from the player's estimated ability, until the
+	// estimate's standard error is at most TargetError or MaxQuestions were answered. Zero means the service default.
+	Adaptive     bool
+	MaxQuestions int
+	TargetError  float64
+	// ReviewPolicy is when players may review their finished attempts; empty means the service default
+	ReviewPolicy string
+}
+
+// DrawRule asks for Count questions drawn at random from the quiz's questions in Pool.
+type DrawRule struct {
+	Pool  string
+	Count int
+}
+
+// Question types. An empty type is a single-choice question, as stored before types existed.
+const (
+	QuestionTypeSingle      = "single"       // Exactly one alternative is correct: CorrectAnswer
+	QuestionTypeMulti       = "multi"        // Select all that apply: CorrectAnswers
+	QuestionTypeShortAnswer = "short-answer" // Typed text matched against AcceptedAnswers
+	QuestionTypeNumeric     = "numeric"      // A number within Tolerance of NumericAnswer
+	QuestionTypeOrdering    = "ordering"     // Alternatives put in CorrectOrder
+	QuestionTypeMatching    = "matching"     // Each of Prompts matched to one of Alternatives
+)
+
+// Tolerance modes of numeric questions. An empty mode is absolute.
+const (
+	ToleranceAbsolute = "absolute" // Tolerance is a distance from NumericAnswer
+	ToleranceRelative = "relative" // Tolerance is a fraction of NumericAnswer
+)
+
 // Question represents a question in the repository layer.
 type Question struct {
-	ID            int
-	QuestionText  string
-	Alternatives  []string
+	ID           int
+	QuizID       int // Zero is stored as DefaultQuizID
+	Type         string
+	QuestionText string
+	Alternatives []string
+	TimeLimit    time.Duration // Zero means the question is not individually timed
+	Weight       float64       // Relative worth under weighted scoring; zero counts as 1
+	Pool         string        // Pool the quiz's draw rules pick the question from, such as "easy-geography"
+	// Item response theory parameters for adaptive quizzes: Difficulty is on the ability scale, and Discrimination
+	// is how sharply the question tells abilities around it apart. Zero discrimination means not calibrated.
+	Difficulty     float64
+	Discrimination float64
+	// PinnedAlternatives are indices of Alternatives that keep their position when alternatives are shuffled
+	PinnedAlternatives []int
+	// Shown when a finished attempt is reviewed: why the answer is what it is and, unless Feedback is empty,
+	// a remark on each alternative, in the order of Alternatives
+	Explanation string
+	Feedback    []string
+	// Hints are revealed one at a time during an attempt; each revealed hint takes HintPenalty, a share of the
+	// question's points, off what the question can earn
+	Hints       []string
+	HintPenalty float64
+	// Single-choice questions
 	CorrectAnswer int
+	// Multi-select questions
+	CorrectAnswers []int
+	Grading        string // Empty means the service default
+	// Short-answer questions
+	AcceptedAnswers []string // Any of these, compared without case and extra whitespace, is correct
+	FuzzyDistance   int      // Typos (edits) tolerated against an accepted answer; zero requires an exact match
+	// Numeric questions
+	NumericAnswer float64
+	Tolerance     float64
+	ToleranceMode string // Empty means ToleranceAbsolute
+	// Ordering questions
+	CorrectOrder []int // Indices of Alternatives in the correct order
+	// Matching questions
+	Prompts        []string
+	CorrectMatches []int // For each prompt, the index of its alternative
+}
+
+// User is a registered player. Players authenticate with a token; only its hash is stored.
+type User struct {
+	ID        string
+	Name      string
+	TokenHash string
+	CreatedAt time.Time
+}
+
+// ScoreRecord is one graded quiz result.
+type ScoreRecord struct {
+	UserID     string        // Empty for anonymous players
+	QuizID     int           // Zero is stored as DefaultQuizID
+	Score      float64       // Points earned; fractional when questions award partial credit
+	MaxScore   float64       // The score a perfect result would have had; zero for records stored before it was tracked
+	Elapsed    time.Duration // Zero when the result was not timed
+	RecordedAt time.Time
+}
+
+// ScoreFilter selects the scores of one quiz recorded in [From, To); a zero time leaves that end open.
+type ScoreFilter struct {
+	QuizID  int // Zero selects DefaultQuizID
+	From    time.Time
+	To      time.Time
+	Buckets int // Histogram buckets; must be positive
+}
+
+// ScoreStats summarises the scores a ScoreFilter selects, each as a percentage of its own maximum so results
+// worth different points compare fairly. Scores stored without a maximum are left out. Quartiles interpolate
+// linearly between scores; they, the minimum and the maximum are exact to a hundredth of a percent. The standard
+// deviation is that of the population. Everything is zero when no score matched.
+type ScoreStats struct {
+	Count  int
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	Q1     float64
+	Median float64
+	Q3     float64
+	// Histogram counts the scores in equal buckets from 0% to 100%; a perfect score falls in the last one.
+	Histogram []int
+}
+
+// AnswerRecord is one graded answer of a recorded result, kept for item analysis.
+type AnswerRecord struct {
+	QuizID     int // Zero is stored as DefaultQuizID
+	QuestionID int
+	Correct    bool    // The answer earned every point
+	Points     float64 // Between 0 and 1; zero for unanswered questions
+	Choices    []int   // Alternatives the player picked, for choice questions
+	Total      float64 // The whole result's score as a fraction of its maximum
+	RecordedAt time.Time
+}
+
+// Result is one graded quiz result: its score together with the graded answers behind it.
+type Result struct {
+	Score   ScoreRecord
+	Answers []AnswerRecord
+}
+
+// ItemStats aggregates the recorded answers to one question.
+type ItemStats struct {
+	QuestionID int
+	Responses  int
+	Correct    int
+	MeanPoints float64
+	// Discrimination is the Pearson correlation of the answers' points with their results' totals, which is
+	// the point-biserial correlation for questions scored right or wrong. Zero when either does not vary.
+	Discrimination float64
+	ChoiceCounts   map[int]int // Times each alternative was picked
+}
+
+// ReviewState is a user's spaced-repetition schedule for one question. Practice reviews are kept apart
+// from scores and graded answers, so they never count towards results or statistics.
+type ReviewState struct {
+	UserID       string
+	QuestionID   int
+	Reviews      int           // Every review so far
+	Lapses       int           // Failed reviews of a question that had been recalled before
+	Repetitions  int           // Successful reviews in a row
+	Interval     time.Duration // From the last review to the next
+	Ease         float64       // How fast the interval grows; zero before the first review
+	LastReviewed time.Time
+	Due          time.Time
+}
+
+// Attempt is a player's server-side quiz session.
+type Attempt struct {
+	ID          string
+	UserID      string // The player who started the attempt; empty for anonymous players
+	QuizID      int    // Zero is treated as DefaultQuizID
+	QuestionIDs []int  // Questions served when the attempt started, in order
+	Seed        int64  // Seeds the attempt's shuffles, so its order can be reproduced
+	// ShuffleAlternatives is set when the attempt shows every question's alternatives in an order derived from Seed.
+	// Saved answers always use the canonical alternative indices.
+	ShuffleAlternatives bool
+	Answers             map[int]int       // Question ID to chosen alternative, for single-choice questions
+	Responses           map[int]Response  // Question ID to the answer, for every other question type
+	ServedAt            map[int]time.Time // Question ID to when it was first shown, for per-question time limits
+	Hints               map[int]int       // Question ID to how many of its hints were revealed
+	StartedAt           time.Time
+	ExpiresAt           time.Time
+	Deadline            time.Time // Zero when the quiz is not timed
+	LatePolicy          string    // What happens to answers that arrive after the deadline
+	FinishedAt          time.Time // Zero until the attempt is finished
+	Elapsed             time.Duration
+	Score               float64
+	MaxScore            float64 // Zero for attempts finished before it was tracked
+	Comparison          string
+	Standing            Standing // Zero for attempts finished before it was tracked
+	Results             []AttemptResult
+
+	// Adaptive attempts add each next question to QuestionIDs once the previous one is answered
+	Adaptive     bool
+	Ability      float64 // Estimated from the answers so far
+	AbilityError float64 // Standard error of Ability
+}
+
+// Standing places a finished attempt among the other results of its quiz.
+type Standing struct {
+	Rank       int
+	Of         int
+	BetterThan int
+	TiedWith   int
+	WorseThan  int
+	Percentile float64
+}
+
+// Response is a player's answer to a question that is not single-choice.
+type Response struct {
+	Choices []int    // Selected alternatives of a multi-select question
+	Text    string   // Typed answer to a short-answer question
+	Number  *float64 // Answer to a numeric question
+	Order   []int    // Alternatives of an ordering question, in the order given
+	Matches []int    // For each prompt of a matching question, the chosen alternative
+}
+
+// AttemptResult records how one question of a finished attempt was graded.
+type AttemptResult struct {
+	QuestionID int
+	Status     string
+	Points     float64
+	Hints      int // Hints revealed, already taken off Points
+}
+
+// Finished reports whether the attempt has been graded.
+func (a Attempt) Finished() bool {
+	return !a.FinishedAt.IsZero()
 }
@@ -0,0 +1,913 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PostgreSQL error codes the repository translates into its own errors.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// PostgresRepository is a Repository backed by PostgreSQL.
+// The schema is managed by MigrateUp/MigrateDown; run MigrateUp before serving requests.
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+var _ Repository = (*PostgresRepository)(nil)
+
+// NewPostgresRepository creates a repository on top of an open database handle.
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetAllQuizzes returns all quizzes sorted by ID.
+func (p *PostgresRepository) GetAllQuizzes(ctx context.Context) ([]Quiz, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT "+quizColumns+" FROM quizzes ORDER BY id")
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	quizzes := []Quiz{}
+	for rows.Next() {
+		quiz, err := scanQuiz(rows)
+		if err != nil {
+			return nil, err
+		}
+		quizzes = append(quizzes, quiz)
+	}
+	return quizzes, mapPostgresError(rows.Err())
+}
+
+// GetQuiz returns a quiz by its ID.
+func (p *PostgresRepository) GetQuiz(ctx context.Context, id int) (Quiz, error) {
+	quiz, err := scanQuiz(p.db.QueryRowContext(ctx,
+		"SELECT "+quizColumns+" FROM quizzes WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Quiz{}, ErrQuizNotFound
+	}
+	return quiz, mapPostgresError(err)
+}
+
+// AddQuiz adds a new quiz.
+func (p *PostgresRepository) AddQuiz(ctx context.Context, quiz Quiz) error {
+	if err := validateQuiz(quiz); err != nil {
+		return err
+	}
+	values, err := quizValues(quiz)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx,
+		"INSERT INTO quizzes ("+quizColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)", values...)
+	if hasSQLState(err, pgUniqueViolation) {
+		return ErrQuizExists
+	}
+	return mapPostgresError(err)
+}
+
+// UpdateQuiz replaces an existing quiz, matched by ID.
+func (p *PostgresRepository) UpdateQuiz(ctx context.Context, quiz Quiz) error {
+	if err := validateQuiz(quiz); err != nil {
+		// Report a missing quiz before invalid input, like the other implementations
+		if _, getErr := p.GetQuiz(ctx, quiz.ID); getErr != nil {
+			return getErr
+		}
+		return err
+	}
+	values, err := quizValues(quiz)
+	if err != nil {
+		return err
+	}
+
+	result, err := p.db.ExecContext(ctx,
+		"UPDATE quizzes SET name = $2, description = $3, time_limit_ms = $4, late_policy = $5, "+
+			"scoring = $6, penalty = $7, time_bonus = $8, shuffle_questions = $9, shuffle_alternatives = $10, draw = $11, "+
+			"adaptive = $12, max_questions = $13, target_error = $14, review_policy = $15 WHERE id = $1",
+		values...)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+	return requireAffected(result, ErrQuizNotFound)
+}
+
+// DeleteQuiz removes a quiz; its questions and scores go with it through the foreign key cascade.
+// Attempts are JSON documents without a foreign key, so they are removed explicitly in the same transaction.
+func (p *PostgresRepository) DeleteQuiz(ctx context.Context, id int) error {
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM quizzes WHERE id = $1", id)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result, ErrQuizNotFound); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM attempts WHERE (data->>'QuizID')::int = $1", id)
+		return err
+	})
+}
+
+// AddQuestion adds a new question and its alternatives in one transaction.
+func (p *PostgresRepository) AddQuestion(ctx context.Context, question Question) error {
+	// Validate the question data
+	question.QuizID = normalizeQuizID(question.QuizID)
+	if err := validateQuestion(question); err != nil {
+		return err
+	}
+
+	values, err := questionValues(question)
+	if err != nil {
+		return err
+	}
+
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO questions ("+questionColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)",
+			values...)
+		if hasSQLState(err, pgForeignKeyViolation) {
+			return ErrQuizNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return insertAlternatives(ctx, tx, question)
+	})
+}
+
+// UpdateQuestion replaces an existing question and its alternatives.
+func (p *PostgresRepository) UpdateQuestion(ctx context.Context, question Question) error {
+	question.QuizID = normalizeQuizID(question.QuizID)
+
+	values, err := questionValues(question)
+	if err != nil {
+		return err
+	}
+
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE questions SET quiz_id = $2, type = $3, question_text = $4, correct_answer = $5, correct_answers = $6, grading = $7, "+
+				"accepted_answers = $8, fuzzy_distance = $9, numeric_answer = $10, tolerance = $11, tolerance_mode = $12, "+
+				"correct_order = $13, prompts = $14, correct_matches = $15, time_limit_ms = $16, weight = $17, pinned_alternatives = $18, pool = $19, "+
+				"difficulty = $20, discrimination = $21, explanation = $22, feedback = $23, hints = $24, hint_penalty = $25 "+
+				"WHERE id = $1",
+			values...)
+		if hasSQLState(err, pgForeignKeyViolation) {
+			return ErrQuizNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result, ErrQuestionNotFound); err != nil {
+			return err
+		}
+
+		// Validate after the existence check so a missing question reports ErrQuestionNotFound
+		if err := validateQuestion(question); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM alternatives WHERE question_id = $1", question.ID); err != nil {
+			return err
+		}
+		return insertAlternatives(ctx, tx, question)
+	})
+}
+
+// DeleteQuestion removes a question; its alternatives are removed by the foreign key cascade.
+func (p *PostgresRepository) DeleteQuestion(ctx context.Context, id int) error {
+	result, err := p.db.ExecContext(ctx, "DELETE FROM questions WHERE id = $1", id)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+	return requireAffected(result, ErrQuestionNotFound)
+}
+
+// GetAllQuestions returns all quiz questions sorted by ID.
+func (p *PostgresRepository) GetAllQuestions(ctx context.Context) ([]Question, error) {
+	return p.queryQuestions(ctx, "")
+}
+
+// GetQuestionsByQuiz returns the questions of one quiz sorted by ID.
+func (p *PostgresRepository) GetQuestionsByQuiz(ctx context.Context, quizID int) ([]Question, error) {
+	return p.queryQuestions(ctx, "WHERE quiz_id = $1", normalizeQuizID(quizID))
+}
+
+// queryQuestions loads the questions matching filter, a WHERE clause over the questions table, with their alternatives.
+func (p *PostgresRepository) queryQuestions(ctx context.Context, filter string, args ...any) ([]Question, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT "+questionColumns+" FROM questions "+filter+" ORDER BY id", args...)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	questions := []Question{}
+	index := make(map[int]int)
+	for rows.Next() {
+		question, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[question.ID] = len(questions)
+		questions = append(questions, question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapPostgresError(err)
+	}
+
+	// Load all alternatives in a single query and attach them to their questions
+	altRows, err := p.db.QueryContext(ctx,
+		"SELECT question_id, text FROM alternatives WHERE question_id IN (SELECT id FROM questions "+filter+") ORDER BY question_id, position",
+		args...)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = altRows.Close()
+	}()
+
+	for altRows.Next() {
+		var questionID int
+		var text string
+		if err := altRows.Scan(&questionID, &text); err != nil {
+			return nil, err
+		}
+		if i, ok := index[questionID]; ok {
+			questions[i].Alternatives = append(questions[i].Alternatives, text)
+		}
+	}
+	if err := altRows.Err(); err != nil {
+		return nil, mapPostgresError(err)
+	}
+
+	return questions, nil
+}
+
+// GetQuestionByID returns a question by its ID.
+func (p *PostgresRepository) GetQuestionByID(ctx context.Context, id int) (Question, error) {
+	question, err := scanQuestion(p.db.QueryRowContext(ctx, "SELECT "+questionColumns+" FROM questions WHERE id = $1", id))
+	if err != nil {
+		return Question{}, mapPostgresError(err)
+	}
+
+	alternatives, err := p.alternatives(ctx, id)
+	if err != nil {
+		return Question{}, err
+	}
+	question.Alternatives = alternatives
+
+	return question, nil
+}
+
+// AddScore adds a user's score to the repository.
+func (p *PostgresRepository) AddScore(ctx context.Context, score ScoreRecord) error {
+	return p.insertScore(ctx, p.db, score)
+}
+
+// sqlExecer runs statements on the database or inside a transaction.
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertScore inserts a score with db, reporting a missing quiz or user like the other repositories.
+func (p *PostgresRepository) insertScore(ctx context.Context, db sqlExecer, score ScoreRecord) error {
+	recordedAt := score.RecordedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+
+	// Anonymous scores store NULL so the user foreign key does not apply
+	var userID sql.NullString
+	if score.UserID != "" {
+		userID = sql.NullString{String: score.UserID, Valid: true}
+	}
+
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO scores (user_id, quiz_id, score, max_score, elapsed_ms, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		userID, normalizeQuizID(score.QuizID), score.Score, score.MaxScore, score.Elapsed.Milliseconds(), recordedAt)
+	if hasSQLState(err, pgForeignKeyViolation) {
+		// Both the quiz and the user are foreign keys; report whichever is missing
+		if _, quizErr := p.GetQuiz(ctx, normalizeQuizID(score.QuizID)); quizErr != nil {
+			return quizErr
+		}
+		return ErrUserNotFound
+	}
+	return mapPostgresError(err)
+}
+
+// GetAllScores returns all the stored quiz scores in insertion order.
+func (p *PostgresRepository) GetAllScores(ctx context.Context) ([]ScoreRecord, error) {
+	return p.queryScores(ctx, "")
+}
+
+// GetScoresByQuiz returns the scores recorded for one quiz in insertion order.
+func (p *PostgresRepository) GetScoresByQuiz(ctx context.Context, quizID int) ([]ScoreRecord, error) {
+	return p.queryScores(ctx, "WHERE quiz_id = $1", normalizeQuizID(quizID))
+}
+
+// GetScoresByUser returns the scores recorded for one user in insertion order.
+func (p *PostgresRepository) GetScoresByUser(ctx context.Context, userID string) ([]ScoreRecord, error) {
+	return p.queryScores(ctx, "WHERE user_id = $1", userID)
+}
+
+// GetScoreStats aggregates the scores the filter selects in the database; only the summary is transferred.
+func (p *PostgresRepository) GetScoreStats(ctx context.Context, filter ScoreFilter) (ScoreStats, error) {
+	if filter.Buckets <= 0 {
+		return ScoreStats{}, ErrInvalidScoreFilter
+	}
+
+	// Zero bounds are passed as NULL, which leaves that end of the range open
+	var from, to sql.NullTime
+	if !filter.From.IsZero() {
+		from = sql.NullTime{Time: filter.From, Valid: true}
+	}
+	if !filter.To.IsZero() {
+		to = sql.NullTime{Time: filter.To, Valid: true}
+	}
+	// Scores count as percentages of their maximum; quartiles, minimum and maximum use them rounded to a
+	// hundredth of a percent, as the other repositories do
+	const percents = `
+		WITH percents AS (
+			SELECT 100 * score / max_score AS percent, ROUND((100 * score / max_score)::NUMERIC, 2)::DOUBLE PRECISION AS rounded
+			FROM scores
+			WHERE quiz_id = $1 AND max_score > 0 AND ($2::timestamptz IS NULL OR created_at >= $2) AND ($3::timestamptz IS NULL OR created_at < $3)
+		)`
+	args := []any{normalizeQuizID(filter.QuizID), from, to}
+
+	var stats ScoreStats
+	err := p.db.QueryRowContext(ctx, percents+`
+		SELECT COUNT(*), COALESCE(AVG(percent), 0), COALESCE(STDDEV_POP(percent), 0), COALESCE(MIN(rounded), 0), COALESCE(MAX(rounded), 0),
+		       COALESCE(PERCENTILE_CONT(0.25) WITHIN GROUP (ORDER BY rounded), 0),
+		       COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY rounded), 0),
+		       COALESCE(PERCENTILE_CONT(0.75) WITHIN GROUP (ORDER BY rounded), 0)
+		FROM percents`, args...).
+		Scan(&stats.Count, &stats.Mean, &stats.StdDev, &stats.Min, &stats.Max, &stats.Q1, &stats.Median, &stats.Q3)
+	if err != nil {
+		return ScoreStats{}, mapPostgresError(err)
+	}
+
+	stats.Histogram = make([]int, filter.Buckets)
+	rows, err := p.db.QueryContext(ctx, percents+`
+		SELECT LEAST(GREATEST(FLOOR(percent / 100 * $4)::INTEGER, 0), $4 - 1) AS bucket, COUNT(*)
+		FROM percents
+		GROUP BY bucket`, append(args, filter.Buckets)...)
+	if err != nil {
+		return ScoreStats{}, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return ScoreStats{}, err
+		}
+		stats.Histogram[bucket] = count
+	}
+	return stats, mapPostgresError(rows.Err())
+}
+
+// AddAnswers stores the graded answers of one result in one transaction.
+func (p *PostgresRepository) AddAnswers(ctx context.Context, answers []AnswerRecord) error {
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		return insertAnswers(ctx, tx, answers)
+	})
+}
+
+// insertAnswers inserts answers inside the transaction.
+func insertAnswers(ctx context.Context, tx *sql.Tx, answers []AnswerRecord) error {
+	for _, answer := range answers {
+		recordedAt := answer.RecordedAt
+		if recordedAt.IsZero() {
+			recordedAt = time.Now()
+		}
+		choices, err := json.Marshal(answer.Choices)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO answers (quiz_id, question_id, correct, points, choices, total, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			normalizeQuizID(answer.QuizID), answer.QuestionID, answer.Correct, answer.Points, choices, answer.Total, recordedAt)
+		if hasSQLState(err, pgForeignKeyViolation) {
+			return ErrQuizNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddResult stores a score and its answers in one transaction.
+func (p *PostgresRepository) AddResult(ctx context.Context, result Result) error {
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		if err := p.insertScore(ctx, tx, result.Score); err != nil {
+			return err
+		}
+		return insertAnswers(ctx, tx, result.Answers)
+	})
+}
+
+// GetItemStats aggregates the quiz's answers in the database; only the per-question summaries are transferred.
+func (p *PostgresRepository) GetItemStats(ctx context.Context, quizID int) ([]ItemStats, error) {
+	quizID = normalizeQuizID(quizID)
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT question_id, COUNT(*), COUNT(*) FILTER (WHERE correct), AVG(points), COALESCE(CORR(points, total), 0)
+		FROM answers WHERE quiz_id = $1
+		GROUP BY question_id ORDER BY question_id`, quizID)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	items := []ItemStats{}
+	index := map[int]int{}
+	for rows.Next() {
+		item := ItemStats{ChoiceCounts: map[int]int{}}
+		if err := rows.Scan(&item.QuestionID, &item.Responses, &item.Correct, &item.MeanPoints, &item.Discrimination); err != nil {
+			return nil, err
+		}
+		index[item.QuestionID] = len(items)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapPostgresError(err)
+	}
+
+	choiceRows, err := p.db.QueryContext(ctx, `
+		SELECT question_id, choice::INTEGER, COUNT(*)
+		FROM answers, jsonb_array_elements_text(CASE WHEN jsonb_typeof(choices) = 'array' THEN choices ELSE '[]' END) AS choice
+		WHERE quiz_id = $1
+		GROUP BY question_id, choice`, quizID)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = choiceRows.Close()
+	}()
+
+	for choiceRows.Next() {
+		var questionID, choice, count int
+		if err := choiceRows.Scan(&questionID, &choice, &count); err != nil {
+			return nil, err
+		}
+		if i, ok := index[questionID]; ok {
+			items[i].ChoiceCounts[choice] = count
+		}
+	}
+	return items, mapPostgresError(choiceRows.Err())
+}
+
+// queryScores loads the scores matching filter, a WHERE clause over the scores table.
+func (p *PostgresRepository) queryScores(ctx context.Context, filter string, args ...any) ([]ScoreRecord, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT COALESCE(user_id, ''), quiz_id, score, max_score, elapsed_ms, created_at FROM scores "+filter+" ORDER BY id", args...)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	scores := []ScoreRecord{}
+	for rows.Next() {
+		var score ScoreRecord
+		var elapsedMS int64
+		if err := rows.Scan(&score.UserID, &score.QuizID, &score.Score, &score.MaxScore, &elapsedMS, &score.RecordedAt); err != nil {
+			return nil, err
+		}
+		score.Elapsed = time.Duration(elapsedMS) * time.Millisecond
+		scores = append(scores, score)
+	}
+	return scores, mapPostgresError(rows.Err())
+}
+
+// AddUser stores a new user.
+func (p *PostgresRepository) AddUser(ctx context.Context, user User) error {
+	if err := validateUser(user); err != nil {
+		return err
+	}
+
+	createdAt := user.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err := p.db.ExecContext(ctx,
+		"INSERT INTO users (id, name, token_hash, created_at) VALUES ($1, $2, $3, $4)",
+		user.ID, user.Name, user.TokenHash, createdAt)
+	if hasSQLState(err, pgUniqueViolation) {
+		return ErrUserExists
+	}
+	return mapPostgresError(err)
+}
+
+// GetUser returns a user by its ID.
+func (p *PostgresRepository) GetUser(ctx context.Context, id string) (User, error) {
+	return p.queryUser(ctx, "id", id)
+}
+
+// GetUserByTokenHash returns the user whose token hashes to tokenHash.
+func (p *PostgresRepository) GetUserByTokenHash(ctx context.Context, tokenHash string) (User, error) {
+	return p.queryUser(ctx, "token_hash", tokenHash)
+}
+
+// queryUser loads the user whose column equals value; column is never user input.
+func (p *PostgresRepository) queryUser(ctx context.Context, column, value string) (User, error) {
+	var user User
+	err := p.db.QueryRowContext(ctx,
+		"SELECT id, name, token_hash, created_at FROM users WHERE "+column+" = $1", value).
+		Scan(&user.ID, &user.Name, &user.TokenHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return User{}, mapPostgresError(err)
+	}
+	return user, nil
+}
+
+// CreateAttempt stores a new attempt as a JSON document.
+func (p *PostgresRepository) CreateAttempt(ctx context.Context, attempt Attempt) error {
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, "INSERT INTO attempts (id, data) VALUES ($1, $2)", attempt.ID, data)
+	if hasSQLState(err, pgUniqueViolation) {
+		return ErrAttemptExists
+	}
+	return mapPostgresError(err)
+}
+
+// GetAttempt returns an attempt by its ID.
+func (p *PostgresRepository) GetAttempt(ctx context.Context, id string) (Attempt, error) {
+	var data []byte
+	err := p.db.QueryRowContext(ctx, "SELECT data FROM attempts WHERE id = $1", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Attempt{}, ErrAttemptNotFound
+	}
+	if err != nil {
+		return Attempt{}, mapPostgresError(err)
+	}
+
+	var attempt Attempt
+	if err := json.Unmarshal(data, &attempt); err != nil {
+		return Attempt{}, err
+	}
+	return attempt, nil
+}
+
+// UpdateAttempt locks the attempt row, applies fn and writes the result in one transaction.
+func (p *PostgresRepository) UpdateAttempt(ctx context.Context, id string, fn func(attempt *Attempt) error) error {
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		var data []byte
+		err := tx.QueryRowContext(ctx, "SELECT data FROM attempts WHERE id = $1 FOR UPDATE", id).Scan(&data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttemptNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var attempt Attempt
+		if err := json.Unmarshal(data, &attempt); err != nil {
+			return err
+		}
+		if err := fn(&attempt); err != nil {
+			return err
+		}
+		attempt.ID = id // the key cannot change
+
+		if data, err = json.Marshal(attempt); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE attempts SET data = $2 WHERE id = $1", id, data)
+		return err
+	})
+}
+
+// FinishAttempt locks the attempt row, applies fn and writes the attempt and its result in one transaction.
+func (p *PostgresRepository) FinishAttempt(ctx context.Context, id string, fn func(attempt *Attempt) (Result, error)) error {
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		var data []byte
+		err := tx.QueryRowContext(ctx, "SELECT data FROM attempts WHERE id = $1 FOR UPDATE", id).Scan(&data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttemptNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var attempt Attempt
+		if err := json.Unmarshal(data, &attempt); err != nil {
+			return err
+		}
+		result, err := fn(&attempt)
+		if err != nil {
+			return err
+		}
+		if err := p.insertScore(ctx, tx, result.Score); err != nil {
+			return err
+		}
+		if err := insertAnswers(ctx, tx, result.Answers); err != nil {
+			return err
+		}
+		attempt.ID = id // the key cannot change
+
+		if data, err = json.Marshal(attempt); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE attempts SET data = $2 WHERE id = $1", id, data)
+		return err
+	})
+}
+
+// GetReviewStates returns a user's review states, sorted by question ID.
+func (p *PostgresRepository) GetReviewStates(ctx context.Context, userID string) ([]ReviewState, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE user_id = $1 ORDER BY question_id", userID)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	states := []ReviewState{}
+	for rows.Next() {
+		state, err := scanReviewState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+// UpdateReviewState locks the review state's row, creating it first if needed, and rewrites it in one transaction.
+// Creating the row before locking it means concurrent first reviews queue up instead of conflicting.
+func (p *PostgresRepository) UpdateReviewState(ctx context.Context, userID string, questionID int, fn func(state *ReviewState) error) error {
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO reviews (user_id, question_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userID, questionID)
+		if hasSQLState(err, pgForeignKeyViolation) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		state, err := scanReviewState(tx.QueryRowContext(ctx,
+			"SELECT "+reviewColumns+" FROM reviews WHERE user_id = $1 AND question_id = $2 FOR UPDATE", userID, questionID))
+		if err != nil {
+			return err
+		}
+		if err := fn(&state); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE reviews SET reviews = $3, lapses = $4, repetitions = $5, interval_ms = $6, ease = $7, last_reviewed = $8, due = $9 "+
+				"WHERE user_id = $1 AND question_id = $2",
+			userID, questionID, state.Reviews, state.Lapses, state.Repetitions, state.Interval.Milliseconds(), state.Ease,
+			nullTime(state.LastReviewed), nullTime(state.Due))
+		return err
+	})
+}
+
+// reviewColumns are the reviews columns read by scanReviewState, in order.
+const reviewColumns = "user_id, question_id, reviews, lapses, repetitions, interval_ms, ease, last_reviewed, due"
+
+// scanReviewState reads one reviews row selected as reviewColumns.
+func scanReviewState(row interface{ Scan(dest ...any) error }) (ReviewState, error) {
+	var state ReviewState
+	var intervalMS int64
+	var lastReviewed, due sql.NullTime
+	err := row.Scan(&state.UserID, &state.QuestionID, &state.Reviews, &state.Lapses, &state.Repetitions, &intervalMS, &state.Ease,
+		&lastReviewed, &due)
+	if err != nil {
+		return ReviewState{}, err
+	}
+	state.Interval = time.Duration(intervalMS) * time.Millisecond
+	state.LastReviewed, state.Due = lastReviewed.Time, due.Time
+	return state, nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// alternatives loads the ordered alternatives of one question.
+func (p *PostgresRepository) alternatives(ctx context.Context, questionID int) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT text FROM alternatives WHERE question_id = $1 ORDER BY position", questionID)
+	if err != nil {
+		return nil, mapPostgresError(err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var alternatives []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, text)
+	}
+	return alternatives, mapPostgresError(rows.Err())
+}
+
+// insertAlternatives stores the question's alternatives with their positions.
+func insertAlternatives(ctx context.Context, tx *sql.Tx, question Question) error {
+	for position, text := range question.Alternatives {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO alternatives (question_id, position, text) VALUES ($1, $2, $3)",
+			question.ID, position, text)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireAffected reports notFound when a statement matched no rows.
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// quizColumns are the quizzes columns written by quizValues and read by scanQuiz, in order.
+const quizColumns = "id, name, description, time_limit_ms, late_policy, scoring, penalty, time_bonus, shuffle_questions, shuffle_alternatives, draw, " +
+	"adaptive, max_questions, target_error, review_policy"
+
+// quizValues returns the quiz's values for quizColumns; the draw rules are stored as JSONB.
+func quizValues(quiz Quiz) ([]any, error) {
+	draw, err := json.Marshal(quiz.Draw)
+	if err != nil {
+		return nil, err
+	}
+	return []any{
+		quiz.ID, quiz.Name, quiz.Description, quiz.TimeLimit.Milliseconds(), quiz.LatePolicy,
+		quiz.Scoring, quiz.Penalty, quiz.TimeBonus, quiz.ShuffleQuestions, quiz.ShuffleAlternatives, draw,
+		quiz.Adaptive, quiz.MaxQuestions, quiz.TargetError, quiz.ReviewPolicy,
+	}, nil
+}
+
+// scanQuiz reads one quizzes row selected as quizColumns.
+func scanQuiz(row interface{ Scan(dest ...any) error }) (Quiz, error) {
+	var quiz Quiz
+	var timeLimitMS int64
+	var draw []byte
+	if err := row.Scan(&quiz.ID, &quiz.Name, &quiz.Description, &timeLimitMS, &quiz.LatePolicy, &quiz.Scoring, &quiz.Penalty, &quiz.TimeBonus,
+		&quiz.ShuffleQuestions, &quiz.ShuffleAlternatives, &draw, &quiz.Adaptive, &quiz.MaxQuestions, &quiz.TargetError,
+		&quiz.ReviewPolicy); err != nil {
+		return Quiz{}, err
+	}
+	if err := unmarshalColumn(draw, &quiz.Draw); err != nil {
+		return Quiz{}, err
+	}
+	quiz.TimeLimit = time.Duration(timeLimitMS) * time.Millisecond
+	return quiz, nil
+}
+
+// questionColumns are the questions columns written by questionValues and read by scanQuestion, in order.
+const questionColumns = "id, quiz_id, type, question_text, correct_answer, correct_answers, grading, " +
+	"accepted_answers, fuzzy_distance, numeric_answer, tolerance, tolerance_mode, correct_order, prompts, correct_matches, time_limit_ms, weight, pinned_alternatives, pool, difficulty, discrimination, " +
+	"explanation, feedback, hints, hint_penalty"
+
+// questionValues returns the question's values for questionColumns; alternatives are stored separately.
+func questionValues(question Question) ([]any, error) {
+	// The list columns are JSONB; marshal them in column order
+	lists := []any{question.CorrectAnswers, question.AcceptedAnswers, question.CorrectOrder, question.Prompts, question.CorrectMatches,
+		question.PinnedAlternatives, question.Feedback, question.Hints}
+	encoded := make([][]byte, len(lists))
+	for i, list := range lists {
+		data, err := json.Marshal(list)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = data
+	}
+
+	return []any{
+		question.ID, question.QuizID, question.Type, question.QuestionText, question.CorrectAnswer, encoded[0], question.Grading,
+		encoded[1], question.FuzzyDistance, question.NumericAnswer, question.Tolerance, question.ToleranceMode,
+		encoded[2], encoded[3], encoded[4], question.TimeLimit.Milliseconds(), question.Weight, encoded[5], question.Pool,
+		question.Difficulty, question.Discrimination, question.Explanation, encoded[6], encoded[7], question.HintPenalty,
+	}, nil
+}
+
+// scanQuestion reads one questions row selected as questionColumns; alternatives are loaded separately.
+func scanQuestion(row interface{ Scan(dest ...any) error }) (Question, error) {
+	var question Question
+	var correctAnswers, acceptedAnswers, correctOrder, prompts, correctMatches, pinnedAlternatives, feedback, hints []byte
+	var timeLimitMS int64
+	err := row.Scan(&question.ID, &question.QuizID, &question.Type, &question.QuestionText, &question.CorrectAnswer,
+		&correctAnswers, &question.Grading, &acceptedAnswers, &question.FuzzyDistance, &question.NumericAnswer,
+		&question.Tolerance, &question.ToleranceMode, &correctOrder, &prompts, &correctMatches, &timeLimitMS, &question.Weight,
+		&pinnedAlternatives, &question.Pool, &question.Difficulty, &question.Discrimination, &question.Explanation, &feedback,
+		&hints, &question.HintPenalty)
+	if err != nil {
+		return Question{}, err
+	}
+
+	columns := []struct {
+		data []byte
+		dest any
+	}{
+		{correctAnswers, &question.CorrectAnswers},
+		{acceptedAnswers, &question.AcceptedAnswers},
+		{correctOrder, &question.CorrectOrder},
+		{prompts, &question.Prompts},
+		{correctMatches, &question.CorrectMatches},
+		{pinnedAlternatives, &question.PinnedAlternatives},
+		{feedback, &question.Feedback},
+		{hints, &question.Hints},
+	}
+	for _, column := range columns {
+		if err := unmarshalColumn(column.data, column.dest); err != nil {
+			return Question{}, err
+		}
+	}
+	question.TimeLimit = time.Duration(timeLimitMS) * time.Millisecond
+	return question, nil
+}
+
+// unmarshalColumn decodes a nullable JSONB column, leaving v untouched for NULL.
+func unmarshalColumn(data []byte, v any) error {
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// withTx runs fn in a transaction, committing on success and rolling back otherwise.
+func (p *PostgresRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(tx); err != nil {
+		return mapPostgresError(err)
+	}
+	return mapPostgresError(tx.Commit())
+}
+
+// mapPostgresError translates driver errors into the repository's question errors.
+func mapPostgresError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrQuestionNotFound
+	}
+
+	switch {
+	case hasSQLState(err, pgUniqueViolation):
+		return ErrQuestionExists
+	case hasSQLState(err, pgForeignKeyViolation):
+		return ErrQuestionNotFound
+	}
+	return err
+}
+
+// hasSQLState reports whether err is a driver error with the given SQLSTATE code.
+// Drivers expose it through a SQLState method (pgx's *pgconn.PgError, lib/pq's *pq.Error).
+func hasSQLState(err error, code string) bool {
+	var pgErr interface{ SQLState() string }
+	return errors.As(err, &pgErr) && pgErr.SQLState() == code
+}
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPostgresRepository connects to the database in QUIZ_TEST_POSTGRES_DSN and resets its schema.
+// The integration tests are skipped when the variable is not set.
+func newTestPostgresRepository(t *testing.T) (*PostgresRepository, *sql.DB) {
+	dsn := os.Getenv("QUIZ_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("QUIZ_TEST_POSTGRES_DSN not set; skipping PostgreSQL integration test")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+	require.NoError(t, MigrateDown(ctx, db, len(migrations)), "Rolling back all migrations should not fail")
+	require.NoError(t, MigrateUp(ctx, db), "Applying all migrations should not fail")
+
+	return NewPostgresRepository(db), db
+}
+
+func TestPostgresRepository_Questions(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	question1 := Question{
+		ID:            2,
+		QuizID:        DefaultQuizID,
+		QuestionText:  "What is the largest ocean?",
+		Alternatives:  []string{"Atlantic", "Pacific", "Indian", "Arctic"},
+		CorrectAnswer: 1,
+	}
+	question2 := Question{
+		ID:            1,
+		QuizID:        DefaultQuizID,
+		QuestionText:  "What is the capital of France?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	}
+
+	assert.NoError(t, repo.AddQuestion(ctx, question1))
+	assert.NoError(t, repo.AddQuestion(ctx, question2))
+
+	// Duplicate IDs map to ErrQuestionExists
+	err := repo.AddQuestion(ctx, question1)
+	assert.ErrorIs(t, err, ErrQuestionExists, "Error should be ErrQuestionExists when adding a duplicate question")
+
+	// Questions come back sorted with their alternatives in order
+	questions, err := repo.GetAllQuestions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Question{question2, question1}, questions)
+
+	foundQuestion, err := repo.GetQuestionByID(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, question1, foundQuestion, "The returned question should match the one added")
+
+	// Missing rows map to ErrQuestionNotFound
+	_, err = repo.GetQuestionByID(ctx, 999)
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "Error should be ErrQuestionNotFound for a non-existing question")
+}
+
+func TestPostgresRepository_UpdateAndDeleteQuestion(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	question := Question{
+		ID:            1,
+		QuizID:        DefaultQuizID,
+		QuestionText:  "What is the capital of Frence?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+		TimeLimit:     30 * time.Second,
+	}
+	assert.NoError(t, repo.AddQuestion(ctx, question))
+
+	// Replace the text and shrink the alternatives
+	question.QuestionText = "What is the capital of France?"
+	question.Alternatives = []string{"Paris", "Rome"}
+	question.CorrectAnswer = 0
+	assert.NoError(t, repo.UpdateQuestion(ctx, question))
+
+	foundQuestion, err := repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, question, foundQuestion, "The stored question should reflect the update")
+
+	question.CorrectAnswer = 5
+	assert.ErrorIs(t, repo.UpdateQuestion(ctx, question), ErrInvalidAnswer)
+	assert.ErrorIs(t, repo.UpdateQuestion(ctx, Question{ID: 999}), ErrQuestionNotFound)
+
+	// Delete cascades to the alternatives
+	assert.NoError(t, repo.DeleteQuestion(ctx, 1))
+	assert.ErrorIs(t, repo.DeleteQuestion(ctx, 1), ErrQuestionNotFound)
+
+	_, err = repo.GetQuestionByID(ctx, 1)
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "The deleted question should no longer be found")
+}
+
+func TestPostgresRepository_Scores(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 5}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 8, Elapsed: 1500 * time.Millisecond}))
+
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{5, 8}, scoreValues(scores), "Scores should be returned in insertion order")
+	assert.Equal(t, 1500*time.Millisecond, scores[1].Elapsed, "The elapsed time should be stored with the score")
+	assert.False(t, scores[0].RecordedAt.IsZero(), "Scores should be timestamped")
+
+	// A zero quiz ID means the default quiz, so only the missing user is reported
+	assert.ErrorIs(t, repo.AddScore(ctx, ScoreRecord{UserID: "missing", Score: 1}), ErrUserNotFound)
+}
+
+// scoreValues extracts the raw scores from records.
+func scoreValues(scores []ScoreRecord) []float64 {
+	values := make([]float64, 0, len(scores))
+	for _, score := range scores {
+		values = append(values, score.Score)
+	}
+	return values
+}
+
+func TestPostgresRepository_Attempts(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	attempt := Attempt{ID: "a1", QuestionIDs: []int{1, 2}, Answers: map[int]int{}}
+	assert.NoError(t, repo.CreateAttempt(ctx, attempt))
+	assert.ErrorIs(t, repo.CreateAttempt(ctx, attempt), ErrAttemptExists, "Duplicate attempt IDs should be rejected")
+
+	err := repo.UpdateAttempt(ctx, "a1", func(attempt *Attempt) error {
+		attempt.Answers[1] = 2
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = repo.UpdateAttempt(ctx, "a1", func(attempt *Attempt) error {
+		attempt.Answers[2] = 0
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	stored, err := repo.GetAttempt(ctx, "a1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 2}, stored.Answers, "Only the successful update should be stored")
+
+	_, err = repo.GetAttempt(ctx, "missing")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestPostgresRepository_Quizzes(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	// The migration creates the default quiz
+	quiz, err := repo.GetQuiz(ctx, DefaultQuizID)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultQuiz(), quiz)
+
+	geography := Quiz{ID: 2, Name: "Geography", Description: "Capitals", TimeLimit: time.Minute, ShuffleQuestions: true, ShuffleAlternatives: true,
+		Draw: []DrawRule{{Pool: "easy", Count: 3}, {Pool: "hard", Count: 1}}, Adaptive: true, MaxQuestions: 12, TargetError: 0.25,
+		ReviewPolicy: "after-deadline"}
+	assert.NoError(t, repo.AddQuiz(ctx, geography))
+	assert.ErrorIs(t, repo.AddQuiz(ctx, geography), ErrQuizExists)
+	assert.ErrorIs(t, repo.UpdateQuiz(ctx, Quiz{ID: 99, Name: "Missing"}), ErrQuizNotFound)
+
+	geography.LatePolicy = "auto-finalize"
+	geography.Scoring = "negative"
+	geography.Penalty = 0.5
+	assert.NoError(t, repo.UpdateQuiz(ctx, geography))
+	quizzes, err := repo.GetAllQuizzes(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Quiz{defaultQuiz(), geography}, quizzes)
+
+	// Questions and scores reference their quiz
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"2"}}))
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 2, QuizID: 2, QuestionText: "Capital of France?", Alternatives: []string{"Paris"}, Weight: 2}))
+	assert.ErrorIs(t, repo.AddQuestion(ctx, Question{ID: 3, QuizID: 99, QuestionText: "Orphan?", Alternatives: []string{"Yes"}}), ErrQuizNotFound)
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{QuizID: 2, Score: 3}))
+
+	questions, err := repo.GetQuestionsByQuiz(ctx, 2)
+	assert.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, []string{"Paris"}, questions[0].Alternatives)
+	assert.Equal(t, 2.0, questions[0].Weight)
+
+	// Deleting a quiz cascades to its questions, scores and attempts
+	assert.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: "a1", QuizID: 2}))
+	assert.NoError(t, repo.DeleteQuiz(ctx, 2))
+	assert.ErrorIs(t, repo.DeleteQuiz(ctx, 2), ErrQuizNotFound)
+
+	_, err = repo.GetQuestionByID(ctx, 2)
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+	_, err = repo.GetAttempt(ctx, "a1")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1}, scoreValues(scores))
+}
+
+func TestPostgresRepository_Users(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	user := User{ID: "u1", Name: "Ada", TokenHash: "hash-1", CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	assert.NoError(t, repo.AddUser(ctx, user))
+	assert.ErrorIs(t, repo.AddUser(ctx, user), ErrUserExists)
+	assert.ErrorIs(t, repo.AddUser(ctx, User{ID: "u2", Name: "Bob", TokenHash: "hash-1"}), ErrUserExists, "Token hashes must be unique")
+
+	found, err := repo.GetUserByTokenHash(ctx, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+	assert.True(t, user.CreatedAt.Equal(found.CreatedAt))
+	_, err = repo.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// Anonymous scores store no user; unknown users and quizzes are told apart
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{UserID: "u1", Score: 2, MaxScore: 3}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1}))
+	assert.ErrorIs(t, repo.AddScore(ctx, ScoreRecord{UserID: "missing", Score: 1}), ErrUserNotFound)
+	assert.ErrorIs(t, repo.AddScore(ctx, ScoreRecord{UserID: "u1", QuizID: 99, Score: 1}), ErrQuizNotFound)
+
+	scores, err := repo.GetScoresByUser(ctx, "u1")
+	assert.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, 3, scores[0].MaxScore)
+
+	scores, err = repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	require.Len(t, scores, 2)
+	assert.Empty(t, scores[1].UserID, "Anonymous scores should read back without a user")
+}
+
+func TestPostgresRepository_HonoursContext(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := repo.AddScore(ctx, ScoreRecord{Score: 1})
+	assert.ErrorIs(t, err, context.Canceled, "A cancelled context should abort the query")
+
+	_, err = repo.GetAllQuestions(ctx)
+	assert.ErrorIs(t, err, context.Canceled, "A cancelled context should abort the query")
+}
+
+func TestMigrations_DownAndUp(t *testing.T) {
+	_, db := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+
+	// Roll everything back; the tables should be gone
+	require.NoError(t, MigrateDown(ctx, db, len(migrations)))
+	var exists bool
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT to_regclass('questions') IS NOT NULL").Scan(&exists))
+	assert.False(t, exists, "questions table should be dropped after migrating down")
+
+	// Re-apply; running MigrateUp twice must be a no-op
+	require.NoError(t, MigrateUp(ctx, db))
+	require.NoError(t, MigrateUp(ctx, db))
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT to_regclass('questions') IS NOT NULL").Scan(&exists))
+	assert.True(t, exists, "questions table should exist after migrating up")
+}
+
+func TestPostgresRepository_MultiSelectQuestion(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	question := Question{
+		ID:             1,
+		QuizID:         DefaultQuizID,
+		Type:           QuestionTypeMulti,
+		QuestionText:   "Which of these are primes?",
+		Alternatives:   []string{"2", "4", "5", "9"},
+		CorrectAnswers: []int{0, 2},
+		Grading:        "penalty",
+	}
+	assert.NoError(t, repo.AddQuestion(ctx, question))
+
+	stored, err := repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, question, stored, "The type, answer key and grading should round-trip")
+
+	// Partial credit produces fractional scores
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1.5, MaxScore: 2}))
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1.5}, scoreValues(scores))
+}
+
+func TestPostgresRepository_TypedQuestions(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	ctx := context.Background()
+
+	questions := []Question{
+		{
+			ID:              1,
+			QuizID:          DefaultQuizID,
+			Type:            QuestionTypeShortAnswer,
+			QuestionText:    "What is the chemical symbol for Gold?",
+			AcceptedAnswers: []string{"Au"},
+			FuzzyDistance:   1,
+		},
+		{
+			ID:            2,
+			QuizID:        DefaultQuizID,
+			Type:          QuestionTypeNumeric,
+			QuestionText:  "What is the boiling point of water in Kelvin?",
+			NumericAnswer: 373.15,
+			Tolerance:     0.01,
+			ToleranceMode: ToleranceRelative,
+		},
+		{
+			ID:           3,
+			QuizID:       DefaultQuizID,
+			Type:         QuestionTypeOrdering,
+			QuestionText: "Put these events in chronological order",
+			Alternatives: []string{"Moon landing", "French Revolution", "Same year"},
+			CorrectOrder: []int{1, 0, 2},
+			Grading:      "kendall-tau",
+
+			PinnedAlternatives: []int{2},
+			Explanation:        "1789 came before 1969",
+			Feedback:           []string{"1969", "1789", ""},
+			Hints:              []string{"The Moon came late"},
+			HintPenalty:        0.5,
+		},
+		{
+			ID:             4,
+			QuizID:         DefaultQuizID,
+			Type:           QuestionTypeMatching,
+			QuestionText:   "Match each country to its capital",
+			Prompts:        []string{"France", "Italy"},
+			Alternatives:   []string{"Rome", "Paris"},
+			CorrectMatches: []int{1, 0},
+			Pool:           "easy-geography",
+			Difficulty:     -0.75,
+			Discrimination: 1.25,
+		},
+	}
+	for _, question := range questions {
+		require.NoError(t, repo.AddQuestion(ctx, question))
+	}
+
+	stored, err := repo.GetAllQuestions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, questions, stored, "The answer keys should round-trip")
+
+	// Replacing a question rewrites its answer key
+	questions[0].AcceptedAnswers = []string{"Au", "Aurum"}
+	require.NoError(t, repo.UpdateQuestion(ctx, questions[0]))
+	updated, err := repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, questions[0], updated)
+}
+
+func TestPostgresRepository_ScoreStats(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	testScoreStats(t, repo)
+}
+
+func TestPostgresRepository_ItemStats(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	testItemStats(t, repo)
+}
+
+func TestPostgresRepository_ReviewStates(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	testReviewStates(t, repo)
+}
+
+func TestPostgresRepository_FinishAttempt(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	testFinishAttempt(t, repo)
+}
+
+func TestPostgresRepository_AddResult(t *testing.T) {
+	repo, _ := newTestPostgresRepository(t)
+	testAddResult(t, repo)
+}
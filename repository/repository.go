@@ -3,49 +3,304 @@ package repository
 import (
 	"context"
 	"errors"
+	"math"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Repository defines the methods to access and modify quiz data.
 type Repository interface {
+	GetAllQuizzes(ctx context.Context) ([]Quiz, error)
+	GetQuiz(ctx context.Context, id int) (Quiz, error)
+	AddQuiz(ctx context.Context, quiz Quiz) error
+	UpdateQuiz(ctx context.Context, quiz Quiz) error
+	// DeleteQuiz removes the quiz together with its questions and scores.
+	DeleteQuiz(ctx context.Context, id int) error
+
 	GetAllQuestions(ctx context.Context) ([]Question, error)
+	GetQuestionsByQuiz(ctx context.Context, quizID int) ([]Question, error)
 	GetQuestionByID(ctx context.Context, id int) (Question, error)
 	AddQuestion(ctx context.Context, question Question) error
-	GetAllScores(ctx context.Context) ([]int, error)
-	AddScore(ctx context.Context, score int) error
+	UpdateQuestion(ctx context.Context, question Question) error
+	DeleteQuestion(ctx context.Context, id int) error
+	GetAllScores(ctx context.Context) ([]ScoreRecord, error)
+	GetScoresByQuiz(ctx context.Context, quizID int) ([]ScoreRecord, error)
+	GetScoresByUser(ctx context.Context, userID string) ([]ScoreRecord, error)
+	// GetScoreStats aggregates the scores the filter selects without returning them.
+	GetScoreStats(ctx context.Context, filter ScoreFilter) (ScoreStats, error)
+	// AddAnswers stores the graded answers of one result; they must all belong to an existing quiz.
+	AddAnswers(ctx context.Context, answers []AnswerRecord) error
+	// GetItemStats aggregates the stored answers to a quiz's questions, one entry per question, by question ID.
+	GetItemStats(ctx context.Context, quizID int) ([]ItemStats, error)
+	// AddScore stores a score; a non-empty UserID must reference an existing user.
+	AddScore(ctx context.Context, score ScoreRecord) error
+	// AddResult stores a score together with its graded answers, checked as by AddScore and AddAnswers:
+	// either all of them are stored or none is.
+	AddResult(ctx context.Context, result Result) error
+
+	// AddUser stores a new user; both the ID and the token hash must be unique.
+	AddUser(ctx context.Context, user User) error
+	GetUser(ctx context.Context, id string) (User, error)
+	GetUserByTokenHash(ctx context.Context, tokenHash string) (User, error)
+
+	// CreateAttempt stores a new attempt; the ID must be unique.
+	CreateAttempt(ctx context.Context, attempt Attempt) error
+	GetAttempt(ctx context.Context, id string) (Attempt, error)
+	// UpdateAttempt atomically loads the attempt, applies fn and stores the result.
+	// If fn returns an error nothing is stored. fn must not call back into the repository.
+	UpdateAttempt(ctx context.Context, id string, fn func(attempt *Attempt) error) error
+	// FinishAttempt atomically loads the attempt, applies fn and stores it together with the result fn
+	// returns: either both are stored or neither is. The result is checked as by AddResult.
+	// If fn returns an error nothing is stored. fn must not call back into the repository.
+	FinishAttempt(ctx context.Context, id string, fn func(attempt *Attempt) (Result, error)) error
+
+	// GetReviewStates returns a user's review states, by question ID.
+	GetReviewStates(ctx context.Context, userID string) ([]ReviewState, error)
+	// UpdateReviewState atomically loads the user's review state for a question, applies fn and stores the result.
+	// fn gets a zero state, with the user and question set, the first time; the user must exist.
+	// If fn returns an error nothing is stored. fn must not call back into the repository.
+	UpdateReviewState(ctx context.Context, userID string, questionID int, fn func(state *ReviewState) error) error
 }
 
 var (
+	ErrQuizNotFound     = errors.New("quiz not found")
+	ErrQuizExists       = errors.New("quiz already exists")
+	ErrInvalidQuiz      = errors.New("invalid quiz: name is required")
 	ErrQuestionNotFound = errors.New("question not found")
 	ErrQuestionExists   = errors.New("question already exists")
+	ErrInvalidQuestion  = errors.New("invalid question: question text and alternatives are required")
+	ErrInvalidAnswer    = errors.New("invalid question: correct answer must reference one of the alternatives")
+	ErrAttemptNotFound  = errors.New("attempt not found")
+	ErrAttemptExists    = errors.New("attempt already exists")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserExists       = errors.New("user already exists")
+	ErrInvalidUser      = errors.New("invalid user: ID, name and token are required")
 )
 
 type inMemoryRepository struct {
-	questions map[int]Question // Map to store questions with question ID as key
-	scores    []int            // Slice to store scores
+	mu        sync.RWMutex                   // Guards every field below; Gin serves each request on its own goroutine
+	quizzes   map[int]Quiz                   // Map to store quizzes with quiz ID as key
+	questions map[int]Question               // Map to store questions with question ID as key
+	scores    []ScoreRecord                  // Slice to store scores
+	answers   []AnswerRecord                 // Slice to store graded answers
+	attempts  map[string]Attempt             // Map to store attempts with attempt ID as key
+	users     map[string]User                // Map to store users with user ID as key
+	reviews   map[string]map[int]ReviewState // User ID to question ID to review state
 }
 
 // NewRepository creates a new in-memory repository.
 func NewRepository() Repository {
 	return &inMemoryRepository{
+		quizzes:   map[int]Quiz{DefaultQuizID: defaultQuiz()},
 		questions: make(map[int]Question),
-		scores:    []int{},
+		scores:    []ScoreRecord{},
+		attempts:  make(map[string]Attempt),
+		users:     make(map[string]User),
+		reviews:   make(map[string]map[int]ReviewState),
+	}
+}
+
+// GetAllQuizzes returns all quizzes sorted by ID.
+func (im *inMemoryRepository) GetAllQuizzes(ctx context.Context) ([]Quiz, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		quizzes := make([]Quiz, 0, len(im.quizzes))
+		for _, quiz := range im.quizzes {
+			quizzes = append(quizzes, quiz)
+		}
+
+		sort.Slice(quizzes, func(i, j int) bool {
+			return quizzes[i].ID < quizzes[j].ID
+		})
+
+		return quizzes, nil
+	}
+}
+
+// GetQuiz returns a quiz by its ID.
+func (im *inMemoryRepository) GetQuiz(ctx context.Context, id int) (Quiz, error) {
+	select {
+	case <-ctx.Done():
+		return Quiz{}, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		if quiz, exists := im.quizzes[id]; exists {
+			return quiz, nil
+		}
+		return Quiz{}, ErrQuizNotFound
+	}
+}
+
+// AddQuiz adds a new quiz to the repository.
+func (im *inMemoryRepository) AddQuiz(ctx context.Context, quiz Quiz) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if _, exists := im.quizzes[quiz.ID]; exists {
+			return ErrQuizExists
+		}
+		if err := validateQuiz(quiz); err != nil {
+			return err
+		}
+
+		im.quizzes[quiz.ID] = quiz
+		return nil
+	}
+}
+
+// UpdateQuiz replaces an existing quiz, matched by ID.
+func (im *inMemoryRepository) UpdateQuiz(ctx context.Context, quiz Quiz) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if _, exists := im.quizzes[quiz.ID]; !exists {
+			return ErrQuizNotFound
+		}
+		if err := validateQuiz(quiz); err != nil {
+			return err
+		}
+
+		im.quizzes[quiz.ID] = quiz
+		return nil
+	}
+}
+
+// DeleteQuiz removes a quiz with its questions and scores.
+func (im *inMemoryRepository) DeleteQuiz(ctx context.Context, id int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if _, exists := im.quizzes[id]; !exists {
+			return ErrQuizNotFound
+		}
+
+		delete(im.quizzes, id)
+		for questionID, question := range im.questions {
+			if question.QuizID == id {
+				delete(im.questions, questionID)
+			}
+		}
+
+		scores := im.scores[:0:0]
+		for _, score := range im.scores {
+			if score.QuizID != id {
+				scores = append(scores, score)
+			}
+		}
+		im.scores = scores
+
+		answers := im.answers[:0:0]
+		for _, answer := range im.answers {
+			if answer.QuizID != id {
+				answers = append(answers, answer)
+			}
+		}
+		im.answers = answers
+
+		for attemptID, attempt := range im.attempts {
+			if attempt.QuizID == id {
+				delete(im.attempts, attemptID)
+			}
+		}
+
+		return nil
 	}
 }
 
 // AddQuestion adds a new question to the repository.
 func (im *inMemoryRepository) AddQuestion(ctx context.Context, question Question) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
 	// Check if the question already exists by ID
 	if _, exists := im.questions[question.ID]; exists {
 		return ErrQuestionExists
 	}
 
 	// Validate the question data
-	if question.QuestionText == "" || len(question.Alternatives) == 0 {
-		return errors.New("invalid question: question text and alternatives are required")
+	question.QuizID = normalizeQuizID(question.QuizID)
+	if err := validateQuestion(question); err != nil {
+		return err
+	}
+	if _, exists := im.quizzes[question.QuizID]; !exists {
+		return ErrQuizNotFound
+	}
+
+	// Store a copy so the caller cannot mutate the alternatives behind the lock
+	im.questions[question.ID] = cloneQuestion(question)
+	return nil
+}
+
+// UpdateQuestion replaces an existing question, matched by ID.
+func (im *inMemoryRepository) UpdateQuestion(ctx context.Context, question Question) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if _, exists := im.questions[question.ID]; !exists {
+		return ErrQuestionNotFound
+	}
+
+	question.QuizID = normalizeQuizID(question.QuizID)
+	if err := validateQuestion(question); err != nil {
+		return err
+	}
+	if _, exists := im.quizzes[question.QuizID]; !exists {
+		return ErrQuizNotFound
+	}
+
+	im.questions[question.ID] = cloneQuestion(question)
+	return nil
+}
+
+// DeleteQuestion removes a question by its ID.
+func (im *inMemoryRepository) DeleteQuestion(ctx context.Context, id int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	if _, exists := im.questions[id]; !exists {
+		return ErrQuestionNotFound
 	}
 
-	im.questions[question.ID] = question
+	delete(im.questions, id)
 	return nil
 }
 
@@ -55,19 +310,40 @@ func (im *inMemoryRepository) GetAllQuestions(ctx context.Context) ([]Question,
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		// Convert the map to a slice
-		questionsSlice := make([]Question, 0, len(im.questions))
-		for _, question := range im.questions {
-			questionsSlice = append(questionsSlice, question)
-		}
+		return im.questionsWhere(func(Question) bool { return true }), nil
+	}
+}
 
-		// Sort the slice by question ID
-		sort.Slice(questionsSlice, func(i, j int) bool {
-			return questionsSlice[i].ID < questionsSlice[j].ID
-		})
+// GetQuestionsByQuiz returns the questions of one quiz as a sorted slice.
+func (im *inMemoryRepository) GetQuestionsByQuiz(ctx context.Context, quizID int) ([]Question, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		quizID = normalizeQuizID(quizID)
+		return im.questionsWhere(func(question Question) bool { return question.QuizID == quizID }), nil
+	}
+}
+
+// questionsWhere returns copies of the questions matching keep, sorted by ID.
+func (im *inMemoryRepository) questionsWhere(keep func(Question) bool) []Question {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
 
-		return questionsSlice, nil
+	// Convert the map to a slice
+	questionsSlice := make([]Question, 0, len(im.questions))
+	for _, question := range im.questions {
+		if keep(question) {
+			questionsSlice = append(questionsSlice, cloneQuestion(question))
+		}
 	}
+
+	// Sort the slice by question ID
+	sort.Slice(questionsSlice, func(i, j int) bool {
+		return questionsSlice[i].ID < questionsSlice[j].ID
+	})
+
+	return questionsSlice
 }
 
 // GetQuestionByID returns a question by its ID.
@@ -76,30 +352,626 @@ func (im *inMemoryRepository) GetQuestionByID(ctx context.Context, id int) (Ques
 	case <-ctx.Done():
 		return Question{}, ctx.Err()
 	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
 		if question, exists := im.questions[id]; exists {
-			return question, nil
+			return cloneQuestion(question), nil
 		}
 		return Question{}, ErrQuestionNotFound
 	}
 }
 
 // AddScore adds a user's score to the repository.
-func (im *inMemoryRepository) AddScore(ctx context.Context, score int) error {
+func (im *inMemoryRepository) AddScore(ctx context.Context, score ScoreRecord) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		return im.addScore(score)
+	}
+}
+
+// addScore checks and appends a score; the caller must hold the write lock.
+func (im *inMemoryRepository) addScore(score ScoreRecord) error {
+	score.QuizID = normalizeQuizID(score.QuizID)
+	if _, exists := im.quizzes[score.QuizID]; !exists {
+		return ErrQuizNotFound
+	}
+	if _, exists := im.users[score.UserID]; score.UserID != "" && !exists {
+		return ErrUserNotFound
+	}
+	im.scores = append(im.scores, score)
+	return nil
+}
+
+// AddResult stores a score and copies of its answers under one write lock, once all of them are checked.
+func (im *inMemoryRepository) AddResult(ctx context.Context, result Result) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		im.scores = append(im.scores, score)
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		return im.addResult(result)
+	}
+}
+
+// addResult checks and stores a score with its answers; the caller must hold the write lock.
+func (im *inMemoryRepository) addResult(result Result) error {
+	if err := im.checkAnswers(result.Answers); err != nil {
+		return err
+	}
+	if err := im.addScore(result.Score); err != nil {
+		return err
+	}
+	im.appendAnswers(result.Answers)
+	return nil
+}
+
+// GetAllScores returns a copy of all the stored quiz scores.
+func (im *inMemoryRepository) GetAllScores(ctx context.Context) ([]ScoreRecord, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		scores := make([]ScoreRecord, len(im.scores))
+		copy(scores, im.scores)
+		return scores, nil
+	}
+}
+
+// GetScoresByQuiz returns a copy of the scores recorded for one quiz.
+func (im *inMemoryRepository) GetScoresByQuiz(ctx context.Context, quizID int) ([]ScoreRecord, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		quizID = normalizeQuizID(quizID)
+		return im.scoresWhere(func(score ScoreRecord) bool { return score.QuizID == quizID }), nil
+	}
+}
+
+// GetScoresByUser returns a copy of the scores recorded for one user.
+func (im *inMemoryRepository) GetScoresByUser(ctx context.Context, userID string) ([]ScoreRecord, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		return im.scoresWhere(func(score ScoreRecord) bool { return score.UserID == userID }), nil
+	}
+}
+
+// GetScoreStats aggregates the scores the filter selects in one pass under the read lock.
+func (im *inMemoryRepository) GetScoreStats(ctx context.Context, filter ScoreFilter) (ScoreStats, error) {
+	select {
+	case <-ctx.Done():
+		return ScoreStats{}, ctx.Err()
+	default:
+		builder, err := newScoreStatsBuilder(filter)
+		if err != nil {
+			return ScoreStats{}, err
+		}
+
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		for _, score := range im.scores {
+			builder.add(score)
+		}
+		return builder.stats(), nil
+	}
+}
+
+// AddAnswers stores copies of the graded answers of one result.
+func (im *inMemoryRepository) AddAnswers(ctx context.Context, answers []AnswerRecord) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if err := im.checkAnswers(answers); err != nil {
+			return err
+		}
+		im.appendAnswers(answers)
 		return nil
 	}
 }
 
-// GetAllScores returns all the stored quiz scores.
-func (im *inMemoryRepository) GetAllScores(ctx context.Context) ([]int, error) {
+// checkAnswers reports whether every answer belongs to an existing quiz; the caller must hold the lock.
+func (im *inMemoryRepository) checkAnswers(answers []AnswerRecord) error {
+	for _, answer := range answers {
+		if _, exists := im.quizzes[normalizeQuizID(answer.QuizID)]; !exists {
+			return ErrQuizNotFound
+		}
+	}
+	return nil
+}
+
+// appendAnswers stores copies of checked answers; the caller must hold the write lock.
+func (im *inMemoryRepository) appendAnswers(answers []AnswerRecord) {
+	for _, answer := range answers {
+		answer.QuizID = normalizeQuizID(answer.QuizID)
+		answer.Choices = append([]int(nil), answer.Choices...)
+		im.answers = append(im.answers, answer)
+	}
+}
+
+// GetItemStats aggregates the quiz's answers in one pass under the read lock.
+func (im *inMemoryRepository) GetItemStats(ctx context.Context, quizID int) ([]ItemStats, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		return im.scores, nil
+		items := newItemStatsBuilder(quizID)
+
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		for _, answer := range im.answers {
+			items.add(answer)
+		}
+		return items.stats(), nil
 	}
 }
+
+// scoresWhere returns the scores matching keep in insertion order.
+func (im *inMemoryRepository) scoresWhere(keep func(ScoreRecord) bool) []ScoreRecord {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+
+	scores := []ScoreRecord{}
+	for _, score := range im.scores {
+		if keep(score) {
+			scores = append(scores, score)
+		}
+	}
+	return scores
+}
+
+// AddUser adds a new user to the repository.
+func (im *inMemoryRepository) AddUser(ctx context.Context, user User) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if err := validateUser(user); err != nil {
+			return err
+		}
+		for _, existing := range im.users {
+			if existing.ID == user.ID || existing.TokenHash == user.TokenHash {
+				return ErrUserExists
+			}
+		}
+
+		im.users[user.ID] = user
+		return nil
+	}
+}
+
+// GetUser returns a user by its ID.
+func (im *inMemoryRepository) GetUser(ctx context.Context, id string) (User, error) {
+	select {
+	case <-ctx.Done():
+		return User{}, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		if user, exists := im.users[id]; exists {
+			return user, nil
+		}
+		return User{}, ErrUserNotFound
+	}
+}
+
+// GetUserByTokenHash returns the user whose token hashes to tokenHash.
+func (im *inMemoryRepository) GetUserByTokenHash(ctx context.Context, tokenHash string) (User, error) {
+	select {
+	case <-ctx.Done():
+		return User{}, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		for _, user := range im.users {
+			if user.TokenHash == tokenHash {
+				return user, nil
+			}
+		}
+		return User{}, ErrUserNotFound
+	}
+}
+
+// CreateAttempt stores a new attempt.
+func (im *inMemoryRepository) CreateAttempt(ctx context.Context, attempt Attempt) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if _, exists := im.attempts[attempt.ID]; exists {
+			return ErrAttemptExists
+		}
+
+		im.attempts[attempt.ID] = cloneAttempt(attempt)
+		return nil
+	}
+}
+
+// GetAttempt returns an attempt by its ID.
+func (im *inMemoryRepository) GetAttempt(ctx context.Context, id string) (Attempt, error) {
+	select {
+	case <-ctx.Done():
+		return Attempt{}, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		if attempt, exists := im.attempts[id]; exists {
+			return cloneAttempt(attempt), nil
+		}
+		return Attempt{}, ErrAttemptNotFound
+	}
+}
+
+// UpdateAttempt applies fn to a copy of the attempt under the write lock and stores it if fn succeeds.
+func (im *inMemoryRepository) UpdateAttempt(ctx context.Context, id string, fn func(attempt *Attempt) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		stored, exists := im.attempts[id]
+		if !exists {
+			return ErrAttemptNotFound
+		}
+
+		attempt := cloneAttempt(stored)
+		if err := fn(&attempt); err != nil {
+			return err
+		}
+
+		attempt.ID = id // the key cannot change
+		im.attempts[id] = attempt
+		return nil
+	}
+}
+
+// FinishAttempt applies fn to a copy of the attempt under the write lock and stores it with its result if both are valid.
+func (im *inMemoryRepository) FinishAttempt(ctx context.Context, id string, fn func(attempt *Attempt) (Result, error)) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		stored, exists := im.attempts[id]
+		if !exists {
+			return ErrAttemptNotFound
+		}
+
+		attempt := cloneAttempt(stored)
+		result, err := fn(&attempt)
+		if err != nil {
+			return err
+		}
+		if err := im.addResult(result); err != nil {
+			return err
+		}
+
+		attempt.ID = id // the key cannot change
+		im.attempts[id] = attempt
+		return nil
+	}
+}
+
+// GetReviewStates returns a copy of a user's review states, sorted by question ID.
+func (im *inMemoryRepository) GetReviewStates(ctx context.Context, userID string) ([]ReviewState, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		im.mu.RLock()
+		defer im.mu.RUnlock()
+
+		states := make([]ReviewState, 0, len(im.reviews[userID]))
+		for _, state := range im.reviews[userID] {
+			states = append(states, state)
+		}
+		sort.Slice(states, func(i, j int) bool { return states[i].QuestionID < states[j].QuestionID })
+		return states, nil
+	}
+}
+
+// UpdateReviewState applies fn to a copy of the review state under the write lock and stores it if fn succeeds.
+func (im *inMemoryRepository) UpdateReviewState(ctx context.Context, userID string, questionID int, fn func(state *ReviewState) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		im.mu.Lock()
+		defer im.mu.Unlock()
+
+		if _, exists := im.users[userID]; !exists {
+			return ErrUserNotFound
+		}
+
+		state, exists := im.reviews[userID][questionID]
+		if !exists {
+			state = ReviewState{UserID: userID, QuestionID: questionID}
+		}
+		if err := fn(&state); err != nil {
+			return err
+		}
+
+		state.UserID, state.QuestionID = userID, questionID // the key cannot change
+		if im.reviews[userID] == nil {
+			im.reviews[userID] = map[int]ReviewState{}
+		}
+		im.reviews[userID][questionID] = state
+		return nil
+	}
+}
+
+// defaultQuiz is the quiz every repository starts with.
+func defaultQuiz() Quiz {
+	return Quiz{ID: DefaultQuizID, Name: "Default quiz"}
+}
+
+// normalizeQuizID maps the zero value to DefaultQuizID so records created before quizzes existed keep working.
+func normalizeQuizID(quizID int) int {
+	if quizID == 0 {
+		return DefaultQuizID
+	}
+	return quizID
+}
+
+// validateQuiz checks the fields every repository implementation requires.
+func validateQuiz(quiz Quiz) error {
+	if quiz.Name == "" || quiz.TimeLimit < 0 || !nonNegative(quiz.Penalty) || !nonNegative(quiz.TimeBonus) ||
+		quiz.MaxQuestions < 0 || !nonNegative(quiz.TargetError) {
+		return ErrInvalidQuiz
+	}
+	return validateDraw(quiz.Draw)
+}
+
+// validateDraw checks that every draw rule names a pool, once, and asks for at least one question.
+// Whether the pools hold enough questions is only known when an attempt draws from them.
+func validateDraw(rules []DrawRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.Pool == "" || rule.Count <= 0 || seen[rule.Pool] {
+			return ErrInvalidQuiz
+		}
+		seen[rule.Pool] = true
+	}
+	return nil
+}
+
+// validateUser checks the fields every repository implementation requires.
+func validateUser(user User) error {
+	if user.ID == "" || user.Name == "" || user.TokenHash == "" {
+		return ErrInvalidUser
+	}
+	return nil
+}
+
+// validateQuestion checks the fields every repository implementation requires.
+func validateQuestion(question Question) error {
+	if question.QuestionText == "" || question.TimeLimit < 0 || !nonNegative(question.Weight) ||
+		!nonNegative(question.Discrimination) || math.IsNaN(question.Difficulty) || math.IsInf(question.Difficulty, 0) {
+		return ErrInvalidQuestion
+	}
+	if err := validatePinned(question.PinnedAlternatives, len(question.Alternatives)); err != nil {
+		return err
+	}
+	if len(question.Feedback) > 0 && len(question.Feedback) != len(question.Alternatives) {
+		return ErrInvalidQuestion
+	}
+	if err := validateHints(question.Hints, question.HintPenalty); err != nil {
+		return err
+	}
+
+	switch question.Type {
+	case "", QuestionTypeSingle:
+		if len(question.Alternatives) == 0 {
+			return ErrInvalidQuestion
+		}
+		if question.CorrectAnswer < 0 || question.CorrectAnswer >= len(question.Alternatives) {
+			return ErrInvalidAnswer
+		}
+	case QuestionTypeMulti:
+		if len(question.Alternatives) == 0 {
+			return ErrInvalidQuestion
+		}
+		return validateCorrectAnswers(question.CorrectAnswers, len(question.Alternatives))
+	case QuestionTypeShortAnswer:
+		if question.FuzzyDistance < 0 {
+			return ErrInvalidQuestion
+		}
+		return validateAcceptedAnswers(question.AcceptedAnswers)
+	case QuestionTypeNumeric:
+		if !nonNegative(question.Tolerance) {
+			return ErrInvalidQuestion
+		}
+		if question.ToleranceMode != "" && question.ToleranceMode != ToleranceAbsolute && question.ToleranceMode != ToleranceRelative {
+			return ErrInvalidQuestion
+		}
+		if math.IsNaN(question.NumericAnswer) || math.IsInf(question.NumericAnswer, 0) {
+			return ErrInvalidAnswer
+		}
+	case QuestionTypeOrdering:
+		if len(question.Alternatives) == 0 {
+			return ErrInvalidQuestion
+		}
+		if len(question.CorrectOrder) != len(question.Alternatives) {
+			return ErrInvalidAnswer
+		}
+		return validateCorrectAnswers(question.CorrectOrder, len(question.Alternatives))
+	case QuestionTypeMatching:
+		if len(question.Prompts) == 0 || len(question.Alternatives) == 0 {
+			return ErrInvalidQuestion
+		}
+		if len(question.CorrectMatches) != len(question.Prompts) {
+			return ErrInvalidAnswer
+		}
+		for _, index := range question.CorrectMatches {
+			if index < 0 || index >= len(question.Alternatives) {
+				return ErrInvalidAnswer
+			}
+		}
+	default:
+		return ErrInvalidQuestion
+	}
+	return nil
+}
+
+// validatePinned checks that pinned alternatives exist and are each pinned once.
+func validatePinned(pinned []int, alternatives int) error {
+	seen := make(map[int]bool, len(pinned))
+	for _, index := range pinned {
+		if index < 0 || index >= alternatives || seen[index] {
+			return ErrInvalidQuestion
+		}
+		seen[index] = true
+	}
+	return nil
+}
+
+// validateHints checks that no hint is blank and that a hint takes at most the whole question's points.
+func validateHints(hints []string, penalty float64) error {
+	if !nonNegative(penalty) || penalty > 1 {
+		return ErrInvalidQuestion
+	}
+	for _, hint := range hints {
+		if strings.TrimSpace(hint) == "" {
+			return ErrInvalidQuestion
+		}
+	}
+	return nil
+}
+
+// nonNegative reports whether v is a finite number of at least zero.
+func nonNegative(v float64) bool {
+	return v >= 0 && !math.IsInf(v, 1)
+}
+
+// validateAcceptedAnswers checks that a short-answer question accepts at least one answer and none is blank.
+func validateAcceptedAnswers(accepted []string) error {
+	if len(accepted) == 0 {
+		return ErrInvalidAnswer
+	}
+	for _, answer := range accepted {
+		if strings.TrimSpace(answer) == "" {
+			return ErrInvalidAnswer
+		}
+	}
+	return nil
+}
+
+// validateCorrectAnswers checks that an answer key names at least one alternative, each in range and once.
+func validateCorrectAnswers(correct []int, alternatives int) error {
+	if len(correct) == 0 {
+		return ErrInvalidAnswer
+	}
+
+	seen := make(map[int]bool, len(correct))
+	for _, index := range correct {
+		if index < 0 || index >= alternatives || seen[index] {
+			return ErrInvalidAnswer
+		}
+		seen[index] = true
+	}
+	return nil
+}
+
+// cloneQuestion returns a copy of the question that shares no slices with the original.
+func cloneQuestion(question Question) Question {
+	question.Alternatives = append([]string(nil), question.Alternatives...)
+	if question.CorrectAnswers != nil {
+		question.CorrectAnswers = append([]int(nil), question.CorrectAnswers...)
+	}
+	if question.AcceptedAnswers != nil {
+		question.AcceptedAnswers = append([]string(nil), question.AcceptedAnswers...)
+	}
+	if question.CorrectOrder != nil {
+		question.CorrectOrder = append([]int(nil), question.CorrectOrder...)
+	}
+	if question.Prompts != nil {
+		question.Prompts = append([]string(nil), question.Prompts...)
+	}
+	if question.CorrectMatches != nil {
+		question.CorrectMatches = append([]int(nil), question.CorrectMatches...)
+	}
+	if question.PinnedAlternatives != nil {
+		question.PinnedAlternatives = append([]int(nil), question.PinnedAlternatives...)
+	}
+	if question.Feedback != nil {
+		question.Feedback = append([]string(nil), question.Feedback...)
+	}
+	if question.Hints != nil {
+		question.Hints = append([]string(nil), question.Hints...)
+	}
+	return question
+}
+
+// cloneAttempt returns a copy of the attempt that shares no slices or maps with the original.
+func cloneAttempt(attempt Attempt) Attempt {
+	attempt.QuestionIDs = append([]int(nil), attempt.QuestionIDs...)
+	attempt.Results = append([]AttemptResult(nil), attempt.Results...)
+
+	servedAt := make(map[int]time.Time, len(attempt.ServedAt))
+	for questionID, at := range attempt.ServedAt {
+		servedAt[questionID] = at
+	}
+	attempt.ServedAt = servedAt
+
+	answers := make(map[int]int, len(attempt.Answers))
+	for questionID, choice := range attempt.Answers {
+		answers[questionID] = choice
+	}
+	attempt.Answers = answers
+
+	if attempt.Hints != nil {
+		hints := make(map[int]int, len(attempt.Hints))
+		for questionID, count := range attempt.Hints {
+			hints[questionID] = count
+		}
+		attempt.Hints = hints
+	}
+
+	if attempt.Responses != nil {
+		responses := make(map[int]Response, len(attempt.Responses))
+		for questionID, response := range attempt.Responses {
+			response.Choices = append([]int(nil), response.Choices...)
+			response.Order = append([]int(nil), response.Order...)
+			response.Matches = append([]int(nil), response.Matches...)
+			if response.Number != nil {
+				number := *response.Number
+				response.Number = &number
+			}
+			responses[questionID] = response
+		}
+		attempt.Responses = responses
+	}
+
+	return attempt
+}
@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInMemoryRepository_AddQuestion(t *testing.T) {
@@ -61,6 +67,7 @@ func TestInMemoryRepository_GetQuestionByID(t *testing.T) {
 
 	question := Question{
 		ID:            1,
+		QuizID:        DefaultQuizID,
 		QuestionText:  "What is the capital of France?",
 		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
 		CorrectAnswer: 2,
@@ -83,9 +90,786 @@ func TestInMemoryRepository_AddScore(t *testing.T) {
 	repo := NewRepository()
 
 	// Add scores
-	err := repo.AddScore(context.Background(), 5)
+	err := repo.AddScore(context.Background(), ScoreRecord{Score: 5})
 	assert.NoError(t, err, "Error should be nil when adding a score")
 
-	err = repo.AddScore(context.Background(), 8)
+	err = repo.AddScore(context.Background(), ScoreRecord{Score: 8})
 	assert.NoError(t, err, "Error should be nil when adding another score")
 }
+
+func TestInMemoryRepository_GetAllScoresReturnsCopy(t *testing.T) {
+	repo := NewRepository()
+
+	err := repo.AddScore(context.Background(), ScoreRecord{Score: 5})
+	assert.NoError(t, err)
+
+	// Mutate the returned slice
+	scores, err := repo.GetAllScores(context.Background())
+	assert.NoError(t, err)
+	scores[0].Score = 100
+
+	// The stored scores should be unaffected
+	scores, err = repo.GetAllScores(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoreRecord{{QuizID: DefaultQuizID, Score: 5}}, scores, "Mutating the returned slice should not change the stored scores")
+}
+
+func TestInMemoryRepository_GetQuestionReturnsCopy(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	question := Question{
+		ID:                 1,
+		QuizID:             DefaultQuizID,
+		QuestionText:       "Which are prime?",
+		Alternatives:       []string{"2", "4", "None"},
+		PinnedAlternatives: []int{2},
+		Feedback:           []string{"Right", "Even", ""},
+	}
+	require.NoError(t, repo.AddQuestion(ctx, question))
+
+	// Mutate the returned question's slices
+	found, err := repo.GetQuestionByID(ctx, 1)
+	require.NoError(t, err)
+	found.PinnedAlternatives[0] = 0
+	found.Feedback[0] = "Wrong"
+
+	found, err = repo.GetQuestionByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, question, found, "Mutating the returned question should not change the stored one")
+}
+
+func TestInMemoryRepository_ConcurrentAccess(t *testing.T) {
+	// Every Repository method runs concurrently; run with -race to detect unsynchronised access
+	repo := NewRepository()
+	ctx := context.Background()
+
+	const workers = 50
+	require.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: "shared", QuestionIDs: []int{1}, ExpiresAt: time.Now().Add(time.Hour)}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			question := Question{
+				ID:            id,
+				QuestionText:  "What is the capital of France?",
+				Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+				CorrectAnswer: 2,
+			}
+			assert.NoError(t, repo.AddQuestion(ctx, question))
+			assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: float64(id)}))
+
+			_, err := repo.GetAllQuestions(ctx)
+			assert.NoError(t, err)
+			_, err = repo.GetQuestionByID(ctx, id)
+			assert.NoError(t, err)
+			_, err = repo.GetAllScores(ctx)
+			assert.NoError(t, err)
+
+			question.QuestionText = "What is the capital of Spain?"
+			question.CorrectAnswer = 1
+			question.Hints = []string{"It is in Europe"}
+			assert.NoError(t, repo.UpdateQuestion(ctx, question))
+
+			// Hint reveals on a shared attempt while others read it
+			assert.NoError(t, repo.UpdateAttempt(ctx, "shared", func(attempt *Attempt) error {
+				if attempt.Hints == nil {
+					attempt.Hints = map[int]int{}
+				}
+				attempt.Hints[1]++
+				return nil
+			}))
+			attempt, err := repo.GetAttempt(ctx, "shared")
+			assert.NoError(t, err)
+			_ = attempt.Hints[1]
+
+			// Every other worker deletes the question it added
+			if id%2 == 0 {
+				assert.NoError(t, repo.DeleteQuestion(ctx, id))
+			}
+
+			// Quizzes, with every other one deleted again
+			quiz := Quiz{ID: 100 + id, Name: "Quiz"}
+			assert.NoError(t, repo.AddQuiz(ctx, quiz))
+			quiz.Name = "Renamed quiz"
+			assert.NoError(t, repo.UpdateQuiz(ctx, quiz))
+			_, err = repo.GetQuiz(ctx, quiz.ID)
+			assert.NoError(t, err)
+			_, err = repo.GetAllQuizzes(ctx)
+			assert.NoError(t, err)
+			_, err = repo.GetQuestionsByQuiz(ctx, DefaultQuizID)
+			assert.NoError(t, err)
+			assert.NoError(t, repo.AddAnswers(ctx, []AnswerRecord{{QuizID: quiz.ID, QuestionID: id, Choices: []int{1}}}))
+			if id%2 == 1 {
+				assert.NoError(t, repo.DeleteQuiz(ctx, quiz.ID))
+			}
+
+			// Players, their results and their practice schedules
+			user := User{ID: fmt.Sprintf("user-%d", id), Name: "Player", TokenHash: fmt.Sprintf("hash-%d", id)}
+			assert.NoError(t, repo.AddUser(ctx, user))
+			_, err = repo.GetUser(ctx, user.ID)
+			assert.NoError(t, err)
+			_, err = repo.GetUserByTokenHash(ctx, user.TokenHash)
+			assert.NoError(t, err)
+
+			result := Result{
+				Score:   ScoreRecord{UserID: user.ID, Score: 1, MaxScore: 2},
+				Answers: []AnswerRecord{{QuestionID: id, Correct: true, Points: 1, Choices: []int{2}}},
+			}
+			assert.NoError(t, repo.AddResult(ctx, result))
+			attemptID := fmt.Sprintf("attempt-%d", id)
+			assert.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: attemptID, UserID: user.ID, QuestionIDs: []int{id}, ExpiresAt: time.Now().Add(time.Hour)}))
+			assert.NoError(t, repo.FinishAttempt(ctx, attemptID, func(attempt *Attempt) (Result, error) {
+				attempt.FinishedAt = time.Now()
+				return result, nil
+			}))
+
+			_, err = repo.GetScoresByQuiz(ctx, DefaultQuizID)
+			assert.NoError(t, err)
+			_, err = repo.GetScoresByUser(ctx, user.ID)
+			assert.NoError(t, err)
+			_, err = repo.GetScoreStats(ctx, ScoreFilter{Buckets: 10})
+			assert.NoError(t, err)
+			_, err = repo.GetItemStats(ctx, DefaultQuizID)
+			assert.NoError(t, err)
+
+			assert.NoError(t, repo.UpdateReviewState(ctx, user.ID, id, func(state *ReviewState) error {
+				state.Reviews++
+				return nil
+			}))
+			_, err = repo.GetReviewStates(ctx, user.ID)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	questions, err := repo.GetAllQuestions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, questions, workers/2, "Only the questions that were not deleted should remain")
+
+	quizzes, err := repo.GetAllQuizzes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, quizzes, 1+workers/2, "Only the quizzes that were not deleted should remain")
+
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, scores, 3*workers, "Every concurrently added score should be stored")
+
+	items, err := repo.GetItemStats(ctx, DefaultQuizID)
+	assert.NoError(t, err)
+	assert.Len(t, items, workers, "Every result's answers should be stored")
+
+	for i := 0; i < workers; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		scores, err := repo.GetScoresByUser(ctx, userID)
+		assert.NoError(t, err)
+		assert.Len(t, scores, 2)
+		states, err := repo.GetReviewStates(ctx, userID)
+		assert.NoError(t, err)
+		assert.Len(t, states, 1)
+	}
+
+	attempt, err := repo.GetAttempt(ctx, "shared")
+	assert.NoError(t, err)
+	assert.Equal(t, workers, attempt.Hints[1], "Every concurrent hint reveal should be counted")
+
+	// A failed update leaves the stored reveals alone
+	assert.Error(t, repo.UpdateAttempt(ctx, "shared", func(attempt *Attempt) error {
+		attempt.Hints[1]++
+		return ErrAttemptExists
+	}))
+	attempt, err = repo.GetAttempt(ctx, "shared")
+	assert.NoError(t, err)
+	assert.Equal(t, workers, attempt.Hints[1])
+}
+
+func TestInMemoryRepository_UpdateQuestion(t *testing.T) {
+	repo := NewRepository()
+
+	question := Question{
+		ID:            1,
+		QuizID:        DefaultQuizID,
+		QuestionText:  "What is the capital of Frence?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	}
+	assert.NoError(t, repo.AddQuestion(context.Background(), question))
+
+	// Fix the typo
+	question.QuestionText = "What is the capital of France?"
+	err := repo.UpdateQuestion(context.Background(), question)
+	assert.NoError(t, err, "Error should be nil when updating an existing question")
+
+	foundQuestion, err := repo.GetQuestionByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, question, foundQuestion, "The stored question should reflect the update")
+
+	// A correct answer outside the alternatives is rejected
+	question.Alternatives = []string{"Berlin", "Paris"}
+	err = repo.UpdateQuestion(context.Background(), question)
+	assert.ErrorIs(t, err, ErrInvalidAnswer, "Error should be ErrInvalidAnswer when the correct answer is out of range")
+
+	// Updating a non-existing question fails
+	err = repo.UpdateQuestion(context.Background(), Question{ID: 999, QuestionText: "?", Alternatives: []string{"a"}})
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "Error should be ErrQuestionNotFound for a non-existing question")
+}
+
+func TestInMemoryRepository_DeleteQuestion(t *testing.T) {
+	repo := NewRepository()
+
+	question := Question{
+		ID:            1,
+		QuestionText:  "What is the capital of France?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	}
+	assert.NoError(t, repo.AddQuestion(context.Background(), question))
+
+	err := repo.DeleteQuestion(context.Background(), 1)
+	assert.NoError(t, err, "Error should be nil when deleting an existing question")
+
+	_, err = repo.GetQuestionByID(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "The deleted question should no longer be found")
+
+	err = repo.DeleteQuestion(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrQuestionNotFound, "Deleting twice should return ErrQuestionNotFound")
+}
+
+func TestInMemoryRepository_Attempts(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	attempt := Attempt{ID: "a1", QuestionIDs: []int{1, 2}, Answers: map[int]int{}}
+	assert.NoError(t, repo.CreateAttempt(ctx, attempt))
+	assert.ErrorIs(t, repo.CreateAttempt(ctx, attempt), ErrAttemptExists, "Duplicate attempt IDs should be rejected")
+
+	// Update the attempt
+	err := repo.UpdateAttempt(ctx, "a1", func(attempt *Attempt) error {
+		attempt.Answers[1] = 2
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// A failing update leaves the attempt untouched
+	err = repo.UpdateAttempt(ctx, "a1", func(attempt *Attempt) error {
+		attempt.Answers[2] = 0
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	stored, err := repo.GetAttempt(ctx, "a1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 2}, stored.Answers, "Only the successful update should be stored")
+
+	_, err = repo.GetAttempt(ctx, "missing")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+	assert.ErrorIs(t, repo.UpdateAttempt(ctx, "missing", func(*Attempt) error { return nil }), ErrAttemptNotFound)
+}
+
+func TestInMemoryRepository_Quizzes(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	// The default quiz always exists
+	quiz, err := repo.GetQuiz(ctx, DefaultQuizID)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultQuizID, quiz.ID)
+
+	geography := Quiz{ID: 2, Name: "Geography", TimeLimit: time.Minute}
+	assert.NoError(t, repo.AddQuiz(ctx, geography))
+	assert.ErrorIs(t, repo.AddQuiz(ctx, geography), ErrQuizExists)
+	assert.ErrorIs(t, repo.AddQuiz(ctx, Quiz{ID: 3}), ErrInvalidQuiz, "A quiz needs a name")
+	assert.ErrorIs(t, repo.AddQuiz(ctx, Quiz{ID: 3, Name: "Harsh", Scoring: "negative", Penalty: -1}), ErrInvalidQuiz, "Penalties cannot be negative")
+	for _, draw := range [][]DrawRule{{{Pool: "", Count: 1}}, {{Pool: "easy", Count: 0}}, {{Pool: "easy", Count: 1}, {Pool: "easy", Count: 2}}} {
+		assert.ErrorIs(t, repo.AddQuiz(ctx, Quiz{ID: 3, Name: "Pooled", Draw: draw}), ErrInvalidQuiz, "Draw %v should be rejected", draw)
+	}
+	assert.ErrorIs(t, repo.AddQuiz(ctx, Quiz{ID: 3, Name: "Adaptive", Adaptive: true, MaxQuestions: -1}), ErrInvalidQuiz)
+	assert.ErrorIs(t, repo.AddQuiz(ctx, Quiz{ID: 3, Name: "Adaptive", Adaptive: true, TargetError: -0.1}), ErrInvalidQuiz)
+	assert.ErrorIs(t, repo.AddQuestion(ctx, Question{ID: 4, QuestionText: "Sharp?", Alternatives: []string{"Yes"}, Discrimination: -1}), ErrInvalidQuestion,
+		"Discrimination cannot be negative")
+	assert.ErrorIs(t, repo.AddQuestion(ctx, Question{ID: 4, QuestionText: "Heavy?", Alternatives: []string{"Yes"}, Weight: -2}), ErrInvalidQuestion, "Weights cannot be negative")
+	assert.ErrorIs(t, repo.UpdateQuiz(ctx, Quiz{ID: 99, Name: "Missing"}), ErrQuizNotFound)
+
+	// Questions and scores are scoped to their quiz
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"2"}}))
+	assert.NoError(t, repo.AddQuestion(ctx, Question{ID: 2, QuizID: 2, QuestionText: "Capital of France?", Alternatives: []string{"Paris"}}))
+	assert.ErrorIs(t, repo.AddQuestion(ctx, Question{ID: 3, QuizID: 99, QuestionText: "Orphan?", Alternatives: []string{"Yes"}}), ErrQuizNotFound)
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{QuizID: 2, Score: 3}))
+
+	questions, err := repo.GetQuestionsByQuiz(ctx, 2)
+	assert.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, 2, questions[0].ID)
+
+	scores, err := repo.GetScoresByQuiz(ctx, DefaultQuizID)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1}, scoreValues(scores), "Only scores of the default quiz should be returned")
+
+	// Deleting a quiz removes its questions, scores and attempts
+	assert.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: "a1", QuizID: 2}))
+	assert.NoError(t, repo.DeleteQuiz(ctx, 2))
+	assert.ErrorIs(t, repo.DeleteQuiz(ctx, 2), ErrQuizNotFound)
+
+	_, err = repo.GetQuestionByID(ctx, 2)
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+	_, err = repo.GetAttempt(ctx, "a1")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+	scores, err = repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1}, scoreValues(scores))
+}
+
+func TestInMemoryRepository_Users(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	user := User{ID: "u1", Name: "Ada", TokenHash: "hash-1"}
+	assert.NoError(t, repo.AddUser(ctx, user))
+	assert.ErrorIs(t, repo.AddUser(ctx, user), ErrUserExists)
+	assert.ErrorIs(t, repo.AddUser(ctx, User{ID: "u2", Name: "Bob", TokenHash: "hash-1"}), ErrUserExists, "Token hashes must be unique")
+	assert.ErrorIs(t, repo.AddUser(ctx, User{ID: "u3"}), ErrInvalidUser)
+
+	found, err := repo.GetUserByTokenHash(ctx, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, found)
+	_, err = repo.GetUser(ctx, "missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// Scores are queryable by user; unknown users are rejected
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{UserID: "u1", Score: 2, MaxScore: 3}))
+	assert.NoError(t, repo.AddScore(ctx, ScoreRecord{Score: 1}))
+	assert.ErrorIs(t, repo.AddScore(ctx, ScoreRecord{UserID: "missing", Score: 1}), ErrUserNotFound)
+
+	scores, err := repo.GetScoresByUser(ctx, "u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoreRecord{{UserID: "u1", QuizID: DefaultQuizID, Score: 2, MaxScore: 3}}, scores)
+}
+
+func TestInMemoryRepository_MultiSelectQuestion(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	question := Question{
+		ID:             1,
+		Type:           QuestionTypeMulti,
+		QuestionText:   "Which of these are primes?",
+		Alternatives:   []string{"2", "4", "5", "9"},
+		CorrectAnswers: []int{0, 2},
+		Grading:        "partial",
+	}
+	assert.NoError(t, repo.AddQuestion(ctx, question))
+
+	stored, err := repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, stored.CorrectAnswers)
+
+	// The stored answer key is a copy
+	stored.CorrectAnswers[0] = 3
+	stored, err = repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, stored.CorrectAnswers)
+
+	// The answer key must name at least one alternative, each in range and once
+	for _, correct := range [][]int{nil, {4}, {-1}, {0, 0}} {
+		invalid := question
+		invalid.ID = 2
+		invalid.CorrectAnswers = correct
+		assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidAnswer, "Answer key %v should be rejected", correct)
+	}
+
+	unknown := question
+	unknown.ID = 3
+	unknown.Type = "essay"
+	assert.ErrorIs(t, repo.AddQuestion(ctx, unknown), ErrInvalidQuestion)
+}
+
+func TestInMemoryRepository_TypedQuestions(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	shortAnswer := Question{
+		ID:              1,
+		Type:            QuestionTypeShortAnswer,
+		QuestionText:    "What is the chemical symbol for Gold?",
+		AcceptedAnswers: []string{"Au"},
+		FuzzyDistance:   1,
+	}
+	numeric := Question{
+		ID:            2,
+		Type:          QuestionTypeNumeric,
+		QuestionText:  "What is the boiling point of water in Kelvin?",
+		NumericAnswer: 373.15,
+		Tolerance:     0.01,
+		ToleranceMode: ToleranceRelative,
+	}
+	assert.NoError(t, repo.AddQuestion(ctx, shortAnswer), "Typed questions need no alternatives")
+	assert.NoError(t, repo.AddQuestion(ctx, numeric))
+
+	stored, err := repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Au"}, stored.AcceptedAnswers)
+	stored.AcceptedAnswers[0] = "Ag"
+	stored, err = repo.GetQuestionByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Au"}, stored.AcceptedAnswers, "The stored accepted answers are a copy")
+
+	// Short answers need at least one non-blank accepted answer
+	for _, accepted := range [][]string{nil, {" "}} {
+		invalid := shortAnswer
+		invalid.ID = 3
+		invalid.AcceptedAnswers = accepted
+		assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidAnswer, "Accepted answers %q should be rejected", accepted)
+	}
+	invalid := shortAnswer
+	invalid.ID = 3
+	invalid.FuzzyDistance = -1
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+
+	// Tolerances cannot be negative and modes must be known
+	invalid = numeric
+	invalid.ID = 4
+	invalid.Tolerance = -1
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+	invalid.Tolerance = 0
+	invalid.ToleranceMode = "percent"
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+}
+
+func TestInMemoryRepository_OrderingAndMatchingQuestions(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	ordering := Question{
+		ID:           1,
+		QuizID:       DefaultQuizID,
+		Type:         QuestionTypeOrdering,
+		QuestionText: "Put these events in chronological order",
+		Alternatives: []string{"Moon landing", "French Revolution"},
+		CorrectOrder: []int{1, 0},
+	}
+	matching := Question{
+		ID:             2,
+		QuizID:         DefaultQuizID,
+		Type:           QuestionTypeMatching,
+		QuestionText:   "Match each country to its capital",
+		Prompts:        []string{"France", "Italy"},
+		Alternatives:   []string{"Rome", "Paris", "Lisbon"},
+		CorrectMatches: []int{1, 0},
+	}
+	assert.NoError(t, repo.AddQuestion(ctx, ordering))
+	assert.NoError(t, repo.AddQuestion(ctx, matching))
+
+	stored, err := repo.GetQuestionByID(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, matching, stored)
+
+	// The correct order must name every alternative exactly once
+	for _, order := range [][]int{{0}, {0, 0}, {0, 2}} {
+		invalid := ordering
+		invalid.ID = 3
+		invalid.CorrectOrder = order
+		assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidAnswer, "Order %v should be rejected", order)
+	}
+
+	// Every prompt needs a match among the alternatives
+	for _, matches := range [][]int{{1}, {1, 3}} {
+		invalid := matching
+		invalid.ID = 3
+		invalid.CorrectMatches = matches
+		assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidAnswer, "Matches %v should be rejected", matches)
+	}
+	invalid := matching
+	invalid.ID = 3
+	invalid.Prompts = nil
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+
+	// Pinned alternatives must exist and be pinned once
+	for _, pinned := range [][]int{{2}, {-1}, {0, 0}} {
+		invalid := ordering
+		invalid.ID = 3
+		invalid.PinnedAlternatives = pinned
+		assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion, "Pins %v should be rejected", pinned)
+	}
+
+	// Feedback, when given, covers every alternative
+	invalid = ordering
+	invalid.ID = 3
+	invalid.Feedback = []string{"Too early"}
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+	invalid.Feedback = []string{"Too early", ""}
+	assert.NoError(t, repo.AddQuestion(ctx, invalid))
+
+	// Hints must not be blank, and each may take at most the whole point
+	invalid = ordering
+	invalid.ID = 4
+	invalid.Hints = []string{" "}
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+	invalid.Hints = []string{"The revolution came first"}
+	invalid.HintPenalty = 1.5
+	assert.ErrorIs(t, repo.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+	invalid.HintPenalty = 0.5
+	assert.NoError(t, repo.AddQuestion(ctx, invalid))
+}
+
+func TestInMemoryRepository_ScoreStats(t *testing.T) {
+	testScoreStats(t, NewRepository())
+}
+
+// testScoreStats checks GetScoreStats against a fixed set of scores; every implementation must agree.
+func testScoreStats(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.AddQuiz(ctx, Quiz{ID: 2, Name: "Other"}))
+	for _, score := range []ScoreRecord{
+		{Score: 2, MaxScore: 4, RecordedAt: base.Add(-2 * time.Hour)},
+		{Score: 8, MaxScore: 8, RecordedAt: base.Add(-time.Hour)}, // Pools make maxima differ
+		{Score: 1, MaxScore: 4, RecordedAt: base},
+		{Score: 3, RecordedAt: base.Add(time.Hour)}, // No maximum: left out
+		{QuizID: 2, Score: 1, MaxScore: 3, RecordedAt: base.Add(-time.Hour)},
+		{QuizID: 2, Score: 10, MaxScore: 10, RecordedAt: base},
+	} {
+		require.NoError(t, repo.AddScore(ctx, score))
+	}
+
+	// Everything is a percentage of each score's own maximum
+	stats, err := repo.GetScoreStats(ctx, ScoreFilter{Buckets: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Count)
+	assert.InDelta(t, 175.0/3, stats.Mean, 1e-9)
+	assert.InDelta(t, math.Sqrt(8750.0/9), stats.StdDev, 1e-9)
+	assert.Equal(t, []float64{25, 100}, []float64{stats.Min, stats.Max})
+	assert.InDeltaSlice(t, []float64{37.5, 50, 75}, []float64{stats.Q1, stats.Median, stats.Q3}, 1e-9)
+	assert.Equal(t, []int{0, 1, 1, 1}, stats.Histogram, "A perfect score falls in the last bucket")
+
+	// The range includes its start and excludes its end
+	stats, err = repo.GetScoreStats(ctx, ScoreFilter{QuizID: DefaultQuizID, From: base.Add(-time.Hour), To: base.Add(time.Hour), Buckets: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.InDelta(t, 37.5, stats.StdDev, 1e-9)
+	assert.InDeltaSlice(t, []float64{43.75, 62.5, 81.25}, []float64{stats.Q1, stats.Median, stats.Q3}, 1e-9)
+	assert.Equal(t, []int{0, 1, 0, 1}, stats.Histogram)
+
+	// Quartiles and extremes are exact to a hundredth of a percent; the mean is exact
+	stats, err = repo.GetScoreStats(ctx, ScoreFilter{QuizID: 2, Buckets: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.InDelta(t, 200.0/3, stats.Mean, 1e-9)
+	assert.Equal(t, []float64{33.33, 100}, []float64{stats.Min, stats.Max})
+	assert.InDelta(t, 66.665, stats.Median, 1e-9)
+	assert.Equal(t, []int{1, 1}, stats.Histogram)
+
+	stats, err = repo.GetScoreStats(ctx, ScoreFilter{QuizID: 2, To: base.Add(-time.Hour), Buckets: 2})
+	require.NoError(t, err)
+	assert.Equal(t, ScoreStats{Histogram: []int{0, 0}}, stats, "No matching scores leave everything zero")
+
+	_, err = repo.GetScoreStats(ctx, ScoreFilter{})
+	assert.ErrorIs(t, err, ErrInvalidScoreFilter)
+}
+
+func TestInMemoryRepository_ItemStats(t *testing.T) {
+	testItemStats(t, NewRepository())
+}
+
+// testItemStats checks AddAnswers and GetItemStats; every implementation must agree.
+func testItemStats(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddQuiz(ctx, Quiz{ID: 2, Name: "Other"}))
+	require.NoError(t, repo.AddAnswers(ctx, []AnswerRecord{
+		{QuestionID: 1, Correct: true, Points: 1, Choices: []int{2}, Total: 1},
+		{QuestionID: 2, Correct: true, Points: 1, Choices: []int{0, 1}, Total: 1},
+	}))
+	require.NoError(t, repo.AddAnswers(ctx, []AnswerRecord{
+		{QuestionID: 1, Points: 0, Choices: []int{0}, Total: 0.5},
+		{QuestionID: 2, Points: 0.5, Choices: []int{1}, Total: 0.5},
+	}))
+	require.NoError(t, repo.AddAnswers(ctx, []AnswerRecord{
+		{QuestionID: 1, Points: 0, Total: 0}, // Unanswered
+		{QuestionID: 2, Points: 0, Choices: []int{2}, Total: 0},
+		{QuizID: 2, QuestionID: 9, Correct: true, Points: 1, Total: 1},
+	}))
+
+	items, err := repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	require.Len(t, items, 2, "Answers to other quizzes are left out")
+
+	assert.Equal(t, 1, items[0].QuestionID)
+	assert.Equal(t, []int{3, 1}, []int{items[0].Responses, items[0].Correct})
+	assert.InDelta(t, 1.0/3, items[0].MeanPoints, 1e-9)
+	assert.InDelta(t, 0.866, items[0].Discrimination, 1e-3)
+	assert.Equal(t, map[int]int{0: 1, 2: 1}, items[0].ChoiceCounts)
+
+	assert.InDelta(t, 1.0, items[1].Discrimination, 1e-9, "Points rising with the total correlate perfectly")
+	assert.Equal(t, map[int]int{0: 1, 1: 2, 2: 1}, items[1].ChoiceCounts)
+
+	items, err = repo.GetItemStats(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, 0.0, items[0].Discrimination, "Constant points correlate with nothing")
+
+	assert.ErrorIs(t, repo.AddAnswers(ctx, []AnswerRecord{{QuizID: 9, QuestionID: 1}}), ErrQuizNotFound)
+
+	// Answers go with their quiz
+	require.NoError(t, repo.DeleteQuiz(ctx, 2))
+	items, err = repo.GetItemStats(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestInMemoryRepository_ReviewStates(t *testing.T) {
+	testReviewStates(t, NewRepository())
+}
+
+// testReviewStates checks GetReviewStates and UpdateReviewState; every implementation must agree.
+func testReviewStates(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.AddUser(ctx, User{ID: "u1", Name: "Ada", TokenHash: "hash-1"}))
+	states, err := repo.GetReviewStates(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, states)
+
+	// The first review starts from a zero state; the key cannot be changed
+	review := func(state *ReviewState) error {
+		state.Reviews++
+		state.Repetitions++
+		state.Interval, state.Ease = 24*time.Hour, 2.5
+		state.LastReviewed, state.Due = now, now.Add(24*time.Hour)
+		state.QuestionID = 99
+		return nil
+	}
+	require.NoError(t, repo.UpdateReviewState(ctx, "u1", 2, func(state *ReviewState) error {
+		assert.Equal(t, ReviewState{UserID: "u1", QuestionID: 2}, *state)
+		return review(state)
+	}))
+	require.NoError(t, repo.UpdateReviewState(ctx, "u1", 1, review))
+	require.NoError(t, repo.UpdateReviewState(ctx, "u1", 1, review))
+
+	// A failing update stores nothing
+	failure := errors.New("rejected")
+	assert.ErrorIs(t, repo.UpdateReviewState(ctx, "u1", 1, func(state *ReviewState) error {
+		state.Reviews = 50
+		return failure
+	}), failure)
+
+	states, err = repo.GetReviewStates(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	assert.Equal(t, []int{1, 2}, []int{states[0].QuestionID, states[1].QuestionID})
+	assert.Equal(t, "u1", states[0].UserID)
+	assert.Equal(t, 2, states[0].Reviews)
+	assert.Equal(t, 24*time.Hour, states[0].Interval)
+	assert.Equal(t, 2.5, states[0].Ease)
+	assert.True(t, now.Equal(states[0].LastReviewed))
+	assert.True(t, now.Add(24*time.Hour).Equal(states[0].Due))
+
+	// Schedules are per user, and only registered users have one
+	states, err = repo.GetReviewStates(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, states)
+	assert.ErrorIs(t, repo.UpdateReviewState(ctx, "missing", 1, review), ErrUserNotFound)
+}
+
+func TestInMemoryRepository_FinishAttempt(t *testing.T) {
+	testFinishAttempt(t, NewRepository())
+}
+
+// testFinishAttempt checks that FinishAttempt stores an attempt and its result together or not at all.
+func testFinishAttempt(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.AddUser(ctx, User{ID: "u1", Name: "Ada", TokenHash: "hash-1"}))
+	require.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: "a1", UserID: "u1", QuestionIDs: []int{1}, StartedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.CreateAttempt(ctx, Attempt{ID: "a2", UserID: "missing", QuestionIDs: []int{1}, StartedAt: now, ExpiresAt: now.Add(time.Hour)}))
+
+	finish := func(attempt *Attempt) (Result, error) {
+		attempt.FinishedAt, attempt.Score, attempt.MaxScore = now, 3, 4
+		return Result{
+			Score:   ScoreRecord{UserID: attempt.UserID, Score: 3, MaxScore: 4, RecordedAt: now},
+			Answers: []AnswerRecord{{QuestionID: 1, Correct: true, Points: 1, Total: 0.75, RecordedAt: now}},
+		}, nil
+	}
+
+	// A failing fn stores nothing
+	failure := errors.New("rejected")
+	assert.ErrorIs(t, repo.FinishAttempt(ctx, "a1", func(attempt *Attempt) (Result, error) {
+		attempt.FinishedAt = now
+		return Result{}, failure
+	}), failure)
+
+	// A score that cannot be stored leaves the attempt unfinished
+	assert.ErrorIs(t, repo.FinishAttempt(ctx, "a2", finish), ErrUserNotFound)
+	attempt, err := repo.GetAttempt(ctx, "a2")
+	require.NoError(t, err)
+	assert.False(t, attempt.Finished())
+
+	scores, err := repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+	items, err := repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	require.NoError(t, repo.FinishAttempt(ctx, "a1", finish))
+	attempt, err = repo.GetAttempt(ctx, "a1")
+	require.NoError(t, err)
+	assert.True(t, attempt.Finished())
+	assert.Equal(t, 3.0, attempt.Score)
+
+	scores, err = repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, "u1", scores[0].UserID)
+	assert.Equal(t, DefaultQuizID, scores[0].QuizID)
+	items, err = repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, 1, items[0].Responses)
+
+	assert.ErrorIs(t, repo.FinishAttempt(ctx, "missing", finish), ErrAttemptNotFound)
+}
+
+func TestInMemoryRepository_AddResult(t *testing.T) {
+	testAddResult(t, NewRepository())
+}
+
+// testAddResult checks that AddResult stores a score and its answers together or not at all.
+func testAddResult(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	answers := []AnswerRecord{{QuestionID: 1, Correct: true, Points: 1, Choices: []int{2}, Total: 1}}
+	assert.ErrorIs(t, repo.AddResult(ctx, Result{Score: ScoreRecord{UserID: "missing", Score: 1, MaxScore: 1}, Answers: answers}), ErrUserNotFound)
+	assert.ErrorIs(t, repo.AddResult(ctx, Result{
+		Score:   ScoreRecord{Score: 1, MaxScore: 1},
+		Answers: append([]AnswerRecord{{QuizID: 9, QuestionID: 1}}, answers...),
+	}), ErrQuizNotFound)
+
+	scores, err := repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, scores, "A rejected result stores no score")
+	items, err := repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	assert.Empty(t, items, "A rejected result stores no answers")
+
+	require.NoError(t, repo.AddResult(ctx, Result{Score: ScoreRecord{Score: 1, MaxScore: 1}, Answers: answers}))
+	scores, err = repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, DefaultQuizID, scores[0].QuizID)
+	items, err = repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, map[int]int{2: 1}, items[0].ChoiceCounts)
+}
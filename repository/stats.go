@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrInvalidScoreFilter is returned for filters without histogram buckets.
+var ErrInvalidScoreFilter = errors.New("invalid score filter: at least one histogram bucket is required")
+
+// percentResolution is how finely the quantile sketch tells percentages apart: to a hundredth of a percent.
+const percentResolution = 100
+
+// scoreStatsBuilder accumulates the scores of a filter for the repositories that aggregate in Go, in
+// constant memory: running sums for the mean and standard deviation, the histogram, and a sketch counting
+// the scores at each hundredth of a percent for the quartiles, minimum and maximum. Between 0% and 100% the
+// sketch has at most 10001 entries however many scores are added.
+type scoreStatsBuilder struct {
+	filter    ScoreFilter
+	count     int
+	sum       float64
+	squares   float64
+	histogram []int
+	sketch    map[int]int
+}
+
+func newScoreStatsBuilder(filter ScoreFilter) (*scoreStatsBuilder, error) {
+	if filter.Buckets <= 0 {
+		return nil, ErrInvalidScoreFilter
+	}
+	filter.QuizID = normalizeQuizID(filter.QuizID)
+	return &scoreStatsBuilder{filter: filter, histogram: make([]int, filter.Buckets), sketch: map[int]int{}}, nil
+}
+
+// add counts the score if the filter selects it and it has a maximum to be a percentage of.
+func (b *scoreStatsBuilder) add(score ScoreRecord) {
+	if normalizeQuizID(score.QuizID) != b.filter.QuizID || !inRange(score.RecordedAt, b.filter.From, b.filter.To) {
+		return
+	}
+	if score.MaxScore <= 0 {
+		return
+	}
+
+	percent := score.Score / score.MaxScore * 100
+	b.count++
+	b.sum += percent
+	b.squares += percent * percent
+	b.histogram[histogramBucket(percent/100, len(b.histogram))]++
+	b.sketch[int(math.Round(percent*percentResolution))]++
+}
+
+// stats returns the summary of the scores added so far.
+func (b *scoreStatsBuilder) stats() ScoreStats {
+	stats := ScoreStats{Count: b.count, Histogram: b.histogram}
+	if stats.Count == 0 {
+		return stats
+	}
+
+	n := float64(stats.Count)
+	stats.Mean = b.sum / n
+	stats.StdDev = math.Sqrt(math.Max(b.squares/n-stats.Mean*stats.Mean, 0))
+
+	keys := make([]int, 0, len(b.sketch))
+	for key := range b.sketch {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	stats.Min = float64(keys[0]) / percentResolution
+	stats.Max = float64(keys[len(keys)-1]) / percentResolution
+	stats.Q1 = b.quantile(keys, 0.25)
+	stats.Median = b.quantile(keys, 0.5)
+	stats.Q3 = b.quantile(keys, 0.75)
+	return stats
+}
+
+// quantile interpolates the q-quantile of the sketched percentages, as PostgreSQL's percentile_cont does;
+// keys are the sketch's keys in order.
+func (b *scoreStatsBuilder) quantile(keys []int, q float64) float64 {
+	position := q * float64(b.count-1)
+	lower := int(math.Floor(position))
+	value := b.percentAt(keys, lower)
+	if lower+1 >= b.count {
+		return value
+	}
+	return value + (b.percentAt(keys, lower+1)-value)*(position-float64(lower))
+}
+
+// percentAt returns the percentage at a zero-based rank.
+func (b *scoreStatsBuilder) percentAt(keys []int, rank int) float64 {
+	seen := 0
+	for _, key := range keys {
+		seen += b.sketch[key]
+		if rank < seen {
+			return float64(key) / percentResolution
+		}
+	}
+	return float64(keys[len(keys)-1]) / percentResolution
+}
+
+// inRange reports whether t falls in [from, to); zero bounds are open.
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// histogramBucket returns the bucket a fraction of the maximum score falls in, clamped to the histogram.
+func histogramBucket(fraction float64, buckets int) int {
+	bucket := int(math.Floor(fraction * float64(buckets)))
+	if bucket >= buckets {
+		return buckets - 1
+	}
+	if bucket < 0 {
+		return 0
+	}
+	return bucket
+}
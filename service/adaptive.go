@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Stopping rule of adaptive quizzes that leave MaxQuestions or TargetError at zero.
+const (
+	DefaultAdaptiveMaxQuestions = 20
+	DefaultAdaptiveTargetError  = 0.3
+)
+
+// Ability estimates are expected a posteriori values under a standard normal prior, evaluated on a grid.
+const (
+	abilityGridMin   = -4.0
+	abilityGridMax   = 4.0
+	abilityGridSteps = 160
+)
+
+// minCalibrationResponses is how many recorded answers a question needs before it is calibrated.
+const minCalibrationResponses = 20
+
+// Bounds that keep calibrated parameters usable when a question has few or unusual responses.
+const (
+	maxBiserial       = 0.95
+	minBiserial       = 0.05
+	maxDiscrimination = 4.0
+	maxDifficulty     = 4.0
+)
+
+// logisticScale converts normal-ogive discriminations to the logistic model.
+const logisticScale = 1.702
+
+// ErrAnswerLocked is returned when an answered question of an adaptive attempt is answered again.
+var ErrAnswerLocked = errors.New("adaptive attempts do not accept a changed answer")
+
+// AbilityEstimate is a player's estimated ability on an adaptive quiz, on the scale of the questions'
+// difficulties: 0 is average, and each unit is one standard deviation of the players' abilities.
+type AbilityEstimate struct {
+	Ability       float64 `json:"ability"`
+	StandardError float64 `json:"standard_error"`
+}
+
+// ItemCalibration reports the item response theory parameters calibration gave a question.
+type ItemCalibration struct {
+	QuestionID     int     `json:"question_id"`
+	Responses      int     `json:"responses"`
+	Difficulty     float64 `json:"difficulty"`
+	Discrimination float64 `json:"discrimination"`
+	// Calibrated is false when the responses could not place the question: too few of them, everybody or
+	// nobody right, or no positive discrimination. The question keeps its previous parameters.
+	Calibrated bool `json:"calibrated"`
+}
+
+// irtResponse is one graded answer of an adaptive attempt with its question's parameters.
+type irtResponse struct {
+	discrimination float64
+	difficulty     float64
+	points         float64
+}
+
+// itemParameters returns the question's discrimination and difficulty; uncalibrated questions count as average.
+func itemParameters(question Question) (discrimination, difficulty float64) {
+	discrimination = question.Discrimination
+	if discrimination == 0 {
+		discrimination = 1
+	}
+	return discrimination, question.Difficulty
+}
+
+// probability is the chance a player of the given ability answers the question right, under the two-parameter logistic model.
+func probability(ability, discrimination, difficulty float64) float64 {
+	return 1 / (1 + math.Exp(-discrimination*(ability-difficulty)))
+}
+
+// information is how much an answer to the question tells about an ability around the given one.
+func information(ability, discrimination, difficulty float64) float64 {
+	p := probability(ability, discrimination, difficulty)
+	return discrimination * discrimination * p * (1 - p)
+}
+
+// estimateAbility returns the expected a posteriori ability and its standard error. Partial credit counts
+// as that share of a right answer. Unlike a maximum likelihood estimate it stays finite when every answer
+// was right, or every one wrong.
+func estimateAbility(responses []irtResponse) (ability, standardError float64) {
+	step := (abilityGridMax - abilityGridMin) / abilityGridSteps
+	logWeights := make([]float64, abilityGridSteps+1)
+	maxLog := math.Inf(-1)
+	for i := range logWeights {
+		theta := abilityGridMin + float64(i)*step
+		logWeight := -theta * theta / 2
+		for _, response := range responses {
+			p := probability(theta, response.discrimination, response.difficulty)
+			logWeight += response.points*math.Log(p) + (1-response.points)*math.Log(1-p)
+		}
+		logWeights[i] = logWeight
+		maxLog = math.Max(maxLog, logWeight)
+	}
+
+	var total, sum, sumSquares float64
+	for i, logWeight := range logWeights {
+		theta := abilityGridMin + float64(i)*step
+		weight := math.Exp(logWeight - maxLog)
+		total += weight
+		sum += weight * theta
+		sumSquares += weight * theta * theta
+	}
+	ability = sum / total
+	return ability, math.Sqrt(math.Max(sumSquares/total-ability*ability, 0))
+}
+
+// nextQuestion picks the question not yet served that is most informative at the ability; ties go to the lowest ID.
+func nextQuestion(candidates []Question, served []int, ability float64) (Question, bool) {
+	var best Question
+	bestInformation, found := 0.0, false
+	for _, candidate := range candidates {
+		if containsID(served, candidate.ID) {
+			continue
+		}
+		discrimination, difficulty := itemParameters(candidate)
+		info := information(ability, discrimination, difficulty)
+		if !found || info > bestInformation || (info == bestInformation && candidate.ID < best.ID) {
+			best, bestInformation, found = candidate, info, true
+		}
+	}
+	return best, found
+}
+
+// adaptiveDone reports whether an adaptive attempt with the given answers and standard error should stop.
+func adaptiveDone(quiz repository.Quiz, answered int, standardError float64) bool {
+	maxQuestions, targetError := quiz.MaxQuestions, quiz.TargetError
+	if maxQuestions == 0 {
+		maxQuestions = DefaultAdaptiveMaxQuestions
+	}
+	if targetError == 0 {
+		targetError = DefaultAdaptiveTargetError
+	}
+	return answered >= maxQuestions || standardError <= targetError
+}
+
+// adapt re-estimates an adaptive attempt's ability from its answers and, unless the stopping rule is met,
+// serves the next question from the quiz's candidates.
+func adapt(attempt *repository.Attempt, quiz repository.Quiz, candidates []Question) {
+	byID := make(map[int]Question, len(candidates))
+	for _, candidate := range candidates {
+		byID[candidate.ID] = candidate
+	}
+
+	answers := attemptAnswers(*attempt)
+	responses := make([]irtResponse, 0, len(answers))
+	for _, id := range attempt.QuestionIDs {
+		question, exists := byID[id]
+		answer, answered := answers[id]
+		if !exists || !answered {
+			continue
+		}
+		discrimination, difficulty := itemParameters(question)
+		responses = append(responses, irtResponse{discrimination, difficulty, gradeQuestion(question, answer)})
+	}
+
+	attempt.Ability, attempt.AbilityError = estimateAbility(responses)
+	if adaptiveDone(quiz, len(responses), attempt.AbilityError) {
+		return
+	}
+	if next, ok := nextQuestion(candidates, attempt.QuestionIDs, attempt.Ability); ok {
+		attempt.QuestionIDs = append(attempt.QuestionIDs, next.ID)
+	}
+}
+
+// adaptiveComplete reports whether an adaptive attempt has no question left to answer.
+func adaptiveComplete(attempt repository.Attempt) bool {
+	answers := attemptAnswers(attempt)
+	for _, id := range attempt.QuestionIDs {
+		if _, answered := answers[id]; !answered {
+			return false
+		}
+	}
+	return true
+}
+
+// CalibrateQuiz estimates the difficulty and discrimination of a quiz's questions from their recorded
+// answers and stores them on the questions. The estimates convert each question's classical statistics,
+// its p-value and point-biserial correlation, to the two-parameter logistic model.
+func (q *QuizServiceImpl) CalibrateQuiz(ctx context.Context, quizID int) ([]ItemCalibration, error) {
+	_, questions, err := q.loadQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := q.repo.GetItemStats(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	byQuestion := make(map[int]repository.ItemStats, len(stats))
+	for _, item := range stats {
+		byQuestion[item.QuestionID] = item
+	}
+
+	calibrations := make([]ItemCalibration, 0, len(questions))
+	for _, question := range questions {
+		item := byQuestion[question.ID]
+		calibration := ItemCalibration{
+			QuestionID:     question.ID,
+			Responses:      item.Responses,
+			Difficulty:     question.Difficulty,
+			Discrimination: question.Discrimination,
+		}
+		if discrimination, difficulty, ok := calibrate(item); ok {
+			question.Discrimination, question.Difficulty = discrimination, difficulty
+			if err := q.repo.UpdateQuestion(ctx, toRepositoryQuestion(question)); err != nil {
+				return nil, err
+			}
+			calibration.Discrimination, calibration.Difficulty, calibration.Calibrated = discrimination, difficulty, true
+		}
+		calibrations = append(calibrations, calibration)
+	}
+	return calibrations, nil
+}
+
+// calibrate converts a question's classical statistics to logistic discrimination and difficulty, with
+// Lord's normal-ogive approximations: the point-biserial correlation becomes a biserial one, which sets the
+// discrimination, and the p-value's normal quantile divided by it sets the difficulty.
+func calibrate(item repository.ItemStats) (discrimination, difficulty float64, ok bool) {
+	p := item.MeanPoints
+	if item.Responses < minCalibrationResponses || p <= 0 || p >= 1 {
+		return 0, 0, false
+	}
+
+	z := math.Sqrt2 * math.Erfinv(2*p-1)
+	density := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	biserial := item.Discrimination * math.Sqrt(p*(1-p)) / density
+	if biserial < minBiserial {
+		return 0, 0, false
+	}
+	biserial = math.Min(biserial, maxBiserial)
+
+	discrimination = math.Min(logisticScale*biserial/math.Sqrt(1-biserial*biserial), maxDiscrimination)
+	difficulty = math.Max(-maxDifficulty, math.Min(-z/biserial, maxDifficulty))
+	return discrimination, difficulty, true
+}
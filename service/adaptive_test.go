@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestEstimateAbility(t *testing.T) {
+	// Without answers the estimate is the prior: average ability, one standard deviation either way
+	ability, standardError := estimateAbility(nil)
+	assert.InDelta(t, 0, ability, 1e-9)
+	assert.InDelta(t, 1, standardError, 0.01)
+
+	// Right answers raise the estimate, wrong ones lower it, and each answer narrows it
+	right := []irtResponse{{1.5, -1, 1}, {1.5, 0, 1}, {1.5, 1, 1}}
+	high, highError := estimateAbility(right)
+	assert.Greater(t, high, 0.5)
+	assert.Less(t, highError, standardError)
+
+	wrong := []irtResponse{{1.5, -1, 0}, {1.5, 0, 0}, {1.5, 1, 0}}
+	low, lowError := estimateAbility(wrong)
+	assert.InDelta(t, -high, low, 1e-9, "The estimate is symmetric")
+	assert.InDelta(t, highError, lowError, 1e-9)
+
+	// Partial credit lands in between
+	half, _ := estimateAbility([]irtResponse{{1.5, 0, 0.5}})
+	assert.InDelta(t, 0, half, 1e-9)
+}
+
+func TestNextQuestion(t *testing.T) {
+	candidates := []Question{
+		{ID: 1, Difficulty: -2, Discrimination: 1.5},
+		{ID: 2, Difficulty: 0, Discrimination: 1.5},
+		{ID: 3, Difficulty: 1, Discrimination: 1.5},
+		{ID: 4, Difficulty: 1, Discrimination: 0.5},
+		{ID: 5, Difficulty: 1, Discrimination: 1.5},
+	}
+
+	// The most informative question sits at the ability, and sharper questions tell more
+	next, ok := nextQuestion(candidates, nil, 0.1)
+	require.True(t, ok)
+	assert.Equal(t, 2, next.ID)
+	next, _ = nextQuestion(candidates, nil, 1)
+	assert.Equal(t, 3, next.ID, "Ties go to the lowest ID")
+
+	// Served questions are never picked again
+	next, _ = nextQuestion(candidates, []int{2, 3, 5}, 0.5)
+	assert.Equal(t, 4, next.ID)
+	_, ok = nextQuestion(candidates, []int{1, 2, 3, 4, 5}, 0)
+	assert.False(t, ok)
+}
+
+func TestCalibrate(t *testing.T) {
+	// An item half the players get right sits at average ability
+	discrimination, difficulty, ok := calibrate(repository.ItemStats{Responses: 50, MeanPoints: 0.5, Discrimination: 0.4})
+	require.True(t, ok)
+	assert.InDelta(t, 0, difficulty, 1e-9)
+	assert.InDelta(t, 0.986, discrimination, 0.001)
+
+	// Easier items are placed lower, and sharper correlations discriminate more
+	_, easy, ok := calibrate(repository.ItemStats{Responses: 50, MeanPoints: 0.8, Discrimination: 0.4})
+	require.True(t, ok)
+	assert.Less(t, easy, 0.0)
+	sharp, _, _ := calibrate(repository.ItemStats{Responses: 50, MeanPoints: 0.5, Discrimination: 0.6})
+	assert.Greater(t, sharp, discrimination)
+
+	// Items that tell nobody apart, or have too few answers, are left alone
+	for _, item := range []repository.ItemStats{
+		{Responses: 5, MeanPoints: 0.5, Discrimination: 0.4},
+		{Responses: 50, MeanPoints: 1, Discrimination: 0},
+		{Responses: 50, MeanPoints: 0, Discrimination: 0},
+		{Responses: 50, MeanPoints: 0.5, Discrimination: -0.3},
+	} {
+		_, _, ok := calibrate(item)
+		assert.False(t, ok, "%+v should not be calibrated", item)
+	}
+}
+
+// placementQuestions are nine questions for an adaptive quiz 2, difficulties -2 to 2.
+var placementQuestions = []Question{
+	{ID: 1, QuizID: 2, Question: "Level 0", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: -2, Discrimination: 2},
+	{ID: 2, QuizID: 2, Question: "Level 1", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: -1.5, Discrimination: 2},
+	{ID: 3, QuizID: 2, Question: "Level 2", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: -1, Discrimination: 2},
+	{ID: 4, QuizID: 2, Question: "Level 3", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: -0.5, Discrimination: 2},
+	{ID: 5, QuizID: 2, Question: "Level 4", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: 0, Discrimination: 2},
+	{ID: 6, QuizID: 2, Question: "Level 5", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: 0.5, Discrimination: 2},
+	{ID: 7, QuizID: 2, Question: "Level 6", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: 1, Discrimination: 2},
+	{ID: 8, QuizID: 2, Question: "Level 7", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: 1.5, Discrimination: 2},
+	{ID: 9, QuizID: 2, Question: "Level 8", Alternatives: []string{"wrong", "right"}, CorrectAnswer: 1, Difficulty: 2, Discrimination: 2},
+}
+
+func TestQuizService_AdaptiveAttempt(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newSeededService(t, testFixture{
+		Quizzes:   []Quiz{{ID: 2, Name: "Placement", Adaptive: true, MaxQuestions: 4, TargetError: 0.01}},
+		Questions: placementQuestions,
+	})
+
+	// The first question suits an average player
+	attempt, err := svc.StartQuizAttempt(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, attempt.Questions, 1)
+	assert.Equal(t, 5, attempt.Questions[0].ID)
+	require.NotNil(t, attempt.Ability)
+	assert.False(t, attempt.Done)
+
+	// A strong player gets ever harder questions; answers cannot be changed
+	for i := 0; i < 4; i++ {
+		current := attempt.Questions[len(attempt.Questions)-1]
+		require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: current.ID, Choice: 1}))
+		assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: current.ID, Choice: 0}), ErrAnswerLocked)
+
+		previous := attempt.Ability.Ability
+		attempt, err = svc.GetAttempt(ctx, attempt.ID)
+		require.NoError(t, err)
+		assert.Greater(t, attempt.Ability.Ability, previous)
+		if i < 3 {
+			require.Len(t, attempt.Questions, i+2)
+			assert.Greater(t, attempt.Questions[i+1].ID, current.ID, "A right answer leads to a harder question")
+		}
+	}
+
+	// The attempt stops at its maximum length and reports the estimate with the result
+	assert.Len(t, attempt.Questions, 4)
+	assert.True(t, attempt.Done)
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, result.Score)
+	require.NotNil(t, result.Ability)
+	assert.Equal(t, *attempt.Ability, *result.Ability)
+
+	// Adaptive quizzes pick their own questions
+	_, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.ErrorIs(t, err, ErrAttemptRequired)
+	assert.ErrorIs(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Placement", Adaptive: true, Draw: []DrawRule{{Pool: "a", Count: 1}}}), ErrInvalidQuiz)
+}
+
+func TestQuizService_AdaptiveAttempt_StopsOnPrecision(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newSeededService(t, testFixture{
+		Quizzes:   []Quiz{{ID: 2, Name: "Placement", Adaptive: true, TargetError: 0.6}},
+		Questions: placementQuestions,
+	})
+
+	attempt, err := svc.StartQuizAttempt(ctx, 2)
+	require.NoError(t, err)
+	for !attempt.Done {
+		current := attempt.Questions[len(attempt.Questions)-1]
+		require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: current.ID, Choice: len(attempt.Questions) % 2}))
+		attempt, err = svc.GetAttempt(ctx, attempt.ID)
+		require.NoError(t, err)
+	}
+
+	assert.LessOrEqual(t, attempt.Ability.StandardError, 0.6)
+	assert.Less(t, len(attempt.Questions), 9, "The attempt should stop once the estimate is precise enough")
+}
+
+func TestQuizService_CalibrateQuiz(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newSeededService(t, testFixture{
+		Quizzes:   []Quiz{{ID: 2, Name: "Placement", Adaptive: true}},
+		Questions: placementQuestions,
+	})
+
+	// Stronger players answer question 1 right; question 2 everybody gets right; the rest were never answered
+	var records []repository.AnswerRecord
+	for i := 0; i < 40; i++ {
+		total := float64(i) / 40
+		points := 0.0
+		if i >= 10 {
+			points = 1
+		}
+		records = append(records,
+			repository.AnswerRecord{QuizID: 2, QuestionID: 1, Correct: points == 1, Points: points, Total: total},
+			repository.AnswerRecord{QuizID: 2, QuestionID: 2, Correct: true, Points: 1, Total: total},
+		)
+	}
+	require.NoError(t, repo.AddAnswers(ctx, records))
+
+	calibrations, err := svc.CalibrateQuiz(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, calibrations, 9)
+	assert.True(t, calibrations[0].Calibrated)
+	assert.Equal(t, 40, calibrations[0].Responses)
+	assert.Less(t, calibrations[0].Difficulty, 0.0, "Three in four got it right")
+	assert.Greater(t, calibrations[0].Discrimination, 1.0)
+	assert.False(t, calibrations[1].Calibrated)
+	assert.Equal(t, -1.5, calibrations[1].Difficulty, "Uncalibrated questions keep their parameters")
+	assert.False(t, calibrations[2].Calibrated)
+
+	// The parameters are stored on the question
+	stored, err := repo.GetQuestionByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, calibrations[0].Difficulty, stored.Difficulty)
+	assert.Equal(t, calibrations[0].Discrimination, stored.Discrimination)
+
+	_, err = svc.CalibrateQuiz(ctx, 99)
+	assert.ErrorIs(t, err, ErrQuizNotFound)
+}
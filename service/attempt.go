@@ -0,0 +1,533 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Attempt is the player-facing view of a server-side quiz session.
+type Attempt struct {
+	ID         string           `json:"id"`
+	QuizID     int              `json:"quiz_id"`
+	Seed       int64            `json:"seed,omitempty"` // Reproduces the attempt's shuffled order; zero when nothing is shuffled
+	Questions  []PlayerQuestion `json:"questions"`
+	Answers    []Answer         `json:"answers"`
+	StartedAt  time.Time        `json:"started_at"`
+	ExpiresAt  time.Time        `json:"expires_at"`
+	Deadline   *time.Time       `json:"deadline,omitempty"`
+	LatePolicy LatePolicy       `json:"late_policy,omitempty"`
+	Finished   bool             `json:"finished"`
+	Result     *SubmitResponse  `json:"result,omitempty"`
+	// Adaptive attempts report the ability estimated so far, and Done once no further question will be served
+	Ability *AbilityEstimate `json:"ability,omitempty"`
+	Done    bool             `json:"done,omitempty"`
+	// Hints revealed so far, by question in the attempt's order
+	Hints []Hint `json:"hints,omitempty"`
+}
+
+// Errors returned by the attempt flow.
+var (
+	ErrAttemptNotFound = repository.ErrAttemptNotFound
+	ErrAttemptExpired  = errors.New("attempt has expired")
+	ErrAttemptFinished = errors.New("attempt is already finished")
+)
+
+// StartAttempt opens a new attempt over the default quiz.
+func (q *QuizServiceImpl) StartAttempt(ctx context.Context) (Attempt, error) {
+	return q.StartQuizAttempt(ctx, DefaultQuizID)
+}
+
+// StartQuizAttempt opens a new attempt over the current questions of one quiz, or those its draw rules pick.
+// The quiz's own time limit and late policy take precedence over the server defaults.
+// The attempt, and the score it records, belong to the player identified in ctx, if any.
+func (q *QuizServiceImpl) StartQuizAttempt(ctx context.Context, quizID int) (Attempt, error) {
+	quiz, questions, err := q.loadQuiz(ctx, quizID)
+	if err != nil {
+		return Attempt{}, err
+	}
+	if len(questions) == 0 {
+		return Attempt{}, ErrNoQuestions
+	}
+
+	id, err := newRandomID()
+	if err != nil {
+		return Attempt{}, err
+	}
+
+	if quiz.Adaptive {
+		// Adaptive attempts start with the question most informative about an average player
+		first, _ := nextQuestion(questions, nil, 0)
+		questions = []Question{first}
+	}
+
+	var seed int64
+	if quiz.ShuffleQuestions || quiz.ShuffleAlternatives || len(quiz.Draw) > 0 {
+		if seed, err = newSeed(); err != nil {
+			return Attempt{}, err
+		}
+	}
+	if len(quiz.Draw) > 0 {
+		// The attempt records the drawn questions, so grading and review use exactly what was served
+		if questions, err = drawQuestions(fromRepositoryDraw(quiz.Draw), questions, seed); err != nil {
+			return Attempt{}, err
+		}
+	}
+
+	questionIDs := make([]int, 0, len(questions))
+	for _, question := range questions {
+		questionIDs = append(questionIDs, question.ID)
+	}
+	if quiz.ShuffleQuestions {
+		questions = shuffleQuestions(questions, questionIDs, seed)
+	}
+
+	now := q.now()
+	playerID, _ := PlayerFromContext(ctx)
+	attempt := repository.Attempt{
+		ID:          id,
+		UserID:      playerID,
+		QuizID:      quiz.ID,
+		QuestionIDs: questionIDs,
+		Seed:        seed,
+		Answers:     map[int]int{},
+		ServedAt:    map[int]time.Time{},
+		StartedAt:   now,
+		ExpiresAt:   now.Add(q.attemptTTL),
+
+		ShuffleAlternatives: quiz.ShuffleAlternatives,
+		Adaptive:            quiz.Adaptive,
+	}
+	if quiz.Adaptive {
+		attempt.Ability, attempt.AbilityError = estimateAbility(nil)
+	}
+	if timeLimit := q.quizTimeLimit(quiz); timeLimit > 0 {
+		attempt.Deadline = now.Add(timeLimit)
+		attempt.LatePolicy = string(q.quizLatePolicy(quiz))
+
+		// Never expire a timed attempt before its deadline
+		if attempt.ExpiresAt.Before(attempt.Deadline) {
+			attempt.ExpiresAt = attempt.Deadline
+		}
+	}
+
+	if err := q.repo.CreateAttempt(ctx, attempt); err != nil {
+		return Attempt{}, err
+	}
+
+	return toAttempt(attempt, questions), nil
+}
+
+// GetAttempt returns an attempt with its questions and saved answers, so a player can resume it.
+// An attempt whose deadline passed under LatePolicyAutoFinalize is finished on the way.
+func (q *QuizServiceImpl) GetAttempt(ctx context.Context, id string) (Attempt, error) {
+	attempt, err := q.ownedAttempt(ctx, id)
+	if err != nil {
+		return Attempt{}, err
+	}
+
+	questions, err := q.attemptQuestions(ctx, attempt)
+	if err != nil {
+		return Attempt{}, err
+	}
+
+	if now := q.now(); autoFinalizes(attempt, now) && len(questions) > 0 {
+		if attempt, err = q.finish(ctx, id, attempt.QuizID, questions, now); err != nil {
+			return Attempt{}, err
+		}
+	}
+
+	return toAttempt(attempt, questions), nil
+}
+
+// ServeQuestion returns one question of an open attempt and starts its per-question timer.
+// Serving the same question again does not restart the timer.
+func (q *QuizServiceImpl) ServeQuestion(ctx context.Context, attemptID string, questionID int) (PlayerQuestion, error) {
+	attempt, question, err := q.attemptQuestion(ctx, attemptID, questionID)
+	if err != nil {
+		return PlayerQuestion{}, err
+	}
+
+	now := q.now()
+	err = q.repo.UpdateAttempt(ctx, attemptID, func(stored *repository.Attempt) error {
+		if err := checkOpen(*stored, now); err != nil {
+			return err
+		}
+
+		if stored.ServedAt == nil {
+			stored.ServedAt = map[int]time.Time{}
+		}
+		if _, served := stored.ServedAt[questionID]; !served {
+			stored.ServedAt[questionID] = now
+		}
+		return nil
+	})
+	if err != nil {
+		return PlayerQuestion{}, q.handleLate(ctx, attemptID, now, err)
+	}
+
+	return attemptShuffler(attempt).present(fromRepositoryQuestion(question)), nil
+}
+
+// SaveAnswer records (or replaces) the answer to one question of an open attempt.
+func (q *QuizServiceImpl) SaveAnswer(ctx context.Context, attemptID string, answer Answer) error {
+	attempt, question, err := q.attemptQuestion(ctx, attemptID, answer.QuestionID)
+	if err != nil {
+		return err
+	}
+	if err := validateAnswer(fromRepositoryQuestion(question), answer); err != nil {
+		return err
+	}
+	// Answers refer to the alternatives as shown; store them by their canonical index
+	answer = attemptShuffler(attempt).toCanonical(fromRepositoryQuestion(question), answer)
+
+	// Adaptive attempts pick the next question from the quiz's current questions
+	var quiz repository.Quiz
+	var candidates []Question
+	if attempt.Adaptive {
+		if quiz, candidates, err = q.loadQuiz(ctx, attempt.QuizID); err != nil {
+			return err
+		}
+	}
+
+	now := q.now()
+	err = q.repo.UpdateAttempt(ctx, attemptID, func(stored *repository.Attempt) error {
+		if err := checkOpen(*stored, now); err != nil {
+			return err
+		}
+		if questionTimeUp(*stored, question, now) {
+			return ErrDeadlinePassed
+		}
+
+		if stored.Adaptive {
+			if _, answered := attemptAnswers(*stored)[question.ID]; answered {
+				return ErrAnswerLocked
+			}
+		}
+
+		storeAnswer(stored, question, answer)
+		if stored.Adaptive {
+			adapt(stored, quiz, candidates)
+		}
+		return nil
+	})
+	if err != nil {
+		return q.handleLate(ctx, attemptID, now, err)
+	}
+	return nil
+}
+
+// FinishAttempt grades an open attempt and records its score exactly once.
+// Finishing an attempt again returns the original result without recording another score.
+func (q *QuizServiceImpl) FinishAttempt(ctx context.Context, attemptID string) (SubmitResponse, error) {
+	attempt, err := q.ownedAttempt(ctx, attemptID)
+	if err != nil {
+		return SubmitResponse{}, err
+	}
+	if attempt.Finished() {
+		return attemptResponse(attempt), nil
+	}
+
+	questions, err := q.attemptQuestions(ctx, attempt)
+	if err != nil {
+		return SubmitResponse{}, err
+	}
+	if len(questions) == 0 {
+		return SubmitResponse{}, ErrNoQuestions
+	}
+
+	attempt, err = q.finish(ctx, attemptID, attempt.QuizID, questions, q.now())
+	if err != nil {
+		return SubmitResponse{}, err
+	}
+	return attemptResponse(attempt), nil
+}
+
+// finish grades the attempt with its quiz's scoring strategy, records its score and answers and returns the finished
+// attempt. The attempt is only stored as finished together with them, so a failure leaves it open to finish again.
+// If another request finished it first, that result is returned and no second score is recorded.
+func (q *QuizServiceImpl) finish(ctx context.Context, attemptID string, quizID int, questions []Question, now time.Time) (repository.Attempt, error) {
+	if quizID == 0 {
+		quizID = DefaultQuizID
+	}
+	quiz, err := q.repo.GetQuiz(ctx, quizID)
+	if err != nil {
+		return repository.Attempt{}, err
+	}
+	scorer, err := q.scorer(quiz)
+	if err != nil {
+		return repository.Attempt{}, err
+	}
+
+	// Rank while holding the quiz's distribution, so the standing and the stored score agree
+	ranked, err := q.lockRankedQuiz(ctx, quizID)
+	if err != nil {
+		return repository.Attempt{}, err
+	}
+	defer ranked.mu.Unlock()
+
+	// Grade inside the update so answers saved concurrently are either all in or all out
+	var attempt repository.Attempt
+	var record repository.ScoreRecord
+	alreadyFinished := false
+	err = q.repo.FinishAttempt(ctx, attemptID, func(stored *repository.Attempt) (repository.Result, error) {
+		if stored.Finished() {
+			alreadyFinished = true
+			attempt = *stored
+			return repository.Result{}, ErrAttemptFinished
+		}
+		if !now.Before(stored.ExpiresAt) && !autoFinalizes(*stored, now) {
+			return repository.Result{}, ErrAttemptExpired
+		}
+
+		// A late attempt counts as finished at its deadline
+		finishedAt := now
+		if deadlinePassed(*stored, now) {
+			finishedAt = stored.Deadline
+		}
+
+		answers := attemptAnswers(*stored)
+		results := gradeAnswers(questions, answers, stored.Hints)
+		var timeLimit time.Duration
+		if !stored.Deadline.IsZero() {
+			timeLimit = stored.Deadline.Sub(stored.StartedAt)
+		}
+
+		stored.FinishedAt = finishedAt
+		stored.Elapsed = finishedAt.Sub(stored.StartedAt)
+		stored.Score, stored.MaxScore = scorer.Score(scoreSheet(quiz, questions, results, stored.Elapsed, timeLimit))
+		stored.Results = toAttemptResults(results)
+
+		record = repository.ScoreRecord{
+			UserID:     stored.UserID,
+			QuizID:     quizID,
+			Score:      stored.Score,
+			MaxScore:   stored.MaxScore,
+			Elapsed:    stored.Elapsed,
+			RecordedAt: stored.FinishedAt,
+		}
+
+		// Keep the standing on the attempt so repeated finishes return the same comparison
+		standing := newStanding(ranked.place(record))
+		stored.Comparison = standing.Message()
+		stored.Standing = repository.Standing(standing)
+		attempt = *stored
+		return repository.Result{
+			Score:   record,
+			Answers: answerRecords(quizID, questions, answers, results, stored.Score, stored.MaxScore, stored.FinishedAt),
+		}, nil
+	})
+	if alreadyFinished {
+		return attempt, nil
+	}
+	if err != nil {
+		return repository.Attempt{}, err
+	}
+	ranked.add(record)
+	return attempt, nil
+}
+
+// handleLate finishes an attempt that reached its deadline under LatePolicyAutoFinalize.
+// The late request itself is still reported as ErrDeadlinePassed.
+func (q *QuizServiceImpl) handleLate(ctx context.Context, attemptID string, now time.Time, err error) error {
+	if !errors.Is(err, ErrDeadlinePassed) {
+		return err
+	}
+
+	attempt, getErr := q.repo.GetAttempt(ctx, attemptID)
+	if getErr != nil || !autoFinalizes(attempt, now) {
+		return err
+	}
+
+	questions, getErr := q.attemptQuestions(ctx, attempt)
+	if getErr != nil {
+		return getErr
+	}
+	if len(questions) > 0 {
+		if _, finishErr := q.finish(ctx, attemptID, attempt.QuizID, questions, now); finishErr != nil {
+			return finishErr
+		}
+	}
+	return err
+}
+
+// ownedAttempt loads an attempt for the caller. A registered player's attempt belongs to them alone: anyone
+// else gets ErrAttemptNotFound, so an attempt ID reveals nothing. Anonymous attempts are open to whoever holds the ID.
+func (q *QuizServiceImpl) ownedAttempt(ctx context.Context, id string) (repository.Attempt, error) {
+	attempt, err := q.repo.GetAttempt(ctx, id)
+	if err != nil {
+		return repository.Attempt{}, err
+	}
+	if playerID, _ := PlayerFromContext(ctx); attempt.UserID != "" && attempt.UserID != playerID {
+		return repository.Attempt{}, ErrAttemptNotFound
+	}
+	return attempt, nil
+}
+
+// attemptQuestion loads one of the caller's attempts and one of its questions.
+func (q *QuizServiceImpl) attemptQuestion(ctx context.Context, attemptID string, questionID int) (repository.Attempt, repository.Question, error) {
+	attempt, err := q.ownedAttempt(ctx, attemptID)
+	if err != nil {
+		return repository.Attempt{}, repository.Question{}, err
+	}
+	if !containsID(attempt.QuestionIDs, questionID) {
+		return repository.Attempt{}, repository.Question{}, ErrUnknownQuestion
+	}
+
+	question, err := q.repo.GetQuestionByID(ctx, questionID)
+	if errors.Is(err, repository.ErrQuestionNotFound) {
+		return repository.Attempt{}, repository.Question{}, ErrUnknownQuestion
+	}
+	return attempt, question, err
+}
+
+// attemptQuestions loads the questions served in an attempt, skipping any deleted since.
+func (q *QuizServiceImpl) attemptQuestions(ctx context.Context, attempt repository.Attempt) ([]Question, error) {
+	questions := make([]Question, 0, len(attempt.QuestionIDs))
+	for _, id := range attempt.QuestionIDs {
+		repoQuestion, err := q.repo.GetQuestionByID(ctx, id)
+		if errors.Is(err, repository.ErrQuestionNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, fromRepositoryQuestion(repoQuestion))
+	}
+	return questions, nil
+}
+
+// toAttempt maps a stored attempt and its questions to the player-facing view.
+func toAttempt(attempt repository.Attempt, questions []Question) Attempt {
+	view := Attempt{
+		ID:         attempt.ID,
+		QuizID:     attempt.QuizID,
+		Seed:       attempt.Seed,
+		Questions:  make([]PlayerQuestion, 0, len(questions)),
+		Answers:    make([]Answer, 0, len(attempt.Answers)+len(attempt.Responses)),
+		StartedAt:  attempt.StartedAt,
+		ExpiresAt:  attempt.ExpiresAt,
+		LatePolicy: LatePolicy(attempt.LatePolicy),
+		Finished:   attempt.Finished(),
+	}
+	if !attempt.Deadline.IsZero() {
+		deadline := attempt.Deadline
+		view.Deadline = &deadline
+	}
+
+	answers := attemptAnswers(attempt)
+	shuffler := attemptShuffler(attempt)
+	for _, question := range questions {
+		view.Questions = append(view.Questions, shuffler.present(question))
+		if answer, answered := answers[question.ID]; answered {
+			view.Answers = append(view.Answers, shuffler.toShown(question, answer))
+		}
+		view.Hints = append(view.Hints, revealedHints(attempt, question)...)
+	}
+
+	if view.Finished {
+		result := attemptResponse(attempt)
+		view.Result = &result
+	}
+	if attempt.Adaptive {
+		view.Ability = &AbilityEstimate{Ability: attempt.Ability, StandardError: attempt.AbilityError}
+		view.Done = adaptiveComplete(attempt)
+	}
+
+	return view
+}
+
+// attemptResponse rebuilds the submission response stored on a finished attempt.
+func attemptResponse(attempt repository.Attempt) SubmitResponse {
+	results := make([]QuestionResult, 0, len(attempt.Results))
+	for _, result := range attempt.Results {
+		results = append(results, QuestionResult{QuestionID: result.QuestionID, Status: result.Status, Points: result.Points, Hints: result.Hints})
+	}
+
+	response := SubmitResponse{
+		Score:          attempt.Score,
+		MaxScore:       attempt.MaxScore,
+		Percentage:     percentage(attempt.Score, attempt.MaxScore),
+		Comparison:     attempt.Comparison,
+		Standing:       Standing(attempt.Standing),
+		Results:        results,
+		ElapsedSeconds: attempt.Elapsed.Seconds(),
+	}
+	if attempt.Adaptive {
+		response.Ability = &AbilityEstimate{Ability: attempt.Ability, StandardError: attempt.AbilityError}
+	}
+	return response
+}
+
+// toAttemptResults maps graded results to the repository format.
+func toAttemptResults(results []QuestionResult) []repository.AttemptResult {
+	attemptResults := make([]repository.AttemptResult, 0, len(results))
+	for _, result := range results {
+		attemptResults = append(attemptResults, repository.AttemptResult{QuestionID: result.QuestionID, Status: result.Status, Points: result.Points, Hints: result.Hints})
+	}
+	return attemptResults
+}
+
+// storeAnswer records an answer on the attempt: single choices in Answers, everything else in Responses.
+func storeAnswer(attempt *repository.Attempt, question repository.Question, answer Answer) {
+	if question.Type == "" || question.Type == QuestionTypeSingle {
+		if attempt.Answers == nil {
+			attempt.Answers = map[int]int{}
+		}
+		attempt.Answers[answer.QuestionID] = answer.Choice
+		return
+	}
+
+	if attempt.Responses == nil {
+		attempt.Responses = map[int]repository.Response{}
+	}
+	attempt.Responses[answer.QuestionID] = repository.Response{
+		Choices: answer.Choices,
+		Text:    answer.Text,
+		Number:  answer.Number,
+		Order:   answer.Order,
+		Matches: answer.Matches,
+	}
+}
+
+// attemptAnswers collects the answers saved on an attempt, keyed by question ID.
+func attemptAnswers(attempt repository.Attempt) map[int]Answer {
+	answers := make(map[int]Answer, len(attempt.Answers)+len(attempt.Responses))
+	for questionID, choice := range attempt.Answers {
+		answers[questionID] = Answer{QuestionID: questionID, Choice: choice}
+	}
+	for questionID, response := range attempt.Responses {
+		answers[questionID] = Answer{
+			QuestionID: questionID,
+			Choices:    response.Choices,
+			Text:       response.Text,
+			Number:     response.Number,
+			Order:      response.Order,
+			Matches:    response.Matches,
+		}
+	}
+	return answers
+}
+
+// containsID reports whether id is in ids.
+func containsID(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// newRandomID returns a random, unguessable identifier for attempts, players and player tokens.
+func newRandomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// fakeClock is a manually advanced clock for expiry tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// arithmeticFixture holds two questions on the default quiz, each answered by its second alternative.
+var arithmeticFixture = testFixture{Questions: []Question{
+	{ID: 1, Question: "What is 1 + 1?", Alternatives: []string{"1", "2", "3"}, CorrectAnswer: 1},
+	{ID: 2, Question: "What is 2 + 2?", Alternatives: []string{"3", "4", "5"}, CorrectAnswer: 1},
+}}
+
+func TestQuizService_AttemptFlow(t *testing.T) {
+	svc, repo := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	// Start an attempt
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, attempt.ID, "The attempt should have an ID")
+	assert.Len(t, attempt.Questions, 2, "The attempt should serve every question")
+
+	// Save answers one at a time, changing one of them
+	assert.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 0}))
+	assert.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+
+	// Resume the attempt
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Answer{{QuestionID: 1, Choice: 1}}, resumed.Answers, "Only the latest answer should be kept")
+	assert.False(t, resumed.Finished)
+
+	// Finish the attempt
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, result.Score, "The score should be 1")
+	assert.Equal(t, []QuestionResult{
+		{QuestionID: 1, Status: ResultCorrect, Points: 1},
+		{QuestionID: 2, Status: ResultMissing},
+	}, result.Results)
+
+	// Finishing again returns the same result without recording a second score
+	again, err := svc.FinishAttempt(ctx, attempt.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, result, again, "A repeated finish should return the original result")
+
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, scores, 1, "Only one score should be recorded")
+
+	// No more answers once finished
+	err = svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1})
+	assert.ErrorIs(t, err, ErrAttemptFinished)
+}
+
+func TestQuizService_SaveAnswer_Invalid(t *testing.T) {
+	svc, _ := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 42, Choice: 0}), ErrUnknownQuestion)
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 3}), ErrChoiceOutOfRange)
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, "missing", Answer{QuestionID: 1, Choice: 0}), ErrAttemptNotFound)
+}
+
+func TestQuizService_AttemptExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc, _ := newSeededService(t, arithmeticFixture, WithClock(clock.Now), WithAttemptTTL(10*time.Minute))
+	ctx := context.Background()
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(10*time.Minute), attempt.ExpiresAt)
+
+	clock.Advance(9 * time.Minute)
+	assert.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}), "The attempt is still open")
+
+	clock.Advance(time.Minute)
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}), ErrAttemptExpired)
+
+	_, err = svc.FinishAttempt(ctx, attempt.ID)
+	assert.ErrorIs(t, err, ErrAttemptExpired, "An expired attempt cannot be finished")
+}
+
+func TestQuizService_FinishAttempt_Concurrent(t *testing.T) {
+	svc, repo := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+
+	// Many finishes racing for the same attempt must record a single score
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.FinishAttempt(ctx, attempt.ID)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, scores, 1, "Concurrent finishes should record exactly one score")
+}
+
+// failingFinishRepository fails FinishAttempt after fn has run, as a failing score write would.
+type failingFinishRepository struct {
+	repository.Repository
+	err error
+}
+
+func (r *failingFinishRepository) FinishAttempt(ctx context.Context, id string, fn func(attempt *repository.Attempt) (repository.Result, error)) error {
+	return r.Repository.FinishAttempt(ctx, id, func(attempt *repository.Attempt) (repository.Result, error) {
+		result, err := fn(attempt)
+		if err != nil || r.err != nil {
+			return repository.Result{}, errors.Join(err, r.err)
+		}
+		return result, nil
+	})
+}
+
+func TestQuizService_FinishAttempt_ScoreFailure(t *testing.T) {
+	_, inner := newSeededService(t, arithmeticFixture)
+	failure := errors.New("disk full")
+	repo := &failingFinishRepository{Repository: inner, err: failure}
+	svc := NewQuizService(repo)
+	ctx := context.Background()
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+
+	// A score that cannot be stored leaves the attempt open, so it can be finished again
+	_, err = svc.FinishAttempt(ctx, attempt.ID)
+	assert.ErrorIs(t, err, failure)
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.False(t, resumed.Finished)
+	items, err := repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	assert.Empty(t, items, "No answers are stored without their score")
+
+	repo.err = nil
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, result.Score)
+
+	scores, err := repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	assert.Len(t, scores, 1)
+	items, err = repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	assert.Len(t, items, 2, "Both questions' answers are stored with the score")
+}
+
+func TestQuizService_AttemptBelongsToPlayer(t *testing.T) {
+	svc, repo := newSeededService(t, arithmeticFixture)
+	for _, id := range []string{"ada", "bob"} {
+		require.NoError(t, repo.AddUser(context.Background(), repository.User{ID: id, Name: id, TokenHash: id}))
+	}
+	ada := WithPlayer(context.Background(), "ada")
+
+	attempt, err := svc.StartAttempt(ada)
+	require.NoError(t, err)
+
+	// Neither another player nor an anonymous caller can use it
+	for _, ctx := range []context.Context{WithPlayer(context.Background(), "bob"), context.Background()} {
+		_, err = svc.GetAttempt(ctx, attempt.ID)
+		assert.ErrorIs(t, err, ErrAttemptNotFound)
+		_, err = svc.ServeQuestion(ctx, attempt.ID, 1)
+		assert.ErrorIs(t, err, ErrAttemptNotFound)
+		assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 0}), ErrAttemptNotFound)
+		_, err = svc.RevealHint(ctx, attempt.ID, 1)
+		assert.ErrorIs(t, err, ErrAttemptNotFound)
+		_, err = svc.FinishAttempt(ctx, attempt.ID)
+		assert.ErrorIs(t, err, ErrAttemptNotFound)
+		_, err = svc.ReviewAttempt(ctx, attempt.ID)
+		assert.ErrorIs(t, err, ErrAttemptNotFound)
+	}
+
+	// The owner's attempt is untouched
+	require.NoError(t, svc.SaveAnswer(ada, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+	result, err := svc.FinishAttempt(ada, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, result.Score)
+	_, err = svc.ReviewAttempt(ada, attempt.ID)
+	require.NoError(t, err)
+
+	scores, err := repo.GetScoresByUser(context.Background(), "ada")
+	require.NoError(t, err)
+	assert.Len(t, scores, 1)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Standing places a result among the other results of its quiz.
+type Standing struct {
+	Rank       int     `json:"rank"` // 1 for the best result; tied results share a rank
+	Of         int     `json:"of"`   // Results ranked, this one included
+	BetterThan int     `json:"better_than"`
+	TiedWith   int     `json:"tied_with"`
+	WorseThan  int     `json:"worse_than"`
+	Percentile float64 `json:"percentile"` // Mid-rank percentile: results below, plus half the ties, this one included
+}
+
+// newStanding ranks a result from the counts of the other results it beats, ties with and loses to.
+func newStanding(better, tied, worse int) Standing {
+	of := better + tied + worse + 1
+	return Standing{
+		Rank:       worse + 1,
+		Of:         of,
+		BetterThan: better,
+		TiedWith:   tied,
+		WorseThan:  worse,
+		Percentile: (float64(better) + float64(tied+1)/2) / float64(of) * 100,
+	}
+}
+
+// Message describes the standing to the player.
+func (s Standing) Message() string {
+	return fmt.Sprintf("You were better than %d, tied with %d and worse than %d other quizzers (rank %d of %d, %s percentile)",
+		s.BetterThan, s.TiedWith, s.WorseThan, s.Rank, s.Of, ordinal(int(math.Round(s.Percentile))))
+}
+
+// rankings holds the score distribution of every quiz compared since the service started. A quiz's
+// distribution is loaded from the repository once and kept up to date as its scores are recorded, so it
+// only sees the scores this process records: rankings are exact for a single server instance only.
+type rankings struct {
+	mu            sync.Mutex // Guards the map only; distributions are loaded under their own lock
+	distributions map[int]*rankedQuiz
+}
+
+// rankedQuiz guards the distribution of one quiz, so recording a score and counting it happen together.
+// The distribution is nil until it has been loaded.
+type rankedQuiz struct {
+	mu sync.Mutex
+	*distribution
+}
+
+func newRankings() *rankings {
+	return &rankings{distributions: map[int]*rankedQuiz{}}
+}
+
+// forget drops the distribution of a quiz, so it is loaded afresh if the quiz is used again.
+func (r *rankings) forget(quizID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.distributions, quizID)
+}
+
+// lockRankedQuiz returns the distribution of a quiz locked, loading it from the repository on first use;
+// the caller must unlock it. A failed load is retried by the next caller.
+func (q *QuizServiceImpl) lockRankedQuiz(ctx context.Context, quizID int) (*rankedQuiz, error) {
+	q.rankings.mu.Lock()
+	ranked, ok := q.rankings.distributions[quizID]
+	if !ok {
+		ranked = &rankedQuiz{}
+		q.rankings.distributions[quizID] = ranked
+	}
+	q.rankings.mu.Unlock()
+
+	// Load under the quiz's own lock, so a slow load only holds up results of the same quiz
+	ranked.mu.Lock()
+	if ranked.distribution == nil {
+		scores, err := q.repo.GetScoresByQuiz(ctx, quizID)
+		if err != nil {
+			ranked.mu.Unlock()
+			return nil, err
+		}
+		ranked.distribution = newDistribution(scores)
+	}
+	return ranked, nil
+}
+
+// recordResult stores a score with its answers and returns the score's standing among the quiz's earlier results.
+func (q *QuizServiceImpl) recordResult(ctx context.Context, result repository.Result) (Standing, error) {
+	record := result.Score
+	if record.QuizID == 0 {
+		record.QuizID = DefaultQuizID
+	}
+
+	ranked, err := q.lockRankedQuiz(ctx, record.QuizID)
+	if err != nil {
+		return Standing{}, err
+	}
+	defer ranked.mu.Unlock()
+
+	standing := newStanding(ranked.place(record))
+	if err := q.repo.AddResult(ctx, result); err != nil {
+		return Standing{}, err
+	}
+	ranked.add(record)
+	return standing, nil
+}
+
+// ordinal formats n as 1st, 2nd, 3rd, 4th and so on.
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
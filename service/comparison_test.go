@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestNewStanding(t *testing.T) {
+	// Ties count half towards the mid-rank percentile, the result itself included
+	assert.Equal(t, Standing{Rank: 2, Of: 5, BetterThan: 2, TiedWith: 1, WorseThan: 1, Percentile: 60}, newStanding(2, 1, 1))
+	assert.Equal(t, Standing{Rank: 1, Of: 1, Percentile: 50}, newStanding(0, 0, 0), "A lone result sits at the median")
+
+	assert.Equal(t, "You were better than 2, tied with 1 and worse than 1 other quizzers (rank 2 of 5, 60th percentile)",
+		newStanding(2, 1, 1).Message())
+	assert.Equal(t, []string{"1st", "2nd", "3rd", "4th", "11th", "12th", "13th", "21st", "100th"},
+		[]string{ordinal(1), ordinal(2), ordinal(3), ordinal(4), ordinal(11), ordinal(12), ordinal(13), ordinal(21), ordinal(100)})
+}
+
+func TestDistribution_Place(t *testing.T) {
+	d := newDistribution([]repository.ScoreRecord{
+		{Score: 5, Elapsed: 2 * time.Minute},
+		{Score: 5, Elapsed: 4 * time.Minute},
+		{Score: 5},
+		{Score: 3, Elapsed: time.Minute},
+		{Score: 8},
+	})
+
+	// Beats the lower score and the slower equal score; untimed results never lose a tie
+	better, tied, worse := d.place(repository.ScoreRecord{Score: 5, Elapsed: 3 * time.Minute})
+	assert.Equal(t, []int{2, 1, 2}, []int{better, tied, worse})
+
+	// An untimed result ties with every equal score
+	better, tied, worse = d.place(repository.ScoreRecord{Score: 5})
+	assert.Equal(t, []int{1, 3, 1}, []int{better, tied, worse})
+
+	better, tied, worse = d.place(repository.ScoreRecord{Score: 9})
+	assert.Equal(t, []int{5, 0, 0}, []int{better, tied, worse})
+}
+
+func TestDistribution_NormalisesScores(t *testing.T) {
+	// Results drawn from pools are worth different amounts; they compare by their share of the maximum
+	d := newDistribution([]repository.ScoreRecord{
+		{Score: 4, MaxScore: 5},
+		{Score: 6, MaxScore: 10},
+		{UserID: "a", Score: 3, MaxScore: 4},
+		{UserID: "a", Score: 7, MaxScore: 10},
+	})
+
+	better, tied, worse := d.place(repository.ScoreRecord{Score: 3.5, MaxScore: 5})
+	assert.Equal(t, []int{1, 0, 2}, []int{better, tied, worse}, "70% beats 60% and loses to 75% and 80%")
+	better, tied, worse = d.place(repository.ScoreRecord{Score: 6, MaxScore: 8})
+	assert.Equal(t, []int{1, 1, 1}, []int{better, tied, worse}, "Player a counts once, with their best share")
+}
+
+func TestDistribution_CountsPlayersOnce(t *testing.T) {
+	d := newDistribution([]repository.ScoreRecord{
+		{UserID: "a", Score: 1},
+		{Score: 2},
+		{UserID: "a", Score: 3, Elapsed: 20 * time.Second},
+		{UserID: "b", Score: 2},
+		{UserID: "a", Score: 3, Elapsed: 10 * time.Second},
+		{Score: 2},
+	})
+
+	// Each player keeps only their best result: a's 3 in 10s, b's 2 and both anonymous 2s
+	better, tied, worse := d.place(repository.ScoreRecord{Score: 2})
+	assert.Equal(t, []int{0, 3, 1}, []int{better, tied, worse})
+
+	// The player's own results are left out
+	better, tied, worse = d.place(repository.ScoreRecord{UserID: "a", Score: 2})
+	assert.Equal(t, []int{0, 3, 0}, []int{better, tied, worse})
+
+	// A worse retake does not replace the best result; a better one does
+	d.add(repository.ScoreRecord{UserID: "b", Score: 1})
+	better, _, _ = d.place(repository.ScoreRecord{Score: 1.5})
+	assert.Equal(t, 0, better)
+	d.add(repository.ScoreRecord{UserID: "b", Score: 4})
+	better, tied, worse = d.place(repository.ScoreRecord{Score: 2})
+	assert.Equal(t, []int{0, 2, 2}, []int{better, tied, worse})
+}
+
+func TestScoreTree_MatchesSortedSlice(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	var tree scoreTree
+	var keys []scoreKey
+
+	for i := 0; i < 2000; i++ {
+		key := scoreKey{float64(random.Intn(20)), time.Duration(random.Intn(5)) * time.Second}
+		if len(keys) > 0 && random.Intn(4) == 0 {
+			// Remove a key that is present
+			j := random.Intn(len(keys))
+			key = keys[j]
+			keys = append(keys[:j], keys[j+1:]...)
+			tree.remove(key)
+		} else {
+			keys = append(keys, key)
+			tree.insert(key)
+		}
+
+		probe := scoreKey{float64(random.Intn(21)), time.Duration(random.Intn(6)) * time.Second}
+		sort.Slice(keys, func(a, b int) bool { return compareKeys(keys[a], keys[b]) < 0 })
+		want := sort.Search(len(keys), func(j int) bool { return compareKeys(keys[j], probe) >= 0 })
+		require.Equal(t, want, tree.countLess(probe))
+		require.Equal(t, len(keys), tree.size())
+	}
+}
+
+func TestQuizService_Standing(t *testing.T) {
+	svc, _ := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	// The first result is ranked like any other, without a sentinel
+	response, err := svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, Standing{Rank: 1, Of: 1, Percentile: 50}, response.Standing)
+	assert.Equal(t, response.Standing.Message(), response.Comparison)
+
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 1}})
+	require.NoError(t, err)
+
+	response, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Standing.Rank, "Tied results share a rank")
+	assert.Equal(t, []int{0, 1, 1}, []int{response.Standing.BetterThan, response.Standing.TiedWith, response.Standing.WorseThan})
+	assert.InDelta(t, 100.0/3, response.Standing.Percentile, 1e-9)
+
+	// Attempts are ranked against the same distribution and keep their standing
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	_, err = svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	finished, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	require.NotNil(t, finished.Result)
+	assert.Equal(t, Standing{Rank: 4, Of: 4, WorseThan: 3, Percentile: 12.5}, finished.Result.Standing)
+}
+
+// gatedScoresRepository holds up loading one quiz's scores until its gate is closed, and can fail loads.
+type gatedScoresRepository struct {
+	repository.Repository
+	quizID  int
+	loading chan struct{}
+	gate    chan struct{}
+	err     error
+}
+
+func (r *gatedScoresRepository) GetScoresByQuiz(ctx context.Context, quizID int) ([]repository.ScoreRecord, error) {
+	if quizID == r.quizID {
+		close(r.loading)
+		<-r.gate
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.Repository.GetScoresByQuiz(ctx, quizID)
+}
+
+func TestQuizService_LockRankedQuiz(t *testing.T) {
+	ctx := context.Background()
+	repo := &gatedScoresRepository{Repository: repository.NewRepository(), quizID: 2, loading: make(chan struct{}), gate: make(chan struct{})}
+	svc := NewQuizService(repo).(*QuizServiceImpl)
+
+	// A slow load of one quiz does not hold up the others
+	loaded := make(chan error)
+	go func() {
+		ranked, err := svc.lockRankedQuiz(ctx, 2)
+		if err == nil {
+			ranked.mu.Unlock()
+		}
+		loaded <- err
+	}()
+	<-repo.loading
+
+	other := make(chan error)
+	go func() {
+		ranked, err := svc.lockRankedQuiz(ctx, DefaultQuizID)
+		if err == nil {
+			ranked.mu.Unlock()
+		}
+		other <- err
+	}()
+	select {
+	case err := <-other:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Loading another quiz waited for the slow one")
+	}
+	close(repo.gate)
+	require.NoError(t, <-loaded)
+
+	// A failed load is not cached
+	repo.quizID = 0
+	repo.err = errors.New("connection reset")
+	_, err := svc.lockRankedQuiz(ctx, 3)
+	assert.ErrorIs(t, err, repo.err)
+	repo.err = nil
+	ranked, err := svc.lockRankedQuiz(ctx, 3)
+	require.NoError(t, err)
+	assert.NotNil(t, ranked.distribution)
+	ranked.mu.Unlock()
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// distribution is the maintained score distribution of one quiz. A registered player counts once, with
+// their best result, and is never compared against themselves; anonymous results cannot be told apart
+// and each count. Adding a result and placing one are O(log n) in the number of results.
+type distribution struct {
+	results scoreTree
+	best    map[string]repository.ScoreRecord // User ID to the result counted for that player
+}
+
+// newDistribution builds the distribution of the stored results of a quiz.
+func newDistribution(records []repository.ScoreRecord) *distribution {
+	d := &distribution{best: map[string]repository.ScoreRecord{}}
+	for _, record := range records {
+		d.add(record)
+	}
+	return d
+}
+
+// add counts a new result. A player's result only replaces their best if it beats it.
+func (d *distribution) add(record repository.ScoreRecord) {
+	if record.UserID != "" {
+		best, seen := d.best[record.UserID]
+		if seen && !betterScore(record, best) {
+			return
+		}
+		if seen {
+			d.results.remove(keyOf(best))
+		}
+		d.best[record.UserID] = record
+	}
+	d.results.insert(keyOf(record))
+}
+
+// place counts the results the record beats, ties with and loses to, leaving out its player's own best.
+// A result beats another with a higher normalised score, or the same score in less time when both were timed.
+func (d *distribution) place(record repository.ScoreRecord) (better, tied, worse int) {
+	score, elapsed := normalizedScore(record), record.Elapsed
+
+	below := d.results.countLess(scoreKey{score, 0})
+	atOrBelow := d.results.countLess(scoreKey{score, math.MaxInt64})
+	better, tied, worse = below, atOrBelow-below, d.results.size()-atOrBelow
+
+	// Equal timed scores are told apart by elapsed time; untimed ones tie with every equal score
+	if elapsed > 0 {
+		slower := atOrBelow - d.results.countLess(scoreKey{score, elapsed + 1})
+		faster := d.results.countLess(scoreKey{score, elapsed}) - d.results.countLess(scoreKey{score, 1})
+		better += slower
+		worse += faster
+		tied -= slower + faster
+	}
+
+	if own, seen := d.best[record.UserID]; seen && record.UserID != "" {
+		switch {
+		case betterScore(record, own):
+			better--
+		case betterScore(own, record):
+			worse--
+		default:
+			tied--
+		}
+	}
+	return better, tied, worse
+}
+
+// scoreKey orders results by normalised score, then by elapsed time; untimed results have no elapsed time.
+type scoreKey struct {
+	score   float64
+	elapsed time.Duration
+}
+
+// keyOf returns the key a result is stored under.
+func keyOf(record repository.ScoreRecord) scoreKey {
+	return scoreKey{normalizedScore(record), record.Elapsed}
+}
+
+// normalizedScore is the score results are compared by: its share of the maximum, so attempts drawn from
+// pools compare fairly however much their questions were worth. Results stored before maxima were tracked
+// have nothing to normalise by and compare by their raw score.
+func normalizedScore(record repository.ScoreRecord) float64 {
+	if record.MaxScore > 0 {
+		return record.Score / record.MaxScore
+	}
+	return record.Score
+}
+
+// compareKeys returns -1, 0 or 1 as a sorts before, with or after b.
+func compareKeys(a, b scoreKey) int {
+	switch {
+	case a.score < b.score:
+		return -1
+	case a.score > b.score:
+		return 1
+	case a.elapsed < b.elapsed:
+		return -1
+	case a.elapsed > b.elapsed:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// scoreTree is a multiset of score keys that counts the keys below any key in O(log n).
+// It is a treap whose nodes know the size of their subtree.
+type scoreTree struct {
+	root *scoreNode
+}
+
+type scoreNode struct {
+	key         scoreKey
+	count       int // Copies of key
+	size        int // Keys in this subtree, copies included
+	priority    uint32
+	left, right *scoreNode
+}
+
+// size returns the number of keys in the tree.
+func (t *scoreTree) size() int {
+	return t.root.subtreeSize()
+}
+
+// insert adds one copy of key.
+func (t *scoreTree) insert(key scoreKey) {
+	t.root = t.root.insert(key)
+}
+
+// remove deletes one copy of key, if there is one.
+func (t *scoreTree) remove(key scoreKey) {
+	t.root = t.root.remove(key)
+}
+
+// countLess returns the number of keys that sort before key.
+func (t *scoreTree) countLess(key scoreKey) int {
+	n := 0
+	for node := t.root; node != nil; {
+		if compareKeys(key, node.key) <= 0 {
+			node = node.left
+		} else {
+			n += node.left.subtreeSize() + node.count
+			node = node.right
+		}
+	}
+	return n
+}
+
+func (n *scoreNode) subtreeSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *scoreNode) update() {
+	n.size = n.left.subtreeSize() + n.count + n.right.subtreeSize()
+}
+
+func (n *scoreNode) insert(key scoreKey) *scoreNode {
+	if n == nil {
+		return &scoreNode{key: key, count: 1, size: 1, priority: rand.Uint32()}
+	}
+
+	switch c := compareKeys(key, n.key); {
+	case c == 0:
+		n.count++
+	case c < 0:
+		n.left = n.left.insert(key)
+		if n.left.priority > n.priority {
+			n = n.rotateRight()
+		}
+	default:
+		n.right = n.right.insert(key)
+		if n.right.priority > n.priority {
+			n = n.rotateLeft()
+		}
+	}
+	n.update()
+	return n
+}
+
+func (n *scoreNode) remove(key scoreKey) *scoreNode {
+	if n == nil {
+		return nil
+	}
+
+	switch c := compareKeys(key, n.key); {
+	case c == 0:
+		if n.count == 1 {
+			return mergeNodes(n.left, n.right)
+		}
+		n.count--
+	case c < 0:
+		n.left = n.left.remove(key)
+	default:
+		n.right = n.right.remove(key)
+	}
+	n.update()
+	return n
+}
+
+func (n *scoreNode) rotateRight() *scoreNode {
+	left := n.left
+	n.left = left.right
+	n.update()
+	left.right = n
+	return left
+}
+
+func (n *scoreNode) rotateLeft() *scoreNode {
+	right := n.right
+	n.right = right.left
+	n.update()
+	right.left = n
+	return right
+}
+
+// mergeNodes joins two treaps whose keys all sort a before b.
+func mergeNodes(a, b *scoreNode) *scoreNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		a.right = mergeNodes(a.right, b)
+		a.update()
+		return a
+	default:
+		b.left = mergeNodes(a, b.left)
+		b.update()
+		return b
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Grading decides how many points a partly right answer earns. Which schemes apply depends on the question type.
+type Grading string
+
+const (
+	// GradingAllOrNothing awards the point only for an entirely right answer. It is the default for most types.
+	GradingAllOrNothing Grading = "all-or-nothing"
+	// GradingPartial awards a share of the point for every correct pick or pair; mistakes cost nothing.
+	// It is the default for matching questions.
+	GradingPartial Grading = "partial"
+	// GradingPenalty awards a share for every correct pick and takes one away for every wrong pick, down to zero.
+	GradingPenalty Grading = "penalty"
+	// GradingKendallTau awards the share of item pairs an ordering answer puts in the right relative order.
+	GradingKendallTau Grading = "kendall-tau"
+)
+
+// ToleranceMode decides how the tolerance of a numeric question is measured.
+type ToleranceMode string
+
+const (
+	// ToleranceAbsolute accepts answers at most Tolerance away from the answer. It is the default.
+	ToleranceAbsolute ToleranceMode = repository.ToleranceAbsolute
+	// ToleranceRelative accepts answers at most Tolerance times the answer away from it, e.g. 0.05 for 5%.
+	ToleranceRelative ToleranceMode = repository.ToleranceRelative
+)
+
+// ResultPartial is reported for an answer that earned some, but not all, of its point.
+const ResultPartial = "partial"
+
+// Errors returned when an answer does not fit its question.
+var (
+	ErrWrongAnswerType  = errors.New("answer does not match the question type")
+	ErrDuplicateChoice  = errors.New("alternative selected more than once")
+	ErrIncompleteAnswer = errors.New("answer must cover every item of the question")
+)
+
+// validateAnswerKey checks the parts of an answer key the repository cannot, such as the grading scheme.
+func validateAnswerKey(question Question) error {
+	kind, ok := typeOf(question)
+	if !ok {
+		return ErrInvalidQuestion
+	}
+	return kind.validateKey(question)
+}
+
+// validateAnswer checks that an answer is well formed for its question before it is graded or saved.
+// Only the field the question type reads may be set.
+func validateAnswer(question Question, answer Answer) error {
+	kind, ok := typeOf(question)
+	if !ok {
+		return ErrInvalidQuestion
+	}
+
+	for _, field := range answerFields(answer) {
+		if field != kind.answerField() {
+			return fmt.Errorf("%w: question %d takes %s", ErrWrongAnswerType, question.ID, kind.answerField())
+		}
+	}
+	return kind.validateAnswer(question, answer)
+}
+
+// answerFields names the JSON fields set on an answer, apart from choice, which always has a value.
+func answerFields(answer Answer) []string {
+	var fields []string
+	if answer.Choices != nil {
+		fields = append(fields, "choices")
+	}
+	if answer.Text != "" {
+		fields = append(fields, "text")
+	}
+	if answer.Number != nil {
+		fields = append(fields, "number")
+	}
+	if answer.Order != nil {
+		fields = append(fields, "order")
+	}
+	if answer.Matches != nil {
+		fields = append(fields, "matches")
+	}
+	return fields
+}
+
+// gradeAnswers grades the answers (question ID to answer) and reports how every question was graded, in order.
+// Each question earns up to one point; some grading schemes award a fraction of it, and every hint revealed
+// (question ID to count) takes its penalty off. The status reflects the answer alone. The quiz's Scorer turns
+// the results into a score.
+func gradeAnswers(questions []Question, answers map[int]Answer, hints map[int]int) []QuestionResult {
+	results := make([]QuestionResult, 0, len(questions))
+
+	for _, question := range questions {
+		result := QuestionResult{QuestionID: question.ID, Status: ResultMissing, Hints: hints[question.ID]}
+		if answer, answered := answers[question.ID]; answered {
+			points := gradeQuestion(question, answer)
+			result.Status = resultStatus(points)
+			result.Points = points * hintShare(question, result.Hints)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// gradeQuestion returns the points, between 0 and 1, an answer earns on its question.
+func gradeQuestion(question Question, answer Answer) float64 {
+	kind, ok := typeOf(question)
+	if !ok {
+		return 0
+	}
+	return kind.grade(question, answer)
+}
+
+// validateGrading rejects a grading scheme the question type does not offer; empty picks the type's default.
+func validateGrading(question Question, offered ...Grading) error {
+	if question.Grading == "" {
+		return nil
+	}
+	for _, grading := range offered {
+		if question.Grading == grading {
+			return nil
+		}
+	}
+	return ErrInvalidQuestion
+}
+
+// validateIndices checks that every index picks one of n alternatives, each at most once.
+func validateIndices(question Question, indices []int, n int) error {
+	seen := make(map[int]bool, len(indices))
+	for _, index := range indices {
+		if index < 0 || index >= n {
+			return fmt.Errorf("%w: question %d has %d alternatives", ErrChoiceOutOfRange, question.ID, n)
+		}
+		if seen[index] {
+			return fmt.Errorf("%w: question %d, alternative %d", ErrDuplicateChoice, question.ID, index)
+		}
+		seen[index] = true
+	}
+	return nil
+}
+
+// points converts a right or wrong answer to the point it earns.
+func points(correct bool) float64 {
+	if correct {
+		return 1
+	}
+	return 0
+}
+
+// resultStatus names the outcome of an answer that earned points.
+func resultStatus(points float64) string {
+	switch {
+	case points >= 1:
+		return ResultCorrect
+	case points > 0:
+		return ResultPartial
+	default:
+		return ResultIncorrect
+	}
+}
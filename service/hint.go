@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// ErrNoMoreHints is returned when every hint of a question has already been revealed.
+var ErrNoMoreHints = errors.New("no more hints for this question")
+
+// Hint is one revealed hint of a question in an attempt.
+type Hint struct {
+	QuestionID int    `json:"question_id"`
+	Number     int    `json:"number"` // 1 for the first hint
+	Text       string `json:"text"`
+	TextHTML   string `json:"text_html,omitempty"` // The text rendered from Markdown to sanitised HTML
+	Remaining  int    `json:"remaining"`
+	// Penalty is the share of the question's point the hints revealed so far take off
+	Penalty float64 `json:"penalty"`
+}
+
+// RevealHint reveals the next hint of a question in an open attempt and records it on the attempt,
+// so grading the attempt takes the hint's penalty off the question's points.
+func (q *QuizServiceImpl) RevealHint(ctx context.Context, attemptID string, questionID int) (Hint, error) {
+	_, question, err := q.attemptQuestion(ctx, attemptID, questionID)
+	if err != nil {
+		return Hint{}, err
+	}
+
+	now := q.now()
+	var revealed int
+	err = q.repo.UpdateAttempt(ctx, attemptID, func(stored *repository.Attempt) error {
+		if err := checkOpen(*stored, now); err != nil {
+			return err
+		}
+		if questionTimeUp(*stored, question, now) {
+			return ErrDeadlinePassed
+		}
+		if stored.Hints[questionID] >= len(question.Hints) {
+			return ErrNoMoreHints
+		}
+
+		if stored.Hints == nil {
+			stored.Hints = map[int]int{}
+		}
+		stored.Hints[questionID]++
+		revealed = stored.Hints[questionID]
+		return nil
+	})
+	if err != nil {
+		return Hint{}, q.handleLate(ctx, attemptID, now, err)
+	}
+
+	return toHint(fromRepositoryQuestion(question), revealed), nil
+}
+
+// revealedHints lists the hints of a question revealed in an attempt, in order.
+func revealedHints(attempt repository.Attempt, question Question) []Hint {
+	count := attempt.Hints[question.ID]
+	if count > len(question.Hints) {
+		count = len(question.Hints) // Hints removed since they were revealed
+	}
+	hints := make([]Hint, 0, count)
+	for number := 1; number <= count; number++ {
+		hints = append(hints, toHint(question, number))
+	}
+	return hints
+}
+
+// toHint returns a question's hint by its 1-based number.
+func toHint(question Question, number int) Hint {
+	return Hint{
+		QuestionID: question.ID,
+		Number:     number,
+		Text:       question.Hints[number-1],
+		TextHTML:   renderMarkdown(question.Hints[number-1]),
+		Remaining:  len(question.Hints) - number,
+		Penalty:    math.Min(1, float64(number)*question.HintPenalty),
+	}
+}
+
+// hintShare returns the share of a question's points still on offer after revealing the given number of hints.
+func hintShare(question Question, revealed int) float64 {
+	return math.Max(0, 1-float64(revealed)*question.HintPenalty)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuizService_RevealHint(t *testing.T) {
+	svc, _ := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	hints := []string{"It is even", "It is less than 3"}
+	penalty := 0.25
+	_, err := svc.PatchQuestion(ctx, 1, QuestionPatch{Hints: &hints, HintPenalty: &penalty})
+	require.NoError(t, err)
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	first := attempt.Questions[indexOfQuestion(attempt.Questions, 1)]
+	assert.Equal(t, 2, first.HintCount)
+	assert.Equal(t, 0.25, first.HintPenalty)
+
+	// Hints come one at a time, in the author's order, until none are left
+	hint, err := svc.RevealHint(ctx, attempt.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Hint{QuestionID: 1, Number: 1, Text: "It is even", TextHTML: "<p>It is even</p>", Remaining: 1, Penalty: 0.25}, hint)
+	hint, err = svc.RevealHint(ctx, attempt.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, Hint{QuestionID: 1, Number: 2, Text: "It is less than 3", TextHTML: "<p>It is less than 3</p>", Penalty: 0.5}, hint)
+	_, err = svc.RevealHint(ctx, attempt.ID, 1)
+	assert.ErrorIs(t, err, ErrNoMoreHints)
+	_, err = svc.RevealHint(ctx, attempt.ID, 2)
+	assert.ErrorIs(t, err, ErrNoMoreHints, "Questions without hints have none to reveal")
+	_, err = svc.RevealHint(ctx, attempt.ID, 9)
+	assert.ErrorIs(t, err, ErrUnknownQuestion)
+
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Len(t, resumed.Hints, 2, "The attempt keeps the revealed hints")
+
+	// Each revealed hint takes its penalty off the question's point; the answer is still correct
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}))
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, result.Score)
+	assert.Contains(t, result.Results, QuestionResult{QuestionID: 1, Status: ResultCorrect, Points: 0.5, Hints: 2})
+
+	_, err = svc.RevealHint(ctx, attempt.ID, 1)
+	assert.ErrorIs(t, err, ErrAttemptFinished)
+
+	bad := 1.5
+	_, err = svc.PatchQuestion(ctx, 1, QuestionPatch{HintPenalty: &bad})
+	assert.ErrorIs(t, err, ErrInvalidQuestion)
+}
+
+func TestGradeAnswers_Hints(t *testing.T) {
+	questions := []Question{{ID: 1, Alternatives: []string{"a", "b"}, CorrectAnswer: 0, Hints: []string{"x", "y", "z"}, HintPenalty: 0.4}}
+
+	results := gradeAnswers(questions, map[int]Answer{1: {QuestionID: 1, Choice: 0}}, map[int]int{1: 3})
+	assert.Equal(t, []QuestionResult{{QuestionID: 1, Status: ResultCorrect, Points: 0, Hints: 3}}, results, "Penalties never take a question below zero")
+}
+
+func TestQuizService_RevealHint_Concurrent(t *testing.T) {
+	// Run with -race to detect reveals leaking into attempts read elsewhere
+	svc, _ := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	const workers = 20
+	hints := make([]string, workers)
+	for i := range hints {
+		hints[i] = "Think again"
+	}
+	_, err := svc.PatchQuestion(ctx, 1, QuestionPatch{Hints: &hints})
+	require.NoError(t, err)
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := svc.RevealHint(ctx, attempt.ID, 1)
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			resumed, err := svc.GetAttempt(ctx, attempt.ID)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(resumed.Hints), workers)
+		}()
+	}
+	wg.Wait()
+
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Len(t, resumed.Hints, workers, "Every concurrent reveal should be recorded once")
+	_, err = svc.RevealHint(ctx, attempt.ID, 1)
+	assert.ErrorIs(t, err, ErrNoMoreHints)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Item flags mark questions whose answers tell players apart poorly.
+const (
+	ItemNobodyCorrect    = "nobody-correct"
+	ItemEverybodyCorrect = "everybody-correct"
+)
+
+// ItemAnalysis is the classical item statistics of one question, from every recorded answer to it.
+type ItemAnalysis struct {
+	QuestionID int    `json:"question_id"`
+	Question   string `json:"question"`
+	Type       string `json:"type,omitempty"`
+	Responses  int    `json:"responses"` // Results that included the question, unanswered or not
+	Correct    int    `json:"correct"`
+	// Difficulty is the p-value: the mean points earned, from 0 (nobody scored) to 1 (everybody did).
+	Difficulty float64 `json:"difficulty"`
+	// Discrimination is the point-biserial correlation between the points earned on the question and the
+	// percentage the whole result scored. Good questions correlate positively; zero if either never varied.
+	Discrimination float64               `json:"discrimination"`
+	Distractors    []DistractorFrequency `json:"distractors,omitempty"` // Choice questions only
+	Flag           string                `json:"flag,omitempty"`        // ItemNobodyCorrect or ItemEverybodyCorrect
+}
+
+// DistractorFrequency reports how often one alternative of a choice question was picked.
+type DistractorFrequency struct {
+	Choice      int     `json:"choice"`
+	Alternative string  `json:"alternative"`
+	Correct     bool    `json:"correct"`
+	Picks       int     `json:"picks"`
+	Frequency   float64 `json:"frequency"` // Picks per response
+}
+
+// GetItemAnalysis reports the item statistics of every question of a quiz, in question order. The repository
+// aggregates the recorded answers; questions nobody has answered yet report zero responses.
+func (q *QuizServiceImpl) GetItemAnalysis(ctx context.Context, quizID int) ([]ItemAnalysis, error) {
+	_, questions, err := q.loadQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := q.repo.GetItemStats(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	byQuestion := make(map[int]repository.ItemStats, len(stats))
+	for _, item := range stats {
+		byQuestion[item.QuestionID] = item
+	}
+
+	items := make([]ItemAnalysis, 0, len(questions))
+	for _, question := range questions {
+		item := byQuestion[question.ID]
+		analysis := ItemAnalysis{
+			QuestionID:     question.ID,
+			Question:       question.Question,
+			Type:           question.Type,
+			Responses:      item.Responses,
+			Correct:        item.Correct,
+			Difficulty:     item.MeanPoints,
+			Discrimination: item.Discrimination,
+			Distractors:    distractors(question, item),
+		}
+		switch {
+		case item.Responses == 0:
+		case item.Correct == 0:
+			analysis.Flag = ItemNobodyCorrect
+		case item.Correct == item.Responses:
+			analysis.Flag = ItemEverybodyCorrect
+		}
+		items = append(items, analysis)
+	}
+	return items, nil
+}
+
+// distractors reports the picks of every alternative of a choice question; other types have none.
+func distractors(question Question, item repository.ItemStats) []DistractorFrequency {
+	kind, ok := typeOf(question)
+	if !ok || kind.keyedChoices(question) == nil {
+		return nil
+	}
+
+	correct := map[int]bool{}
+	for _, choice := range kind.keyedChoices(question) {
+		correct[choice] = true
+	}
+
+	frequencies := make([]DistractorFrequency, 0, len(question.Alternatives))
+	for i, alternative := range question.Alternatives {
+		frequency := DistractorFrequency{Choice: i, Alternative: alternative, Correct: correct[i], Picks: item.ChoiceCounts[i]}
+		if item.Responses > 0 {
+			frequency.Frequency = float64(frequency.Picks) / float64(item.Responses)
+		}
+		frequencies = append(frequencies, frequency)
+	}
+	return frequencies
+}
+
+// answerRecords returns every graded answer of a result, to be stored with its score for item analysis.
+func answerRecords(quizID int, questions []Question, answers map[int]Answer,
+	results []QuestionResult, score, maxScore float64, recordedAt time.Time) []repository.AnswerRecord {
+	records := make([]repository.AnswerRecord, 0, len(results))
+	for i, result := range results {
+		record := repository.AnswerRecord{
+			QuizID:     quizID,
+			QuestionID: result.QuestionID,
+			Correct:    result.Status == ResultCorrect,
+			Points:     result.Points,
+			Total:      percentage(score, maxScore) / 100,
+			RecordedAt: recordedAt,
+		}
+		if answer, answered := answers[result.QuestionID]; answered {
+			if kind, ok := typeOf(questions[i]); ok {
+				record.Choices = kind.pickedChoices(answer)
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
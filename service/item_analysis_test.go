@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestQuizService_GetItemAnalysis(t *testing.T) {
+	svc, repo := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	for _, question := range []repository.Question{
+		{ID: 3, Type: QuestionTypeShortAnswer, QuestionText: "What is the capital of France?", AcceptedAnswers: []string{"Paris"}},
+		{ID: 4, QuestionText: "What is 3 + 3?", Alternatives: []string{"6", "7"}, CorrectAnswer: 0},
+	} {
+		require.NoError(t, repo.AddQuestion(ctx, question))
+	}
+
+	// Question 1 is always right, question 4 never answered; question 2 is right on the best results
+	for _, answers := range [][]Answer{
+		{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 1}, {QuestionID: 3, Text: "Paris"}},
+		{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 0}},
+		{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 2}, {QuestionID: 3, Text: "Lyon"}},
+	} {
+		_, err := svc.SubmitAnswersByID(ctx, answers)
+		require.NoError(t, err)
+	}
+
+	// Finished attempts are recorded too
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}))
+	_, err = svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.AddQuestion(ctx, repository.Question{ID: 5, QuestionText: "New?", Alternatives: []string{"Yes", "No"}}))
+
+	items, err := svc.GetItemAnalysis(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+
+	assert.Equal(t, 4, items[0].Responses)
+	assert.Equal(t, 1.0, items[0].Difficulty)
+	assert.Equal(t, 0.0, items[0].Discrimination, "A question everybody gets right cannot discriminate")
+	assert.Equal(t, ItemEverybodyCorrect, items[0].Flag)
+
+	assert.Equal(t, 2, items[1].Correct)
+	assert.Equal(t, 0.5, items[1].Difficulty)
+	assert.InDelta(t, 0.9045, items[1].Discrimination, 1e-4)
+	assert.Empty(t, items[1].Flag)
+	assert.Equal(t, []DistractorFrequency{
+		{Choice: 0, Alternative: "3", Picks: 1, Frequency: 0.25},
+		{Choice: 1, Alternative: "4", Correct: true, Picks: 2, Frequency: 0.5},
+		{Choice: 2, Alternative: "5", Picks: 1, Frequency: 0.25},
+	}, items[1].Distractors)
+
+	assert.Equal(t, 0.25, items[2].Difficulty)
+	assert.Nil(t, items[2].Distractors, "Only choice questions have distractors")
+
+	assert.Equal(t, 4, items[3].Responses, "Unanswered questions count as responses")
+	assert.Equal(t, ItemNobodyCorrect, items[3].Flag)
+	assert.Equal(t, 0, items[3].Distractors[1].Picks)
+
+	assert.Equal(t, ItemAnalysis{
+		QuestionID: 5, Question: "New?",
+		Distractors: []DistractorFrequency{{Choice: 0, Alternative: "Yes", Correct: true}, {Choice: 1, Alternative: "No"}},
+	}, items[4], "Questions nobody has answered report no statistics and no flag")
+
+	_, err = svc.GetItemAnalysis(ctx, 999)
+	assert.ErrorIs(t, err, ErrQuizNotFound)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// LeaderboardWindow names the period of results a leaderboard ranks.
+type LeaderboardWindow string
+
+const (
+	// WindowAllTime ranks every result. It is the default.
+	WindowAllTime LeaderboardWindow = "all-time"
+	// WindowDaily ranks the results of the last 24 hours.
+	WindowDaily LeaderboardWindow = "daily"
+	// WindowWeekly ranks the results of the last 7 days.
+	WindowWeekly LeaderboardWindow = "weekly"
+)
+
+// TieBreak names the rule that orders players with equal scores.
+type TieBreak string
+
+const (
+	// TieBreakEarliest puts whoever reached the score first ahead. It is the default.
+	TieBreakEarliest TieBreak = "earliest"
+	// TieBreakFastest puts whoever took the least time ahead; untimed results come after timed ones,
+	// then the earliest goes first.
+	TieBreakFastest TieBreak = "fastest"
+)
+
+// DefaultLeaderboardLimit is the number of entries a leaderboard shows when the query sets no limit.
+const DefaultLeaderboardLimit = 10
+
+// LeaderboardQuery selects the part of a quiz's leaderboard to return.
+type LeaderboardQuery struct {
+	Window   LeaderboardWindow
+	TieBreak TieBreak
+	Limit    int  // Entries to return; zero means DefaultLeaderboardLimit
+	AroundMe bool // Return the entries around the calling player instead of the top ones
+}
+
+// Leaderboard ranks the registered players of one quiz by their best result in a window.
+type Leaderboard struct {
+	QuizID   int                `json:"quiz_id"`
+	Window   LeaderboardWindow  `json:"window"`
+	TieBreak TieBreak           `json:"tie_break"`
+	Since    *time.Time         `json:"since,omitempty"` // Start of the window; unset for all-time
+	Players  int                `json:"players"`         // Players ranked, including those not returned
+	Entries  []LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardEntry is one player's place on a leaderboard.
+type LeaderboardEntry struct {
+	Rank           int       `json:"rank"` // Players the tie-break cannot tell apart share a rank
+	PlayerID       string    `json:"player_id"`
+	PlayerName     string    `json:"player_name"`
+	Score          float64   `json:"score"`
+	MaxScore       float64   `json:"max_score"`
+	ElapsedSeconds float64   `json:"elapsed_seconds,omitempty"`
+	RecordedAt     time.Time `json:"recorded_at"`
+}
+
+// Errors returned when querying leaderboards.
+var (
+	ErrInvalidLeaderboard = errors.New("invalid leaderboard query")
+	ErrNotOnLeaderboard   = errors.New("player has no result on this leaderboard")
+)
+
+// GetLeaderboard ranks the best result of every registered player of a quiz. Anonymous results are left out.
+// Around-me queries need an identified player who has a result in the window.
+func (q *QuizServiceImpl) GetLeaderboard(ctx context.Context, quizID int, query LeaderboardQuery) (Leaderboard, error) {
+	query, err := normalizeLeaderboardQuery(query)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	playerID, identified := PlayerFromContext(ctx)
+	if query.AroundMe && !identified {
+		return Leaderboard{}, ErrIdentityRequired
+	}
+
+	if _, err := q.repo.GetQuiz(ctx, quizID); err != nil {
+		return Leaderboard{}, err
+	}
+	records, err := q.repo.GetScoresByQuiz(ctx, quizID)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+
+	board := Leaderboard{QuizID: quizID, Window: query.Window, TieBreak: query.TieBreak}
+	if since, windowed := windowStart(query.Window, q.now()); windowed {
+		board.Since = &since
+	}
+
+	ahead := tieBreakRule(query.TieBreak)
+	ranked := bestByPlayer(records, board.Since, ahead)
+	board.Players = len(ranked)
+
+	from, to := 0, query.Limit
+	if query.AroundMe {
+		me := -1
+		for i, record := range ranked {
+			if record.UserID == playerID {
+				me = i
+				break
+			}
+		}
+		if me < 0 {
+			return Leaderboard{}, ErrNotOnLeaderboard
+		}
+		from = me - (query.Limit-1)/2
+		if from > len(ranked)-query.Limit {
+			from = len(ranked) - query.Limit
+		}
+		if from < 0 {
+			from = 0
+		}
+		to = from + query.Limit
+	}
+	if to > len(ranked) {
+		to = len(ranked)
+	}
+
+	board.Entries = make([]LeaderboardEntry, 0, to-from)
+	rank := 0
+	for i, record := range ranked[:to] {
+		if i == 0 || ahead(ranked[i-1], record) {
+			rank = i + 1
+		}
+		if i < from {
+			continue
+		}
+
+		user, err := q.repo.GetUser(ctx, record.UserID)
+		if err != nil {
+			return Leaderboard{}, err
+		}
+		board.Entries = append(board.Entries, LeaderboardEntry{
+			Rank:           rank,
+			PlayerID:       record.UserID,
+			PlayerName:     user.Name,
+			Score:          record.Score,
+			MaxScore:       record.MaxScore,
+			ElapsedSeconds: record.Elapsed.Seconds(),
+			RecordedAt:     record.RecordedAt,
+		})
+	}
+	return board, nil
+}
+
+// normalizeLeaderboardQuery fills in the defaults and rejects unknown settings.
+func normalizeLeaderboardQuery(query LeaderboardQuery) (LeaderboardQuery, error) {
+	switch query.Window {
+	case "":
+		query.Window = WindowAllTime
+	case WindowAllTime, WindowDaily, WindowWeekly:
+	default:
+		return query, fmt.Errorf("%w: unknown window %q", ErrInvalidLeaderboard, query.Window)
+	}
+
+	switch query.TieBreak {
+	case "":
+		query.TieBreak = TieBreakEarliest
+	case TieBreakEarliest, TieBreakFastest:
+	default:
+		return query, fmt.Errorf("%w: unknown tie-break %q", ErrInvalidLeaderboard, query.TieBreak)
+	}
+
+	switch {
+	case query.Limit < 0:
+		return query, fmt.Errorf("%w: limit must not be negative", ErrInvalidLeaderboard)
+	case query.Limit == 0:
+		query.Limit = DefaultLeaderboardLimit
+	}
+	return query, nil
+}
+
+// windowStart returns when a rolling window opened, or false for the all-time window.
+func windowStart(window LeaderboardWindow, now time.Time) (time.Time, bool) {
+	switch window {
+	case WindowDaily:
+		return now.Add(-24 * time.Hour), true
+	case WindowWeekly:
+		return now.Add(-7 * 24 * time.Hour), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// tieBreakRule returns a function reporting whether result a ranks ahead of result b under the rule.
+// Results are ranked by normalizedScore, as comparisons are, so results out of different maxima compare fairly.
+func tieBreakRule(rule TieBreak) func(a, b repository.ScoreRecord) bool {
+	return func(a, b repository.ScoreRecord) bool {
+		if scoreA, scoreB := normalizedScore(a), normalizedScore(b); scoreA != scoreB {
+			return scoreA > scoreB
+		}
+		if rule == TieBreakFastest && a.Elapsed != b.Elapsed {
+			switch {
+			case a.Elapsed == 0:
+				return false
+			case b.Elapsed == 0:
+				return true
+			default:
+				return a.Elapsed < b.Elapsed
+			}
+		}
+		return a.RecordedAt.Before(b.RecordedAt)
+	}
+}
+
+// bestByPlayer keeps each registered player's best result since the given time, best player first.
+func bestByPlayer(records []repository.ScoreRecord, since *time.Time, ahead func(a, b repository.ScoreRecord) bool) []repository.ScoreRecord {
+	best := map[string]repository.ScoreRecord{}
+	for _, record := range records {
+		if record.UserID == "" || (since != nil && record.RecordedAt.Before(*since)) {
+			continue
+		}
+		if current, seen := best[record.UserID]; !seen || ahead(record, current) {
+			best[record.UserID] = record
+		}
+	}
+
+	ranked := make([]repository.ScoreRecord, 0, len(best))
+	for _, record := range best {
+		ranked = append(ranked, record)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ahead(ranked[i], ranked[j]) || ahead(ranked[j], ranked[i]) {
+			return ahead(ranked[i], ranked[j])
+		}
+		return ranked[i].UserID < ranked[j].UserID // keep entries the rule cannot tell apart in a stable order
+	})
+	return ranked
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// leaderboardNow is the time the leaderboard tests rank at.
+var leaderboardNow = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// leaderboardFixture holds five players' results on the default quiz, the oldest over a week ago.
+var leaderboardFixture = testFixture{
+	Users: []repository.User{
+		{ID: "ada", Name: "Player ada", TokenHash: "ada"},
+		{ID: "bob", Name: "Player bob", TokenHash: "bob"},
+		{ID: "cy", Name: "Player cy", TokenHash: "cy"},
+		{ID: "dee", Name: "Player dee", TokenHash: "dee"},
+		{ID: "eve", Name: "Player eve", TokenHash: "eve"},
+	},
+	Scores: []repository.ScoreRecord{
+		{UserID: "ada", Score: 9, MaxScore: 10, RecordedAt: leaderboardNow.Add(-10 * 24 * time.Hour)},
+		{UserID: "ada", Score: 4, MaxScore: 10, Elapsed: 50 * time.Second, RecordedAt: leaderboardNow.Add(-time.Hour)},
+		{UserID: "bob", Score: 7, MaxScore: 10, RecordedAt: leaderboardNow.Add(-3 * time.Hour)},
+		{UserID: "cy", Score: 7, MaxScore: 10, Elapsed: 40 * time.Second, RecordedAt: leaderboardNow.Add(-2 * time.Hour)},
+		{UserID: "dee", Score: 7, MaxScore: 10, Elapsed: 30 * time.Second, RecordedAt: leaderboardNow.Add(-3 * 24 * time.Hour)},
+		{UserID: "eve", Score: 2, MaxScore: 10, RecordedAt: leaderboardNow.Add(-30 * time.Minute)},
+		{Score: 10, MaxScore: 10, RecordedAt: leaderboardNow.Add(-time.Minute)},
+	},
+}
+
+// leaderboardClock stops the service's clock at leaderboardNow.
+func leaderboardClock() time.Time { return leaderboardNow }
+
+// playerIDs lists the entries' players in order.
+func playerIDs(board Leaderboard) []string {
+	ids := make([]string, 0, len(board.Entries))
+	for _, entry := range board.Entries {
+		ids = append(ids, entry.PlayerID)
+	}
+	return ids
+}
+
+func TestQuizService_GetLeaderboard(t *testing.T) {
+	svc, _ := newSeededService(t, leaderboardFixture, WithClock(leaderboardClock))
+	now := leaderboardNow
+	ctx := context.Background()
+
+	// All-time keeps each player's best result and leaves out anonymous ones; earliest wins a tie
+	board, err := svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, WindowAllTime, board.Window)
+	assert.Equal(t, TieBreakEarliest, board.TieBreak)
+	assert.Nil(t, board.Since)
+	assert.Equal(t, 5, board.Players)
+	assert.Equal(t, []string{"ada", "dee", "bob", "cy", "eve"}, playerIDs(board))
+	assert.Equal(t, LeaderboardEntry{
+		Rank: 1, PlayerID: "ada", PlayerName: "Player ada", Score: 9, MaxScore: 10, RecordedAt: now.Add(-10 * 24 * time.Hour),
+	}, board.Entries[0])
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ranks(board))
+
+	// Fastest puts timed results ahead, quickest first
+	board, err = svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{TieBreak: TieBreakFastest})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ada", "dee", "cy", "bob", "eve"}, playerIDs(board))
+
+	// Rolling windows only rank the results recorded in them
+	board, err = svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{Window: WindowWeekly, Limit: 2})
+	require.NoError(t, err)
+	require.NotNil(t, board.Since)
+	assert.Equal(t, now.Add(-7*24*time.Hour), *board.Since)
+	assert.Equal(t, 5, board.Players)
+	assert.Equal(t, []string{"dee", "bob"}, playerIDs(board))
+
+	board, err = svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{Window: WindowDaily})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "cy", "ada", "eve"}, playerIDs(board))
+	assert.Equal(t, 4.0, board.Entries[2].Score, "Only results inside the window count towards a player's best")
+	assert.Equal(t, 50.0, board.Entries[2].ElapsedSeconds)
+}
+
+func TestQuizService_GetLeaderboard_AroundMe(t *testing.T) {
+	svc, _ := newSeededService(t, leaderboardFixture, WithClock(leaderboardClock))
+	ctx := context.Background()
+
+	_, err := svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{AroundMe: true})
+	assert.ErrorIs(t, err, ErrIdentityRequired)
+
+	board, err := svc.GetLeaderboard(WithPlayer(ctx, "bob"), DefaultQuizID, LeaderboardQuery{AroundMe: true, Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dee", "bob", "cy"}, playerIDs(board))
+	assert.Equal(t, []int{2, 3, 4}, ranks(board))
+
+	// The window is clamped to the ends of the board
+	board, err = svc.GetLeaderboard(WithPlayer(ctx, "eve"), DefaultQuizID, LeaderboardQuery{AroundMe: true, Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "cy", "eve"}, playerIDs(board))
+
+	board, err = svc.GetLeaderboard(WithPlayer(ctx, "ada"), DefaultQuizID, LeaderboardQuery{AroundMe: true, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ada", "dee"}, playerIDs(board))
+
+	_, err = svc.GetLeaderboard(WithPlayer(ctx, "nobody"), DefaultQuizID, LeaderboardQuery{AroundMe: true})
+	assert.ErrorIs(t, err, ErrNotOnLeaderboard)
+}
+
+func TestQuizService_GetLeaderboard_SharedRanks(t *testing.T) {
+	ctx := context.Background()
+	now := leaderboardNow
+	svc, _ := newSeededService(t, testFixture{
+		Users: []repository.User{{ID: "a", Name: "a", TokenHash: "a"}, {ID: "b", Name: "b", TokenHash: "b"}, {ID: "c", Name: "c", TokenHash: "c"}},
+		Scores: []repository.ScoreRecord{
+			{UserID: "a", Score: 5, RecordedAt: now},
+			{UserID: "b", Score: 5, RecordedAt: now},
+			{UserID: "c", Score: 3, RecordedAt: now},
+		},
+	}, WithClock(leaderboardClock))
+
+	// Results the tie-break cannot tell apart share a rank
+	board, err := svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{TieBreak: TieBreakFastest})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, playerIDs(board))
+	assert.Equal(t, []int{1, 1, 3}, ranks(board))
+}
+
+func TestQuizService_GetLeaderboard_DifferentMaxScores(t *testing.T) {
+	ctx := context.Background()
+	now := leaderboardNow
+	svc, _ := newSeededService(t, testFixture{
+		Users: []repository.User{{ID: "a", Name: "a", TokenHash: "a"}, {ID: "b", Name: "b", TokenHash: "b"}, {ID: "c", Name: "c", TokenHash: "c"}},
+		Scores: []repository.ScoreRecord{
+			{UserID: "a", Score: 6, MaxScore: 10, RecordedAt: now},
+			{UserID: "a", Score: 5, MaxScore: 5, RecordedAt: now.Add(-time.Hour)}, // Fewer points, but a's best
+			{UserID: "b", Score: 4, MaxScore: 5, RecordedAt: now},
+			{UserID: "c", Score: 7, MaxScore: 20, RecordedAt: now},
+		},
+	}, WithClock(leaderboardClock))
+
+	// Pooled attempts are worth different points, so results rank by their share of the maximum
+	board, err := svc.GetLeaderboard(ctx, DefaultQuizID, LeaderboardQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, playerIDs(board))
+	assert.Equal(t, []int{1, 2, 3}, ranks(board))
+	assert.Equal(t, []float64{5, 4, 7}, []float64{board.Entries[0].Score, board.Entries[1].Score, board.Entries[2].Score})
+}
+
+func TestQuizService_GetLeaderboard_InvalidQuery(t *testing.T) {
+	svc, _ := newSeededService(t, leaderboardFixture, WithClock(leaderboardClock))
+	ctx := context.Background()
+
+	for _, query := range []LeaderboardQuery{{Window: "monthly"}, {TieBreak: "alphabetical"}, {Limit: -1}} {
+		_, err := svc.GetLeaderboard(ctx, DefaultQuizID, query)
+		assert.ErrorIs(t, err, ErrInvalidLeaderboard, "%+v", query)
+	}
+
+	_, err := svc.GetLeaderboard(ctx, 999, LeaderboardQuery{})
+	assert.ErrorIs(t, err, ErrQuizNotFound)
+}
+
+// ranks lists the entries' ranks in order.
+func ranks(board Leaderboard) []int {
+	out := make([]int, 0, len(board.Entries))
+	for _, entry := range board.Entries {
+		out = append(out, entry.Rank)
+	}
+	return out
+}
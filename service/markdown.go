@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Question text, alternatives, prompts, hints, explanations and feedback are Markdown. Players get them
+// rendered to HTML, sanitised on the server, so clients can show the HTML as it is.
+var markdown = goldmark.New(goldmark.WithExtensions(
+	extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+	extension.Strikethrough,
+	extension.Linkify,
+))
+
+// mediaReference matches inline link and image destinations pointing at stored media, such as
+// ![Map](/media/<id>). Only root-relative paths count: other hosts' /media/ URLs are not ours.
+var mediaReference = regexp.MustCompile(`\]\(\s*<?/media/([A-Za-z0-9_-]+)>?(?:\s[^)]*)?\)`)
+
+// contentPolicy allows the HTML Markdown produces and nothing that could run script or pull in outside
+// content: images may only show stored media.
+var contentPolicy = func() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "code",
+		"em", "strong", "del", "ul", "ol", "li", "table", "thead", "tbody", "tr", "th", "td", "a", "img")
+	policy.AllowStandardURLs()
+	policy.RequireNoFollowOnLinks(true)
+	policy.AllowAttrs("href", "title").OnElements("a")
+	policy.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	policy.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	policy.AllowAttrs("src").Matching(regexp.MustCompile(`^/media/[A-Za-z0-9_-]+$`)).OnElements("img")
+	policy.AllowAttrs("alt", "title").OnElements("img")
+	return policy
+}()
+
+// renderMarkdown renders Markdown to sanitised HTML. Raw HTML in the source is never passed through.
+func renderMarkdown(source string) string {
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+
+	var out bytes.Buffer
+	if err := markdown.Convert([]byte(source), &out); err != nil {
+		// Rendering into memory cannot fail; fall back to the escaped source all the same
+		return "<p>" + html.EscapeString(strings.TrimSpace(source)) + "</p>"
+	}
+	return strings.TrimSpace(contentPolicy.Sanitize(out.String()))
+}
+
+// renderAll renders each Markdown value; nil stays nil.
+func renderAll(sources []string) []string {
+	if sources == nil {
+		return nil
+	}
+	rendered := make([]string, len(sources))
+	for i, source := range sources {
+		rendered[i] = renderMarkdown(source)
+	}
+	return rendered
+}
+
+// mediaReferences returns the IDs of the stored media the question's rendered content refers to.
+func mediaReferences(question Question) []string {
+	sources := []string{question.Question, question.Explanation}
+	for _, values := range [][]string{question.Alternatives, question.Prompts, question.Hints, question.Feedback} {
+		sources = append(sources, values...)
+	}
+
+	var ids []string
+	for _, source := range sources {
+		for _, match := range mediaReference.FindAllStringSubmatch(source, -1) {
+			ids = append(ids, match[1])
+		}
+	}
+	return ids
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"Plain text", "What is 2 + 2?", "<p>What is 2 + 2?</p>"},
+		{"Emphasis and inline code", "What does `len(s)` return for *empty* strings?", "<p>What does <code>len(s)</code> return for <em>empty</em> strings?</p>"},
+		{"Code block", "```go\nfmt.Println(\"<hi>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>"},
+		{"Stored image", "![Map](/media/abc123)", "<p><img src=\"/media/abc123\" alt=\"Map\"></p>"},
+		{"Outside image", "![Pixel](https://tracker.example/p.gif)", "<p><img alt=\"Pixel\"></p>"},
+		{"Raw HTML", "Hi <b onclick=\"x()\">there</b>", "<p>Hi there</p>"},
+		{"Script", "<script>alert(1)</script>", ""},
+		{"Table", "| Capital |\n|:-:|\n| Lima |", "<table>\n<thead>\n<tr>\n<th align=\"center\">Capital</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"center\">Lima</td>\n</tr>\n</tbody>\n</table>"},
+		{"Script link", "[Click](javascript:alert(1))", "<p>Click</p>"},
+		{"Link", "[Docs](https://go.dev)", "<p><a href=\"https://go.dev\" rel=\"nofollow\">Docs</a></p>"},
+		{"Empty", " ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, renderMarkdown(tt.source))
+		})
+	}
+}
+
+func TestMediaReferences(t *testing.T) {
+	question := Question{
+		Question:     "Which country is shaded? ![Map](/media/map1)",
+		Alternatives: []string{"![](/media/flag-a)", "Peru", "[Flag](/media/flag_b \"Flag of Chile\")"},
+		Prompts:      []string{"![](</media/prompt>)"},
+		Hints:        []string{"Compare with [this map](/media/hint)"},
+		Explanation:  "![Relief](/media/relief)",
+		Feedback:     []string{"![](/media/feedback)"},
+	}
+	assert.Equal(t, []string{"map1", "relief", "flag-a", "flag_b", "prompt", "hint", "feedback"}, mediaReferences(question))
+
+	// Only links to our own media count
+	question = Question{
+		Question:     "![Logo](https://cdn.example.com/media/logo)",
+		Alternatives: []string{"See /media/loose", "[Docs](http://example.com/media/docs)"},
+	}
+	assert.Empty(t, mediaReferences(question))
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// DefaultMaxMediaSize is the largest upload accepted unless configured with WithMaxMediaSize.
+const DefaultMaxMediaSize = 5 << 20
+
+// mediaTypes are the image formats that can be uploaded. SVG is left out: it can carry script.
+var mediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Errors returned for media.
+var (
+	ErrMediaNotFound    = repository.ErrMediaNotFound
+	ErrMediaTooLarge    = errors.New("media is too large")
+	ErrUnsupportedMedia = errors.New("unsupported media: PNG, JPEG, GIF and WebP images are accepted")
+	ErrMediaUnavailable = errors.New("no media store is configured")
+)
+
+// ErrUnknownMedia is returned for question content that refers to media that was never uploaded.
+var ErrUnknownMedia = fmt.Errorf("%w: content refers to unknown media", ErrInvalidQuestion)
+
+// Media is an uploaded file. Question content refers to it by URL, as in ![Map](/media/<id>).
+type Media struct {
+	ID          string `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// WithMediaStore keeps uploaded media in store. Without one, uploads fail with ErrMediaUnavailable.
+func WithMediaStore(store repository.MediaStore) Option {
+	return func(q *QuizServiceImpl) {
+		q.media = store
+	}
+}
+
+// WithMaxMediaSize sets the largest upload, in bytes.
+func WithMaxMediaSize(size int64) Option {
+	return func(q *QuizServiceImpl) {
+		q.maxMediaSize = size
+	}
+}
+
+// UploadMedia stores an image and returns where question content can refer to it. The format is
+// sniffed from the content; a declared content type, if given, must agree with it.
+func (q *QuizServiceImpl) UploadMedia(ctx context.Context, contentType string, content io.Reader) (Media, error) {
+	if q.media == nil {
+		return Media{}, ErrMediaUnavailable
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, q.maxMediaSize+1))
+	if err != nil {
+		return Media{}, err
+	}
+	if int64(len(data)) > q.maxMediaSize {
+		return Media{}, fmt.Errorf("%w: the limit is %d bytes", ErrMediaTooLarge, q.maxMediaSize)
+	}
+
+	detected := http.DetectContentType(data)
+	if len(data) == 0 || !mediaTypes[detected] {
+		return Media{}, ErrUnsupportedMedia
+	}
+	if contentType != "" && contentType != detected {
+		return Media{}, fmt.Errorf("%w: the content is %s, not %s", ErrUnsupportedMedia, detected, contentType)
+	}
+
+	id, err := newRandomID()
+	if err != nil {
+		return Media{}, err
+	}
+	media := repository.Media{ID: id, ContentType: detected, Size: int64(len(data)), CreatedAt: q.now()}
+	if err := q.media.SaveMedia(ctx, media, data); err != nil {
+		return Media{}, err
+	}
+	return toMedia(media), nil
+}
+
+// OpenMedia returns an uploaded file and its content; the caller must close it.
+func (q *QuizServiceImpl) OpenMedia(ctx context.Context, id string) (Media, io.ReadCloser, error) {
+	if q.media == nil {
+		return Media{}, nil, ErrMediaNotFound
+	}
+
+	media, content, err := q.media.OpenMedia(ctx, id)
+	if err != nil {
+		return Media{}, nil, err
+	}
+	return toMedia(media), content, nil
+}
+
+// checkMediaReferences makes sure every media the question's content refers to has been uploaded.
+func (q *QuizServiceImpl) checkMediaReferences(ctx context.Context, question Question) error {
+	for _, id := range mediaReferences(question) {
+		if q.media == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownMedia, id)
+		}
+		_, err := q.media.GetMedia(ctx, id)
+		if errors.Is(err, repository.ErrMediaNotFound) {
+			return fmt.Errorf("%w: %s", ErrUnknownMedia, id)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toMedia(media repository.Media) Media {
+	return Media{ID: media.ID, URL: "/media/" + media.ID, ContentType: media.ContentType, Size: media.Size}
+}
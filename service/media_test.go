@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// pngImage is enough of a PNG file for its type to be recognised.
+var pngImage = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestQuizService_Media(t *testing.T) {
+	ctx := context.Background()
+	store, err := repository.NewFileMediaStore(t.TempDir())
+	require.NoError(t, err)
+	svc := NewQuizService(repository.NewRepository(), WithMediaStore(store), WithMaxMediaSize(64))
+
+	media, err := svc.UploadMedia(ctx, "", bytes.NewReader(pngImage))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", media.ContentType)
+	assert.Equal(t, "/media/"+media.ID, media.URL)
+
+	opened, content, err := svc.OpenMedia(ctx, media.ID)
+	require.NoError(t, err)
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, pngImage, data)
+	assert.Equal(t, media, opened)
+
+	// The content decides the type; uploads must be images within the size limit
+	_, err = svc.UploadMedia(ctx, "image/jpeg", bytes.NewReader(pngImage))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+	_, err = svc.UploadMedia(ctx, "", strings.NewReader(`<svg onload="alert(1)"></svg>`))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+	_, err = svc.UploadMedia(ctx, "", bytes.NewReader(append(pngImage, make([]byte, 64)...)))
+	assert.ErrorIs(t, err, ErrMediaTooLarge)
+	_, _, err = svc.OpenMedia(ctx, "missing")
+	assert.ErrorIs(t, err, ErrMediaNotFound)
+
+	// Question content may only refer to uploaded media
+	question := Question{ID: 1, Question: fmt.Sprintf("Which country is this?\n\n![Map](%s)", media.URL), Alternatives: []string{"Peru", "Chile"}}
+	require.NoError(t, svc.AddQuestion(ctx, question))
+	question.ID = 2
+	question.Alternatives = []string{"![](/media/missing)", "Chile"}
+	assert.ErrorIs(t, svc.AddQuestion(ctx, question), ErrUnknownMedia)
+	assert.ErrorIs(t, svc.AddQuestion(ctx, question), ErrInvalidQuestion)
+
+	questions, err := svc.GetQuestions(ctx)
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, fmt.Sprintf("<p>Which country is this?</p>\n<p><img src=%q alt=\"Map\"></p>", media.URL), questions[0].QuestionHTML)
+	assert.Equal(t, []string{"<p>Peru</p>", "<p>Chile</p>"}, questions[0].AlternativesHTML)
+
+	// Without a store nothing can be uploaded
+	_, err = NewQuizService(repository.NewRepository()).UploadMedia(ctx, "", bytes.NewReader(pngImage))
+	assert.ErrorIs(t, err, ErrMediaUnavailable)
+}
+
+func TestShuffler_PresentRendersInShownOrder(t *testing.T) {
+	question := Question{ID: 1, Question: "Pick **one**", Alternatives: []string{"a", "b", "c", "d"}}
+	view := shuffler{enabled: true, seed: 7}.present(question)
+
+	require.Len(t, view.AlternativesHTML, 4)
+	for i, alternative := range view.Alternatives {
+		assert.Equal(t, "<p>"+alternative+"</p>", view.AlternativesHTML[i])
+	}
+	assert.Equal(t, "<p>Pick <strong>one</strong></p>", view.QuestionHTML)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Player is the public view of a registered player.
+type Player struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PlayerRegistration is returned once, when a player registers; the token cannot be retrieved again.
+type PlayerRegistration struct {
+	Player
+	Token string `json:"token"`
+}
+
+// Score is one recorded quiz result in a player's history.
+type Score struct {
+	QuizID         int       `json:"quiz_id"`
+	Score          float64   `json:"score"`
+	MaxScore       float64   `json:"max_score"`
+	ElapsedSeconds float64   `json:"elapsed_seconds,omitempty"`
+	RecordedAt     time.Time `json:"recorded_at"`
+}
+
+// Errors returned when registering or identifying players.
+var (
+	ErrPlayerNotFound   = repository.ErrUserNotFound
+	ErrInvalidPlayer    = errors.New("player name is required")
+	ErrInvalidToken     = errors.New("invalid player token")
+	ErrIdentityRequired = errors.New("this operation requires a registered player")
+)
+
+// playerKey is the context key under which the caller's player ID is stored.
+type playerKey struct{}
+
+// WithPlayer returns a context that identifies the caller as the given player.
+// Scores and attempts created with this context are attributed to the player.
+func WithPlayer(ctx context.Context, playerID string) context.Context {
+	return context.WithValue(ctx, playerKey{}, playerID)
+}
+
+// PlayerFromContext returns the caller's player ID, if the request was identified.
+func PlayerFromContext(ctx context.Context) (string, bool) {
+	playerID, ok := ctx.Value(playerKey{}).(string)
+	return playerID, ok && playerID != ""
+}
+
+// RegisterPlayer creates a player and returns it with the token that identifies it in later requests.
+func (q *QuizServiceImpl) RegisterPlayer(ctx context.Context, name string) (PlayerRegistration, error) {
+	if name == "" {
+		return PlayerRegistration{}, ErrInvalidPlayer
+	}
+
+	id, err := newRandomID()
+	if err != nil {
+		return PlayerRegistration{}, err
+	}
+	token, err := newRandomID()
+	if err != nil {
+		return PlayerRegistration{}, err
+	}
+
+	user := repository.User{ID: id, Name: name, TokenHash: hashToken(token), CreatedAt: q.now()}
+	if err := q.repo.AddUser(ctx, user); err != nil {
+		return PlayerRegistration{}, err
+	}
+
+	return PlayerRegistration{Player: Player{ID: id, Name: name}, Token: token}, nil
+}
+
+// AuthenticatePlayer returns the player a token was issued to.
+func (q *QuizServiceImpl) AuthenticatePlayer(ctx context.Context, token string) (Player, error) {
+	user, err := q.repo.GetUserByTokenHash(ctx, hashToken(token))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return Player{}, ErrInvalidToken
+	}
+	if err != nil {
+		return Player{}, err
+	}
+	return Player{ID: user.ID, Name: user.Name}, nil
+}
+
+// GetPlayerScores returns the calling player's score history, oldest first.
+func (q *QuizServiceImpl) GetPlayerScores(ctx context.Context) ([]Score, error) {
+	playerID, ok := PlayerFromContext(ctx)
+	if !ok {
+		return nil, ErrIdentityRequired
+	}
+
+	records, err := q.repo.GetScoresByUser(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]Score, 0, len(records))
+	for _, record := range records {
+		scores = append(scores, Score{
+			QuizID:         record.QuizID,
+			Score:          record.Score,
+			MaxScore:       record.MaxScore,
+			ElapsedSeconds: record.Elapsed.Seconds(),
+			RecordedAt:     record.RecordedAt,
+		})
+	}
+	return scores, nil
+}
+
+// betterScore reports whether a beats b: a higher normalised score, or the same score in less time when both were timed.
+func betterScore(a, b repository.ScoreRecord) bool {
+	if scoreA, scoreB := normalizedScore(a), normalizedScore(b); scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	return a.Elapsed > 0 && b.Elapsed > 0 && a.Elapsed < b.Elapsed
+}
+
+// hashToken returns the stored form of a player token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestQuizService_RegisterAndAuthenticatePlayer(t *testing.T) {
+	svc := NewQuizService(repository.NewRepository())
+	ctx := context.Background()
+
+	registration, err := svc.RegisterPlayer(ctx, "Ada")
+	require.NoError(t, err)
+	assert.NotEmpty(t, registration.ID)
+	assert.NotEmpty(t, registration.Token)
+
+	player, err := svc.AuthenticatePlayer(ctx, registration.Token)
+	require.NoError(t, err)
+	assert.Equal(t, registration.Player, player)
+
+	_, err = svc.AuthenticatePlayer(ctx, "forged")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = svc.RegisterPlayer(ctx, "")
+	assert.ErrorIs(t, err, ErrInvalidPlayer)
+
+	_, err = svc.GetPlayerScores(ctx)
+	assert.ErrorIs(t, err, ErrIdentityRequired, "Anonymous callers have no history")
+}
+
+func TestQuizService_ScoresCarryPlayerIdentity(t *testing.T) {
+	svc, _ := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	registration, err := svc.RegisterPlayer(ctx, "Ada")
+	require.NoError(t, err)
+	playerCtx := WithPlayer(ctx, registration.ID)
+
+	// One direct submission and one attempt, both attributed to the player
+	_, err = svc.SubmitAnswersByID(playerCtx, []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+
+	attempt, err := svc.StartAttempt(playerCtx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(playerCtx, attempt.ID, Answer{QuestionID: 2, Choice: 1}))
+	_, err = svc.FinishAttempt(playerCtx, attempt.ID)
+	require.NoError(t, err)
+
+	// An anonymous result is not part of the player's history
+	_, err = svc.SubmitAnswersByID(ctx, nil)
+	require.NoError(t, err)
+
+	scores, err := svc.GetPlayerScores(playerCtx)
+	require.NoError(t, err)
+	require.Len(t, scores, 2)
+	assert.Equal(t, Score{QuizID: DefaultQuizID, Score: 1, MaxScore: 2, RecordedAt: scores[0].RecordedAt}, scores[0])
+	assert.Equal(t, 1.0, scores[1].Score)
+	assert.Equal(t, 2.0, scores[1].MaxScore)
+}
+
+func TestQuizService_RetakesDoNotSkewComparison(t *testing.T) {
+	svc, _ := newSeededService(t, arithmeticFixture)
+	ctx := context.Background()
+
+	grinder, err := svc.RegisterPlayer(ctx, "Grinder")
+	require.NoError(t, err)
+	newcomer, err := svc.RegisterPlayer(ctx, "Newcomer")
+	require.NoError(t, err)
+
+	// The same player failing ten times counts as a single result
+	for i := 0; i < 10; i++ {
+		_, err := svc.SubmitAnswersByID(WithPlayer(ctx, grinder.ID), nil)
+		require.NoError(t, err)
+	}
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 1}})
+	require.NoError(t, err)
+
+	response, err := svc.SubmitAnswersByID(WithPlayer(ctx, newcomer.ID), []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, Standing{Rank: 2, Of: 3, BetterThan: 1, WorseThan: 1, Percentile: 50}, response.Standing,
+		"The newcomer beat the grinder's best and lost to the anonymous perfect score")
+
+	// A player is never compared against their own earlier results
+	response, err = svc.SubmitAnswersByID(WithPlayer(ctx, grinder.ID), []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, response.Standing.BetterThan)
+	assert.Equal(t, 1, response.Standing.TiedWith)
+	assert.Equal(t, 3, response.Standing.Of)
+}
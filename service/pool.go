@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"sort"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// DrawRule asks for Count questions drawn at random from the quiz's questions in Pool, such as
+// "3 from easy-geography". A quiz's rules are the sections of its attempts, served in rule order.
+type DrawRule struct {
+	Pool  string `json:"pool"`
+	Count int    `json:"count"`
+}
+
+// ErrPoolTooSmall is returned when an attempt cannot be drawn because a pool has fewer questions than its rule asks for.
+var ErrPoolTooSmall = errors.New("a pool has fewer questions than its draw rule asks for")
+
+// drawQuestions picks an attempt's questions by the quiz's draw rules. Each rule's questions keep the quiz's
+// order among themselves; the same seed always draws the same questions.
+func drawQuestions(rules []DrawRule, questions []Question, seed int64) ([]Question, error) {
+	random := mathrand.New(mathrand.NewSource(seed))
+
+	var drawn []Question
+	for _, rule := range rules {
+		var pool []Question
+		for _, question := range questions {
+			if question.Pool == rule.Pool {
+				pool = append(pool, question)
+			}
+		}
+		if len(pool) < rule.Count {
+			return nil, fmt.Errorf("%w: %q has %d, %d wanted", ErrPoolTooSmall, rule.Pool, len(pool), rule.Count)
+		}
+
+		picked := random.Perm(len(pool))[:rule.Count]
+		sort.Ints(picked)
+		for _, i := range picked {
+			drawn = append(drawn, pool[i])
+		}
+	}
+	return drawn, nil
+}
+
+// requiresAttempt reports whether the quiz can only be taken through attempts: timed quizzes, where the
+// server keeps the clock, quizzes with draw rules or adaptive ones, where the server picks what each
+// player is served, and shuffled quizzes, where each player sees their own order.
+func (q *QuizServiceImpl) requiresAttempt(quiz repository.Quiz, questions []Question) bool {
+	return len(quiz.Draw) > 0 || quiz.Adaptive || quiz.ShuffleQuestions || quiz.ShuffleAlternatives || q.isTimed(quiz, questions)
+}
+
+// toRepositoryDraw maps service layer draw rules to the repository format.
+func toRepositoryDraw(rules []DrawRule) []repository.DrawRule {
+	if rules == nil {
+		return nil
+	}
+	repoRules := make([]repository.DrawRule, 0, len(rules))
+	for _, rule := range rules {
+		repoRules = append(repoRules, repository.DrawRule{Pool: rule.Pool, Count: rule.Count})
+	}
+	return repoRules
+}
+
+// fromRepositoryDraw maps repository draw rules to the service layer format.
+func fromRepositoryDraw(repoRules []repository.DrawRule) []DrawRule {
+	if repoRules == nil {
+		return nil
+	}
+	rules := make([]DrawRule, 0, len(repoRules))
+	for _, rule := range repoRules {
+		rules = append(rules, DrawRule{Pool: rule.Pool, Count: rule.Count})
+	}
+	return rules
+}
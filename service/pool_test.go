@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// poolQuestions returns n single-choice questions in a pool, numbered from first.
+func poolQuestions(pool string, first, n int) []Question {
+	questions := make([]Question, 0, n)
+	for id := first; id < first+n; id++ {
+		questions = append(questions, Question{ID: id, Question: fmt.Sprintf("%s %d", pool, id), Alternatives: []string{"no", "yes"}, CorrectAnswer: 1, Pool: pool})
+	}
+	return questions
+}
+
+func TestDrawQuestions(t *testing.T) {
+	bank := append(poolQuestions("easy-geography", 1, 10), poolQuestions("medium-science", 11, 10)...)
+	bank = append(bank, Question{ID: 99, Question: "Unpooled", Alternatives: []string{"no", "yes"}})
+	rules := []DrawRule{{Pool: "easy-geography", Count: 3}, {Pool: "medium-science", Count: 5}}
+
+	drawn, err := drawQuestions(rules, bank, 7)
+	require.NoError(t, err)
+	require.Len(t, drawn, 8)
+
+	// Rules are sections in order, each keeping the bank's order
+	for i, question := range drawn {
+		if i < 3 {
+			assert.Equal(t, "easy-geography", question.Pool)
+		} else {
+			assert.Equal(t, "medium-science", question.Pool)
+		}
+		if i > 0 && i != 3 {
+			assert.Greater(t, question.ID, drawn[i-1].ID)
+		}
+	}
+
+	// The same seed draws the same questions; another seed usually does not
+	again, err := drawQuestions(rules, bank, 7)
+	require.NoError(t, err)
+	assert.Equal(t, drawn, again)
+	differs := false
+	for seed := int64(8); seed < 20 && !differs; seed++ {
+		other, err := drawQuestions(rules, bank, seed)
+		require.NoError(t, err)
+		differs = fmt.Sprint(other) != fmt.Sprint(drawn)
+	}
+	assert.True(t, differs, "Different seeds should draw different questions")
+
+	_, err = drawQuestions([]DrawRule{{Pool: "easy-geography", Count: 11}}, bank, 7)
+	assert.ErrorIs(t, err, ErrPoolTooSmall)
+	_, err = drawQuestions([]DrawRule{{Pool: "missing", Count: 1}}, bank, 7)
+	assert.ErrorIs(t, err, ErrPoolTooSmall)
+}
+
+func TestQuizService_PooledAttempt(t *testing.T) {
+	ctx := context.Background()
+	svc := NewQuizService(repository.NewRepository())
+	require.NoError(t, svc.CreateQuiz(ctx, Quiz{ID: 2, Name: "Bank", Scoring: ScoringWeighted, Draw: []DrawRule{{Pool: "easy", Count: 2}, {Pool: "hard", Count: 1}}}))
+	for _, question := range append(poolQuestions("easy", 1, 6), poolQuestions("hard", 7, 3)...) {
+		question.QuizID = 2
+		if question.Pool == "hard" {
+			question.Weight = 3
+		}
+		require.NoError(t, svc.AddQuestion(ctx, question))
+	}
+
+	// Starting an attempt draws from the pools and records what was drawn
+	attempt, err := svc.StartQuizAttempt(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, attempt.Questions, 3)
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, attempt.Questions, resumed.Questions, "Resuming serves the drawn questions")
+
+	// Grading uses exactly the drawn questions
+	for _, question := range attempt.Questions[:2] {
+		require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: question.ID, Choice: 1}))
+	}
+	notDrawn := 1
+	for containsQuestion(attempt.Questions, notDrawn) {
+		notDrawn++
+	}
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: notDrawn, Choice: 1}), ErrUnknownQuestion)
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, result.Score)
+	assert.Equal(t, 5.0, result.MaxScore)
+	assert.Len(t, result.Results, 3)
+
+	// Bypassing the draw is not allowed, nor is drawing more than a pool holds
+	_, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.ErrorIs(t, err, ErrAttemptRequired)
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Bank", Draw: []DrawRule{{Pool: "hard", Count: 4}}}))
+	_, err = svc.StartQuizAttempt(ctx, 2)
+	assert.ErrorIs(t, err, ErrPoolTooSmall)
+
+	assert.ErrorIs(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Bank", Draw: []DrawRule{{Pool: "hard", Count: 1}, {Pool: "hard", Count: 1}}}), ErrInvalidQuiz)
+}
+
+func containsQuestion(questions []PlayerQuestion, id int) bool {
+	for _, question := range questions {
+		if question.ID == id {
+			return true
+		}
+	}
+	return false
+}
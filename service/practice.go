@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// DefaultPracticeLimit is the number of due questions returned when the caller sets no limit.
+const DefaultPracticeLimit = 20
+
+// Practice schedules follow SuperMemo's SM-2: each successful review multiplies the interval by the
+// question's ease, and the ease follows how well the player recalled it.
+const (
+	initialEase = 2.5
+	minEase     = 1.3
+	day         = 24 * time.Hour
+	// maxIntervalDays caps the interval well within a Duration, however often a question is recalled
+	maxIntervalDays = 36500
+)
+
+// ErrInvalidPractice is returned for a practice request with a negative limit.
+var ErrInvalidPractice = errors.New("invalid practice query")
+
+// DueQuestion is a question a player should practise now, without its answer key.
+type DueQuestion struct {
+	PlayerQuestion
+	// Due is when the question fell due; nil for questions the player has never reviewed
+	Due *time.Time `json:"due,omitempty"`
+}
+
+// Review is a practice review's outcome and the question's next schedule.
+type Review struct {
+	QuestionID   int       `json:"question_id"`
+	Points       float64   `json:"points"`
+	Quality      int       `json:"quality"` // Recall on SM-2's scale of 0 to 5; 3 and up counts as remembered
+	Repetitions  int       `json:"repetitions"`
+	Lapses       int       `json:"lapses"`
+	IntervalDays int       `json:"interval_days"`
+	Ease         float64   `json:"ease"`
+	Due          time.Time `json:"due"`
+}
+
+// GetDueQuestions returns the calling player's practice questions of a quiz: questions whose review is
+// due, longest overdue first, then questions the player has never reviewed, in the quiz's order.
+// A limit of zero means DefaultPracticeLimit.
+func (q *QuizServiceImpl) GetDueQuestions(ctx context.Context, quizID int, limit int) ([]DueQuestion, error) {
+	playerID, ok := PlayerFromContext(ctx)
+	if !ok {
+		return nil, ErrIdentityRequired
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: limit must not be negative", ErrInvalidPractice)
+	}
+	if limit == 0 {
+		limit = DefaultPracticeLimit
+	}
+
+	_, questions, err := q.loadQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := q.repo.GetReviewStates(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	byQuestion := make(map[int]repository.ReviewState, len(states))
+	for _, state := range states {
+		byQuestion[state.QuestionID] = state
+	}
+
+	now := q.now()
+	var due, fresh []DueQuestion
+	for _, question := range questions {
+		state, reviewed := byQuestion[question.ID]
+		switch {
+		case !reviewed:
+			fresh = append(fresh, DueQuestion{PlayerQuestion: toPlayerQuestion(question)})
+		case !state.Due.After(now):
+			at := state.Due
+			due = append(due, DueQuestion{PlayerQuestion: toPlayerQuestion(question), Due: &at})
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].Due.Before(*due[j].Due) })
+
+	practice := append(due, fresh...)
+	if len(practice) > limit {
+		practice = practice[:limit]
+	}
+	if practice == nil {
+		practice = []DueQuestion{}
+	}
+	return practice, nil
+}
+
+// RecordReview grades the calling player's practice answer to a question of the quiz and schedules its
+// next review. Practice is kept apart from competitive play: reviews are never recorded as scores or
+// graded answers, so they cannot move comparisons, leaderboards or statistics.
+func (q *QuizServiceImpl) RecordReview(ctx context.Context, quizID int, answer Answer) (Review, error) {
+	playerID, ok := PlayerFromContext(ctx)
+	if !ok {
+		return Review{}, ErrIdentityRequired
+	}
+
+	_, questions, err := q.loadQuiz(ctx, quizID)
+	if err != nil {
+		return Review{}, err
+	}
+	var question Question
+	found := false
+	for _, candidate := range questions {
+		if candidate.ID == answer.QuestionID {
+			question, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return Review{}, fmt.Errorf("%w: %d", ErrUnknownQuestion, answer.QuestionID)
+	}
+	if err := validateAnswer(question, answer); err != nil {
+		return Review{}, err
+	}
+
+	points := gradeQuestion(question, answer)
+	quality := recallQuality(points)
+	now := q.now()
+	var scheduled repository.ReviewState
+	err = q.repo.UpdateReviewState(ctx, playerID, question.ID, func(state *repository.ReviewState) error {
+		*state = schedule(*state, quality, now)
+		scheduled = *state
+		return nil
+	})
+	if err != nil {
+		return Review{}, err
+	}
+
+	return Review{
+		QuestionID:   question.ID,
+		Points:       points,
+		Quality:      quality,
+		Repetitions:  scheduled.Repetitions,
+		Lapses:       scheduled.Lapses,
+		IntervalDays: int(scheduled.Interval / day),
+		Ease:         scheduled.Ease,
+		Due:          scheduled.Due,
+	}, nil
+}
+
+// recallQuality maps an answer's points to SM-2's quality of recall: a full answer is a good recall,
+// a partial one a hard recall, and anything less a failure.
+func recallQuality(points float64) int {
+	switch {
+	case points >= 1:
+		return 4
+	case points >= 0.5:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// schedule applies one review of the given quality at now to a review state and returns the new state.
+// A recall before the question is due shows nothing new about the interval, so it keeps the interval and
+// ease and only counts from now. It depends only on its arguments, so the schedule is reproducible under
+// a fixed clock.
+func schedule(state repository.ReviewState, quality int, now time.Time) repository.ReviewState {
+	if state.Ease == 0 {
+		state.Ease = initialEase
+	}
+
+	if quality >= 3 && state.Repetitions > 0 && now.Before(state.Due) {
+		state.Reviews++
+		state.LastReviewed = now
+		state.Due = now.Add(state.Interval)
+		return state
+	}
+
+	if quality >= 3 {
+		switch state.Repetitions {
+		case 0:
+			state.Interval = day
+		case 1:
+			state.Interval = 6 * day
+		default:
+			days := math.Min(math.Round(float64(state.Interval/day)*state.Ease), maxIntervalDays)
+			state.Interval = time.Duration(days) * day
+		}
+		state.Repetitions++
+	} else {
+		if state.Repetitions > 0 {
+			state.Lapses++
+		}
+		state.Repetitions = 0
+		state.Interval = day
+	}
+
+	miss := float64(5 - quality)
+	state.Ease = math.Max(minEase, state.Ease+0.1-miss*(0.08+miss*0.02))
+	state.Reviews++
+	state.LastReviewed = now
+	state.Due = now.Add(state.Interval)
+	return state
+}
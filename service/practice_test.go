@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var state repository.ReviewState
+
+	// Good recalls when due space the reviews out: one day, six days, then the interval times the ease
+	at := now
+	for _, days := range []int{1, 6, 15} {
+		state = schedule(state, 4, at)
+		assert.Equal(t, time.Duration(days)*day, state.Interval)
+		assert.Equal(t, at.Add(state.Interval), state.Due)
+		assert.Equal(t, 2.5, state.Ease, "A good recall keeps the ease")
+		at = state.Due
+	}
+	assert.Equal(t, 3, state.Repetitions)
+
+	// Recalling a question before it is due does not grow its interval
+	early := schedule(state, 5, at.Add(-day))
+	assert.Equal(t, state.Interval, early.Interval)
+	assert.Equal(t, []int{3, 4}, []int{early.Repetitions, early.Reviews})
+	assert.Equal(t, at.Add(-day).Add(state.Interval), early.Due)
+
+	// A failed recall starts over and makes the question harder
+	state = schedule(state, 1, at)
+	assert.Equal(t, day, state.Interval)
+	assert.Equal(t, []int{0, 1, 4}, []int{state.Repetitions, state.Lapses, state.Reviews})
+	assert.InDelta(t, 1.96, state.Ease, 1e-9)
+
+	// The ease never drops below its floor, and failing a new question is no lapse
+	fresh := repository.ReviewState{}
+	for i := 0; i < 5; i++ {
+		fresh = schedule(fresh, 1, now)
+	}
+	assert.Equal(t, minEase, fresh.Ease)
+	assert.Equal(t, 0, fresh.Lapses)
+	assert.Equal(t, now, fresh.LastReviewed)
+
+	// However often a question is recalled on time, its interval stays capped and its due date moves forward
+	capped := repository.ReviewState{}
+	at = now
+	for i := 0; i < 40; i++ {
+		capped = schedule(capped, 5, at)
+		require.True(t, capped.Due.After(at), "Review %d should fall due later", i+1)
+		at = capped.Due
+	}
+	assert.Equal(t, maxIntervalDays*day, capped.Interval)
+}
+
+func TestQuizService_Practice(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc, repo := newSeededService(t, arithmeticFixture, WithClock(clock.Now))
+
+	_, err := svc.GetDueQuestions(context.Background(), DefaultQuizID, 0)
+	assert.ErrorIs(t, err, ErrIdentityRequired)
+
+	registration, err := svc.RegisterPlayer(context.Background(), "Ada")
+	require.NoError(t, err)
+	ctx := WithPlayer(context.Background(), registration.ID)
+
+	// New questions are due at once, in the quiz's order
+	due, err := svc.GetDueQuestions(ctx, DefaultQuizID, 0)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, []int{1, 2}, []int{due[0].ID, due[1].ID})
+	assert.Nil(t, due[0].Due)
+
+	review, err := svc.RecordReview(ctx, DefaultQuizID, Answer{QuestionID: 1, Choice: 1})
+	require.NoError(t, err)
+	assert.Equal(t, Review{QuestionID: 1, Points: 1, Quality: 4, Repetitions: 1, IntervalDays: 1, Ease: 2.5, Due: clock.Now().Add(day)}, review)
+	review, err = svc.RecordReview(ctx, DefaultQuizID, Answer{QuestionID: 2, Choice: 0})
+	require.NoError(t, err)
+	assert.Equal(t, 1, review.Quality)
+
+	// Nothing is due until the first review falls due; the longest overdue comes first
+	due, err = svc.GetDueQuestions(ctx, DefaultQuizID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	clock.Advance(time.Hour)
+	_, err = svc.RecordReview(ctx, DefaultQuizID, Answer{QuestionID: 1, Choice: 0})
+	require.NoError(t, err)
+	clock.Advance(2 * day)
+	due, err = svc.GetDueQuestions(ctx, DefaultQuizID, 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1, "The limit caps the questions")
+	assert.Equal(t, 2, due[0].ID)
+	require.NotNil(t, due[0].Due)
+	assert.Equal(t, time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), *due[0].Due)
+
+	// Reviews never count as competitive results
+	scores, err := repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+	items, err := repo.GetItemStats(ctx, DefaultQuizID)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	_, err = svc.RecordReview(ctx, DefaultQuizID, Answer{QuestionID: 9})
+	assert.ErrorIs(t, err, ErrUnknownQuestion)
+	_, err = svc.RecordReview(ctx, DefaultQuizID, Answer{QuestionID: 1, Choice: 7})
+	assert.ErrorIs(t, err, ErrChoiceOutOfRange)
+	_, err = svc.GetDueQuestions(ctx, DefaultQuizID, -1)
+	assert.ErrorIs(t, err, ErrInvalidPractice)
+}
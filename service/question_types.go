@@ -0,0 +1,421 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Question types, the discriminator in a question's "type" field. An empty type is a single-choice question.
+const (
+	QuestionTypeSingle      = repository.QuestionTypeSingle
+	QuestionTypeMulti       = repository.QuestionTypeMulti
+	QuestionTypeShortAnswer = repository.QuestionTypeShortAnswer
+	QuestionTypeNumeric     = repository.QuestionTypeNumeric
+	QuestionTypeOrdering    = repository.QuestionTypeOrdering
+	QuestionTypeMatching    = repository.QuestionTypeMatching
+)
+
+// numericSlack absorbs floating point error, so 0.1 + 0.2 is accepted for 0.3 with no tolerance.
+const numericSlack = 1e-9
+
+// questionType is the behaviour that differs between question types.
+// The repository checks the shape of answer keys; everything else lives here.
+type questionType interface {
+	// answerField names the Answer field that carries answers to this type.
+	answerField() string
+	// validateKey checks the answer key beyond what the repository enforces.
+	validateKey(question Question) error
+	// validateAnswer checks an answer whose fields already match answerField.
+	validateAnswer(question Question, answer Answer) error
+	// grade returns the points, between 0 and 1, a valid answer earns.
+	grade(question Question, answer Answer) float64
+	// fromChoice converts a positional submission's choice to an answer, if the type can be answered that way.
+	fromChoice(questionID, choice int) (Answer, bool)
+	// keyedChoices returns the correct alternatives of a type answered by picking them, or nil.
+	keyedChoices(question Question) []int
+	// pickedChoices returns the alternatives an answer picked, for types answered by picking them, or nil.
+	pickedChoices(answer Answer) []int
+	// mapAlternatives returns the answer with every alternative index it holds replaced by index(i).
+	mapAlternatives(answer Answer, index func(int) int) Answer
+	// key returns the answer key as an answer that earns every point; short answers give the first accepted one.
+	key(question Question) Answer
+}
+
+// questionTypes maps every discriminator value to its behaviour.
+var questionTypes = map[string]questionType{
+	"":                      singleChoice{},
+	QuestionTypeSingle:      singleChoice{},
+	QuestionTypeMulti:       multiSelect{},
+	QuestionTypeShortAnswer: shortAnswer{},
+	QuestionTypeNumeric:     numeric{},
+	QuestionTypeOrdering:    ordering{},
+	QuestionTypeMatching:    matching{},
+}
+
+// typeOf returns the behaviour of the question's type.
+func typeOf(question Question) (questionType, bool) {
+	kind, ok := questionTypes[question.Type]
+	return kind, ok
+}
+
+// singleChoice questions have exactly one correct alternative.
+type singleChoice struct{}
+
+func (singleChoice) answerField() string { return "choice" }
+
+func (singleChoice) validateKey(question Question) error {
+	return validateGrading(question, GradingAllOrNothing)
+}
+
+func (singleChoice) validateAnswer(question Question, answer Answer) error {
+	if answer.Choice < 0 || answer.Choice >= len(question.Alternatives) {
+		return fmt.Errorf("%w: question %d has %d alternatives", ErrChoiceOutOfRange, question.ID, len(question.Alternatives))
+	}
+	return nil
+}
+
+func (singleChoice) grade(question Question, answer Answer) float64 {
+	return points(answer.Choice == question.CorrectAnswer)
+}
+
+func (singleChoice) fromChoice(questionID, choice int) (Answer, bool) {
+	return Answer{QuestionID: questionID, Choice: choice}, true
+}
+
+func (singleChoice) keyedChoices(question Question) []int { return []int{question.CorrectAnswer} }
+
+func (singleChoice) pickedChoices(answer Answer) []int { return []int{answer.Choice} }
+
+func (singleChoice) mapAlternatives(answer Answer, index func(int) int) Answer {
+	answer.Choice = index(answer.Choice)
+	return answer
+}
+
+func (singleChoice) key(question Question) Answer {
+	return Answer{QuestionID: question.ID, Choice: question.CorrectAnswer}
+}
+
+// multiSelect questions ask for every correct alternative.
+type multiSelect struct{}
+
+func (multiSelect) answerField() string { return "choices" }
+
+func (multiSelect) validateKey(question Question) error {
+	return validateGrading(question, GradingAllOrNothing, GradingPartial, GradingPenalty)
+}
+
+func (multiSelect) validateAnswer(question Question, answer Answer) error {
+	return validateIndices(question, answer.Choices, len(question.Alternatives))
+}
+
+func (multiSelect) grade(question Question, answer Answer) float64 {
+	correct := make(map[int]bool, len(question.CorrectAnswers))
+	for _, index := range question.CorrectAnswers {
+		correct[index] = true
+	}
+
+	hits, misses := 0, 0
+	for _, choice := range answer.Choices {
+		if correct[choice] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	switch question.Grading {
+	case GradingPartial:
+		return float64(hits) / float64(len(correct))
+	case GradingPenalty:
+		return math.Max(0, float64(hits-misses)/float64(len(correct)))
+	default:
+		return points(hits == len(correct) && misses == 0)
+	}
+}
+
+// fromChoice counts a positional choice as that one alternative picked.
+func (multiSelect) fromChoice(questionID, choice int) (Answer, bool) {
+	return Answer{QuestionID: questionID, Choices: []int{choice}}, true
+}
+
+func (multiSelect) keyedChoices(question Question) []int { return question.CorrectAnswers }
+
+func (multiSelect) pickedChoices(answer Answer) []int { return answer.Choices }
+
+func (multiSelect) mapAlternatives(answer Answer, index func(int) int) Answer {
+	answer.Choices = mapIndices(answer.Choices, index)
+	return answer
+}
+
+func (multiSelect) key(question Question) Answer {
+	return Answer{QuestionID: question.ID, Choices: question.CorrectAnswers}
+}
+
+// shortAnswer questions are typed and matched against the accepted answers.
+type shortAnswer struct{}
+
+func (shortAnswer) answerField() string { return "text" }
+
+func (shortAnswer) validateKey(question Question) error {
+	return validateGrading(question, GradingAllOrNothing)
+}
+
+func (shortAnswer) validateAnswer(Question, Answer) error { return nil }
+
+// grade accepts a text matching one of the accepted answers, ignoring case and extra whitespace
+// and allowing up to FuzzyDistance typos.
+func (shortAnswer) grade(question Question, answer Answer) float64 {
+	text := normalizeText(answer.Text)
+	if text == "" {
+		return 0
+	}
+
+	for _, accepted := range question.AcceptedAnswers {
+		if editDistance(text, normalizeText(accepted)) <= question.FuzzyDistance {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (shortAnswer) fromChoice(int, int) (Answer, bool) { return Answer{}, false }
+
+func (shortAnswer) keyedChoices(Question) []int { return nil }
+
+func (shortAnswer) pickedChoices(Answer) []int { return nil }
+
+func (shortAnswer) mapAlternatives(answer Answer, _ func(int) int) Answer { return answer }
+
+func (shortAnswer) key(question Question) Answer {
+	answer := Answer{QuestionID: question.ID}
+	if len(question.AcceptedAnswers) > 0 {
+		answer.Text = question.AcceptedAnswers[0]
+	}
+	return answer
+}
+
+// numeric questions accept a number within a tolerance of the answer.
+type numeric struct{}
+
+func (numeric) answerField() string { return "number" }
+
+func (numeric) validateKey(question Question) error {
+	if question.NumericAnswer == nil {
+		return ErrInvalidAnswer
+	}
+	return validateGrading(question, GradingAllOrNothing)
+}
+
+func (numeric) validateAnswer(question Question, answer Answer) error {
+	if answer.Number == nil {
+		return fmt.Errorf("%w: question %d takes number", ErrWrongAnswerType, question.ID)
+	}
+	return nil
+}
+
+func (numeric) grade(question Question, answer Answer) float64 {
+	if question.NumericAnswer == nil || answer.Number == nil {
+		return 0
+	}
+
+	expected := *question.NumericAnswer
+	allowed := question.Tolerance
+	if question.ToleranceMode == ToleranceRelative {
+		allowed *= math.Abs(expected)
+	}
+	return points(math.Abs(*answer.Number-expected) <= allowed+numericSlack)
+}
+
+func (numeric) fromChoice(int, int) (Answer, bool) { return Answer{}, false }
+
+func (numeric) keyedChoices(Question) []int { return nil }
+
+func (numeric) pickedChoices(Answer) []int { return nil }
+
+func (numeric) mapAlternatives(answer Answer, _ func(int) int) Answer { return answer }
+
+func (numeric) key(question Question) Answer {
+	return Answer{QuestionID: question.ID, Number: question.NumericAnswer}
+}
+
+// ordering questions ask for the alternatives in the correct order.
+type ordering struct{}
+
+func (ordering) answerField() string { return "order" }
+
+func (ordering) validateKey(question Question) error {
+	return validateGrading(question, GradingAllOrNothing, GradingKendallTau)
+}
+
+// validateAnswer requires every alternative exactly once.
+func (ordering) validateAnswer(question Question, answer Answer) error {
+	if len(answer.Order) != len(question.Alternatives) {
+		return fmt.Errorf("%w: question %d has %d items to order", ErrIncompleteAnswer, question.ID, len(question.Alternatives))
+	}
+	return validateIndices(question, answer.Order, len(question.Alternatives))
+}
+
+// grade compares the order exactly or, with GradingKendallTau, awards the share of item pairs
+// that are in the right relative order.
+func (ordering) grade(question Question, answer Answer) float64 {
+	if len(answer.Order) != len(question.CorrectOrder) {
+		return 0
+	}
+
+	if question.Grading != GradingKendallTau {
+		for i := range answer.Order {
+			if answer.Order[i] != question.CorrectOrder[i] {
+				return 0
+			}
+		}
+		return 1
+	}
+
+	rank := make(map[int]int, len(question.CorrectOrder))
+	for position, item := range question.CorrectOrder {
+		rank[item] = position
+	}
+
+	pairs, concordant := 0, 0
+	for i := range answer.Order {
+		for j := i + 1; j < len(answer.Order); j++ {
+			pairs++
+			if rank[answer.Order[i]] < rank[answer.Order[j]] {
+				concordant++
+			}
+		}
+	}
+	if pairs == 0 {
+		return 1
+	}
+	return float64(concordant) / float64(pairs)
+}
+
+func (ordering) fromChoice(int, int) (Answer, bool) { return Answer{}, false }
+
+func (ordering) keyedChoices(Question) []int { return nil }
+
+func (ordering) pickedChoices(Answer) []int { return nil }
+
+func (ordering) mapAlternatives(answer Answer, index func(int) int) Answer {
+	answer.Order = mapIndices(answer.Order, index)
+	return answer
+}
+
+func (ordering) key(question Question) Answer {
+	return Answer{QuestionID: question.ID, Order: question.CorrectOrder}
+}
+
+// matching questions pair each prompt with one of the alternatives.
+type matching struct{}
+
+func (matching) answerField() string { return "matches" }
+
+func (matching) validateKey(question Question) error {
+	return validateGrading(question, GradingPartial, GradingAllOrNothing)
+}
+
+// validateAnswer requires one entry per prompt: the index of an alternative, or -1 to leave the prompt unmatched.
+func (matching) validateAnswer(question Question, answer Answer) error {
+	if len(answer.Matches) != len(question.Prompts) {
+		return fmt.Errorf("%w: question %d has %d prompts to match", ErrIncompleteAnswer, question.ID, len(question.Prompts))
+	}
+	for _, match := range answer.Matches {
+		if match < -1 || match >= len(question.Alternatives) {
+			return fmt.Errorf("%w: question %d has %d alternatives", ErrChoiceOutOfRange, question.ID, len(question.Alternatives))
+		}
+	}
+	return nil
+}
+
+// grade awards a share of the point per correctly matched prompt, or, with GradingAllOrNothing,
+// the whole point only when every prompt is matched correctly.
+func (matching) grade(question Question, answer Answer) float64 {
+	if len(question.CorrectMatches) == 0 {
+		return 0
+	}
+
+	correct := 0
+	for i, match := range answer.Matches {
+		if i < len(question.CorrectMatches) && match == question.CorrectMatches[i] {
+			correct++
+		}
+	}
+
+	if question.Grading == GradingAllOrNothing {
+		return points(correct == len(question.CorrectMatches))
+	}
+	return float64(correct) / float64(len(question.CorrectMatches))
+}
+
+func (matching) fromChoice(int, int) (Answer, bool) { return Answer{}, false }
+
+func (matching) keyedChoices(Question) []int { return nil }
+
+func (matching) pickedChoices(Answer) []int { return nil }
+
+// mapAlternatives leaves unmatched prompts (-1) unmatched.
+func (matching) mapAlternatives(answer Answer, index func(int) int) Answer {
+	answer.Matches = mapIndices(answer.Matches, func(i int) int {
+		if i < 0 {
+			return i
+		}
+		return index(i)
+	})
+	return answer
+}
+
+func (matching) key(question Question) Answer {
+	return Answer{QuestionID: question.ID, Matches: question.CorrectMatches}
+}
+
+// normalizeText lower-cases text, trims it and collapses runs of whitespace to a single space.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// editDistance returns the Levenshtein distance between a and b, counted in runes.
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if deletion := previous[j] + 1; deletion < current[j] {
+				current[j] = deletion
+			}
+			if insertion := current[j-1] + 1; insertion < current[j] {
+				current[j] = insertion
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+// mapIndices returns a copy of indices with every index replaced by index(i); nil stays nil.
+func mapIndices(indices []int, index func(int) int) []int {
+	if indices == nil {
+		return nil
+	}
+	mapped := make([]int, len(indices))
+	for i, value := range indices {
+		mapped[i] = index(value)
+	}
+	return mapped
+}
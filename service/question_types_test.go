@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestGradeQuestion_MultiSelect(t *testing.T) {
+	question := Question{
+		ID:             1,
+		Type:           QuestionTypeMulti,
+		Alternatives:   []string{"2", "4", "5", "9"},
+		CorrectAnswers: []int{0, 2},
+	}
+
+	tests := []struct {
+		grading Grading
+		choices []int
+		points  float64
+	}{
+		{"", []int{2, 0}, 1},
+		{GradingAllOrNothing, []int{0}, 0},
+		{GradingAllOrNothing, []int{0, 1, 2}, 0},
+		{GradingPartial, []int{0}, 0.5},
+		{GradingPartial, []int{0, 1, 2, 3}, 1},
+		{GradingPenalty, []int{0, 2, 1}, 0.5},
+		{GradingPenalty, []int{0, 1, 3}, 0},
+		{GradingPenalty, nil, 0},
+	}
+	for _, tt := range tests {
+		question.Grading = tt.grading
+		assert.Equal(t, tt.points, gradeQuestion(question, Answer{QuestionID: 1, Choices: tt.choices}), "%q grading of %v", tt.grading, tt.choices)
+	}
+}
+
+func TestQuizService_MultiSelectQuestion(t *testing.T) {
+	svc := NewQuizService(repository.NewRepository())
+	ctx := context.Background()
+
+	question := Question{
+		ID:             1,
+		Type:           QuestionTypeMulti,
+		Question:       "Which of these are primes?",
+		Alternatives:   []string{"2", "4", "5", "9"},
+		CorrectAnswers: []int{0, 2},
+		Grading:        GradingPenalty,
+	}
+	require.NoError(t, svc.AddQuestion(ctx, question))
+	require.NoError(t, svc.AddQuestion(ctx, Question{ID: 2, Question: "What is 1 + 1?", Alternatives: []string{"1", "2"}, CorrectAnswer: 1}))
+
+	invalid := question
+	invalid.ID = 3
+	invalid.Grading = "lenient"
+	assert.ErrorIs(t, svc.AddQuestion(ctx, invalid), ErrInvalidQuestion)
+
+	// Players are told the type but not the answer key
+	questions, err := svc.GetQuestions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, QuestionTypeMulti, questions[0].Type)
+
+	response, err := svc.SubmitAnswersByID(ctx, []Answer{
+		{QuestionID: 1, Choices: []int{0, 2, 3}},
+		{QuestionID: 2, Choice: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, response.Score)
+	assert.Equal(t, []QuestionResult{
+		{QuestionID: 1, Status: ResultPartial, Points: 0.5},
+		{QuestionID: 2, Status: ResultCorrect, Points: 1},
+	}, response.Results)
+
+	// Answers must fit the question they are for
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 2, Choices: []int{1}}})
+	assert.ErrorIs(t, err, ErrWrongAnswerType)
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choices: []int{4}}})
+	assert.ErrorIs(t, err, ErrChoiceOutOfRange)
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choices: []int{0, 0}}})
+	assert.ErrorIs(t, err, ErrDuplicateChoice)
+}
+
+func TestQuizService_MultiSelectAttempt(t *testing.T) {
+	svc := NewQuizService(repository.NewRepository())
+	ctx := context.Background()
+
+	require.NoError(t, svc.AddQuestion(ctx, Question{
+		ID:             1,
+		Type:           QuestionTypeMulti,
+		Question:       "Which of these are primes?",
+		Alternatives:   []string{"2", "4", "5"},
+		CorrectAnswers: []int{0, 2},
+	}))
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choices: []int{2, 0}}))
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choices: []int{3}}), ErrChoiceOutOfRange)
+
+	// Resuming shows the saved picks
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []Answer{{QuestionID: 1, Choices: []int{2, 0}}}, resumed.Answers)
+
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, result.Score)
+	assert.Equal(t, []QuestionResult{{QuestionID: 1, Status: ResultCorrect, Points: 1}}, result.Results)
+}
+
+func TestGradeQuestion_ShortAnswer(t *testing.T) {
+	question := Question{ID: 1, Type: QuestionTypeShortAnswer, AcceptedAnswers: []string{"Au", "Aurum"}}
+
+	assert.Equal(t, 1.0, gradeQuestion(question, Answer{Text: "  au "}), "Case and surrounding whitespace are ignored")
+	assert.Equal(t, 1.0, gradeQuestion(question, Answer{Text: "AURUM"}), "Any accepted answer counts")
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Text: "Ag"}))
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Text: "Aurun"}), "Typos only count with fuzzy matching")
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Text: " "}))
+
+	question.FuzzyDistance = 1
+	assert.Equal(t, 1.0, gradeQuestion(question, Answer{Text: "Aurun"}))
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Text: "Arun"}), "Two typos are beyond a distance of one")
+
+	phrase := Question{ID: 2, Type: QuestionTypeShortAnswer, AcceptedAnswers: []string{"William Shakespeare"}}
+	assert.Equal(t, 1.0, gradeQuestion(phrase, Answer{Text: "william   shakespeare"}), "Runs of whitespace collapse")
+}
+
+func TestGradeQuestion_Numeric(t *testing.T) {
+	answer := 100.0
+	question := Question{ID: 1, Type: QuestionTypeNumeric, NumericAnswer: &answer}
+
+	number := func(n float64) Answer { return Answer{Number: &n} }
+	assert.Equal(t, 1.0, gradeQuestion(question, number(100)))
+	assert.Equal(t, 0.0, gradeQuestion(question, number(100.5)), "Without a tolerance the answer must be exact")
+
+	question.Tolerance = 0.5
+	assert.Equal(t, 1.0, gradeQuestion(question, number(100.5)))
+	assert.Equal(t, 1.0, gradeQuestion(question, number(99.5)))
+	assert.Equal(t, 0.0, gradeQuestion(question, number(101)))
+
+	question.Tolerance = 0.02
+	question.ToleranceMode = ToleranceRelative
+	assert.Equal(t, 1.0, gradeQuestion(question, number(102)), "A relative tolerance scales with the answer")
+	assert.Equal(t, 0.0, gradeQuestion(question, number(97)))
+
+	third := 0.3
+	exact := Question{ID: 2, Type: QuestionTypeNumeric, NumericAnswer: &third}
+	assert.Equal(t, 1.0, gradeQuestion(exact, number(0.1+0.2)), "Floating point error is not held against the player")
+}
+
+func TestQuizService_TypedQuestions(t *testing.T) {
+	svc := NewQuizService(repository.NewRepository())
+	ctx := context.Background()
+
+	kelvin := 373.15
+	require.NoError(t, svc.AddQuestion(ctx, Question{
+		ID:              1,
+		Type:            QuestionTypeShortAnswer,
+		Question:        "What is the chemical symbol for Gold?",
+		AcceptedAnswers: []string{"Au"},
+	}))
+	require.NoError(t, svc.AddQuestion(ctx, Question{
+		ID:            2,
+		Type:          QuestionTypeNumeric,
+		Question:      "What is the boiling point of water in Kelvin?",
+		NumericAnswer: &kelvin,
+		Tolerance:     0.5,
+	}))
+	assert.ErrorIs(t, svc.AddQuestion(ctx, Question{ID: 3, Type: QuestionTypeNumeric, Question: "How many?"}), ErrInvalidAnswer,
+		"A numeric question needs an answer")
+
+	// The author view keeps the answer keys
+	questions, err := svc.GetAuthorQuestions(ctx)
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	assert.Equal(t, []string{"Au"}, questions[0].AcceptedAnswers)
+	require.NotNil(t, questions[1].NumericAnswer)
+	assert.Equal(t, kelvin, *questions[1].NumericAnswer)
+
+	boiling := 373.0
+	response, err := svc.SubmitAnswersByID(ctx, []Answer{
+		{QuestionID: 1, Text: "au"},
+		{QuestionID: 2, Number: &boiling},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, response.Score)
+
+	// Answers must have the shape their question takes
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 2, Text: "373"}})
+	assert.ErrorIs(t, err, ErrWrongAnswerType)
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Number: &boiling}})
+	assert.ErrorIs(t, err, ErrWrongAnswerType)
+
+	// Typed answers are saved on attempts like any other
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Text: "Ag"}))
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Number: &boiling}))
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []QuestionResult{
+		{QuestionID: 1, Status: ResultIncorrect},
+		{QuestionID: 2, Status: ResultCorrect, Points: 1},
+	}, result.Results)
+}
+
+func TestGradeQuestion_Ordering(t *testing.T) {
+	question := Question{ID: 1, Type: QuestionTypeOrdering, Alternatives: []string{"b", "d", "a", "c"}, CorrectOrder: []int{2, 0, 3, 1}}
+
+	assert.Equal(t, 1.0, gradeQuestion(question, Answer{Order: []int{2, 0, 3, 1}}))
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Order: []int{0, 2, 3, 1}}), "By default the order must be exact")
+
+	question.Grading = GradingKendallTau
+	assert.Equal(t, 1.0, gradeQuestion(question, Answer{Order: []int{2, 0, 3, 1}}))
+	assert.Equal(t, 5.0/6, gradeQuestion(question, Answer{Order: []int{0, 2, 3, 1}}), "One swapped pair out of six")
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Order: []int{1, 3, 0, 2}}), "The reverse order has no pair right")
+}
+
+func TestGradeQuestion_Matching(t *testing.T) {
+	question := Question{
+		ID:             1,
+		Type:           QuestionTypeMatching,
+		Prompts:        []string{"France", "Italy", "Spain"},
+		Alternatives:   []string{"Madrid", "Paris", "Rome", "Lisbon"},
+		CorrectMatches: []int{1, 2, 0},
+	}
+
+	assert.Equal(t, 1.0, gradeQuestion(question, Answer{Matches: []int{1, 2, 0}}))
+	assert.Equal(t, 2.0/3, gradeQuestion(question, Answer{Matches: []int{1, 2, 3}}), "Each pair earns its share")
+	assert.Equal(t, 1.0/3, gradeQuestion(question, Answer{Matches: []int{1, -1, -1}}), "Unmatched prompts earn nothing")
+
+	question.Grading = GradingAllOrNothing
+	assert.Equal(t, 0.0, gradeQuestion(question, Answer{Matches: []int{1, 2, 3}}))
+}
+
+func TestQuizService_OrderingAndMatching(t *testing.T) {
+	svc := NewQuizService(repository.NewRepository())
+	ctx := context.Background()
+
+	require.NoError(t, svc.AddQuestion(ctx, Question{
+		ID:           1,
+		Type:         QuestionTypeOrdering,
+		Question:     "Put these events in chronological order",
+		Alternatives: []string{"Moon landing", "French Revolution", "Fall of the Berlin Wall"},
+		CorrectOrder: []int{1, 0, 2},
+		Grading:      GradingKendallTau,
+	}))
+	require.NoError(t, svc.AddQuestion(ctx, Question{
+		ID:             2,
+		Type:           QuestionTypeMatching,
+		Question:       "Match each country to its capital",
+		Prompts:        []string{"France", "Italy"},
+		Alternatives:   []string{"Rome", "Paris"},
+		CorrectMatches: []int{1, 0},
+	}))
+
+	// Grading schemes are checked per type
+	assert.ErrorIs(t, svc.AddQuestion(ctx, Question{
+		ID:           3,
+		Type:         QuestionTypeOrdering,
+		Question:     "Order these",
+		Alternatives: []string{"a", "b"},
+		CorrectOrder: []int{0, 1},
+		Grading:      GradingPenalty,
+	}), ErrInvalidQuestion)
+	assert.ErrorIs(t, svc.AddQuestion(ctx, Question{ID: 3, Type: "essay", Question: "Discuss"}), ErrInvalidQuestion)
+
+	// Players see the prompts but not the answer keys
+	questions, err := svc.GetQuestions(ctx)
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	assert.Equal(t, QuestionTypeMatching, questions[1].Type)
+	assert.Equal(t, []string{"France", "Italy"}, questions[1].Prompts)
+	assert.Equal(t, []string{"<p>France</p>", "<p>Italy</p>"}, questions[1].PromptsHTML)
+
+	response, err := svc.SubmitAnswersByID(ctx, []Answer{
+		{QuestionID: 1, Order: []int{0, 1, 2}},
+		{QuestionID: 2, Matches: []int{1, 1}},
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 2.0/3+0.5, response.Score, 1e-9)
+
+	// Orders must name every item once; matches must cover every prompt
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Order: []int{0, 1}}})
+	assert.ErrorIs(t, err, ErrIncompleteAnswer)
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Order: []int{0, 0, 1}}})
+	assert.ErrorIs(t, err, ErrDuplicateChoice)
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 2, Matches: []int{1}}})
+	assert.ErrorIs(t, err, ErrIncompleteAnswer)
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 2, Order: []int{1, 0}}})
+	assert.ErrorIs(t, err, ErrWrongAnswerType)
+
+	// Both are saved on attempts and come back when resuming
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Order: []int{1, 0, 2}}))
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Matches: []int{1, 0}}))
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []Answer{
+		{QuestionID: 1, Order: []int{1, 0, 2}},
+		{QuestionID: 2, Matches: []int{1, 0}},
+	}, resumed.Answers)
+
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, result.Score)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// DefaultQuizID is the quiz used by the endpoints that predate named quizzes.
+const DefaultQuizID = repository.DefaultQuizID
+
+// Quiz is a named set of questions with its own settings and score history.
+type Quiz struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// TimeLimitSeconds and LatePolicy override the server defaults when set.
+	TimeLimitSeconds int        `json:"time_limit_seconds,omitempty"`
+	LatePolicy       LatePolicy `json:"late_policy,omitempty"`
+	// Scoring picks the scoring strategy; empty means ScoringCount. Zero parameters take the defaults.
+	Scoring   Scoring `json:"scoring,omitempty"`
+	Penalty   float64 `json:"penalty,omitempty"`    // ScoringNegative and ScoringNoBlankPenalty
+	TimeBonus float64 `json:"time_bonus,omitempty"` // ScoringTimeBonus
+	// Attempts serve the questions, and the alternatives of each, in an order of their own
+	ShuffleQuestions    bool `json:"shuffle_questions,omitempty"`
+	ShuffleAlternatives bool `json:"shuffle_alternatives,omitempty"`
+	// Draw makes every attempt draw its questions at random from the quiz's pools; empty serves them all
+	Draw []DrawRule `json:"draw,omitempty"`
+	// Adaptive quizzes serve one question at a time, picked from the player's estimated ability, until the
+	// estimate's standard error is at most TargetError or MaxQuestions were answered; zero takes the defaults
+	Adaptive     bool    `json:"adaptive,omitempty"`
+	MaxQuestions int     `json:"max_questions,omitempty"`
+	TargetError  float64 `json:"target_error,omitempty"`
+	// ReviewPolicy is when players may review their finished attempts; empty means ReviewImmediate
+	ReviewPolicy ReviewPolicy `json:"review_policy,omitempty"`
+}
+
+// Errors returned when managing quizzes.
+var (
+	ErrQuizNotFound = repository.ErrQuizNotFound
+	ErrQuizExists   = repository.ErrQuizExists
+	ErrInvalidQuiz  = repository.ErrInvalidQuiz
+	ErrDefaultQuiz  = errors.New("the default quiz cannot be deleted")
+)
+
+// GetQuizzes returns every quiz.
+func (q *QuizServiceImpl) GetQuizzes(ctx context.Context) ([]Quiz, error) {
+	repoQuizzes, err := q.repo.GetAllQuizzes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quizzes := make([]Quiz, 0, len(repoQuizzes))
+	for _, repoQuiz := range repoQuizzes {
+		quizzes = append(quizzes, fromRepositoryQuiz(repoQuiz))
+	}
+	return quizzes, nil
+}
+
+// GetQuiz returns a quiz by its ID.
+func (q *QuizServiceImpl) GetQuiz(ctx context.Context, id int) (Quiz, error) {
+	repoQuiz, err := q.repo.GetQuiz(ctx, id)
+	if err != nil {
+		return Quiz{}, err
+	}
+	return fromRepositoryQuiz(repoQuiz), nil
+}
+
+// CreateQuiz adds a new, empty quiz.
+func (q *QuizServiceImpl) CreateQuiz(ctx context.Context, quiz Quiz) error {
+	if err := validateLatePolicy(quiz.LatePolicy); err != nil {
+		return err
+	}
+	if err := validateReviewPolicy(quiz.ReviewPolicy); err != nil {
+		return err
+	}
+	if err := q.validateScoring(quiz); err != nil {
+		return err
+	}
+	if quiz.Adaptive && len(quiz.Draw) > 0 {
+		return ErrInvalidQuiz // Adaptive quizzes pick their own questions
+	}
+	return q.repo.AddQuiz(ctx, toRepositoryQuiz(quiz))
+}
+
+// UpdateQuiz replaces the name, description and settings of an existing quiz.
+func (q *QuizServiceImpl) UpdateQuiz(ctx context.Context, quiz Quiz) error {
+	if err := validateLatePolicy(quiz.LatePolicy); err != nil {
+		return err
+	}
+	if err := validateReviewPolicy(quiz.ReviewPolicy); err != nil {
+		return err
+	}
+	if err := q.validateScoring(quiz); err != nil {
+		return err
+	}
+	if quiz.Adaptive && len(quiz.Draw) > 0 {
+		return ErrInvalidQuiz // Adaptive quizzes pick their own questions
+	}
+	return q.repo.UpdateQuiz(ctx, toRepositoryQuiz(quiz))
+}
+
+// DeleteQuiz removes a quiz together with its questions, scores and attempts.
+// The default quiz backs the original endpoints and cannot be deleted.
+func (q *QuizServiceImpl) DeleteQuiz(ctx context.Context, id int) error {
+	if id == DefaultQuizID {
+		return ErrDefaultQuiz
+	}
+	if err := q.repo.DeleteQuiz(ctx, id); err != nil {
+		return err
+	}
+	q.rankings.forget(id)
+	return nil
+}
+
+// GetQuizQuestions fetches the questions of one quiz for players, without the answer keys.
+func (q *QuizServiceImpl) GetQuizQuestions(ctx context.Context, quizID int) ([]PlayerQuestion, error) {
+	questions, err := q.GetQuizAuthorQuestions(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	playerQuestions := make([]PlayerQuestion, 0, len(questions))
+	for _, question := range questions {
+		playerQuestions = append(playerQuestions, toPlayerQuestion(question))
+	}
+
+	return playerQuestions, nil
+}
+
+// GetQuizAuthorQuestions fetches the questions of one quiz, including the answer keys.
+func (q *QuizServiceImpl) GetQuizAuthorQuestions(ctx context.Context, quizID int) ([]Question, error) {
+	_, questions, err := q.loadQuiz(ctx, quizID)
+	return questions, err
+}
+
+// loadQuiz returns a quiz with its questions, or ErrQuizNotFound.
+func (q *QuizServiceImpl) loadQuiz(ctx context.Context, quizID int) (repository.Quiz, []Question, error) {
+	quiz, err := q.repo.GetQuiz(ctx, quizID)
+	if err != nil {
+		return repository.Quiz{}, nil, err
+	}
+
+	repoQuestions, err := q.repo.GetQuestionsByQuiz(ctx, quizID)
+	if err != nil {
+		return repository.Quiz{}, nil, err
+	}
+
+	questions := make([]Question, 0, len(repoQuestions))
+	for _, repoQuestion := range repoQuestions {
+		questions = append(questions, fromRepositoryQuestion(repoQuestion))
+	}
+	return quiz, questions, nil
+}
+
+// validateLatePolicy rejects late policies the attempt flow does not know; empty keeps the server default.
+func validateLatePolicy(policy LatePolicy) error {
+	switch policy {
+	case "", LatePolicyReject, LatePolicyAutoFinalize:
+		return nil
+	default:
+		return ErrInvalidQuiz
+	}
+}
+
+// toRepositoryQuiz maps a service layer quiz to the repository format.
+func toRepositoryQuiz(quiz Quiz) repository.Quiz {
+	return repository.Quiz{
+		ID:          quiz.ID,
+		Name:        quiz.Name,
+		Description: quiz.Description,
+		TimeLimit:   time.Duration(quiz.TimeLimitSeconds) * time.Second,
+		LatePolicy:  string(quiz.LatePolicy),
+		Scoring:     string(quiz.Scoring),
+		Penalty:     quiz.Penalty,
+		TimeBonus:   quiz.TimeBonus,
+
+		ShuffleQuestions:    quiz.ShuffleQuestions,
+		ShuffleAlternatives: quiz.ShuffleAlternatives,
+		Draw:                toRepositoryDraw(quiz.Draw),
+		Adaptive:            quiz.Adaptive,
+		MaxQuestions:        quiz.MaxQuestions,
+		TargetError:         quiz.TargetError,
+		ReviewPolicy:        string(quiz.ReviewPolicy),
+	}
+}
+
+// fromRepositoryQuiz maps a repository quiz to the service layer format.
+func fromRepositoryQuiz(quiz repository.Quiz) Quiz {
+	return Quiz{
+		ID:               quiz.ID,
+		Name:             quiz.Name,
+		Description:      quiz.Description,
+		TimeLimitSeconds: int(quiz.TimeLimit / time.Second),
+		LatePolicy:       LatePolicy(quiz.LatePolicy),
+		Scoring:          Scoring(quiz.Scoring),
+		Penalty:          quiz.Penalty,
+		TimeBonus:        quiz.TimeBonus,
+
+		ShuffleQuestions:    quiz.ShuffleQuestions,
+		ShuffleAlternatives: quiz.ShuffleAlternatives,
+		Draw:                fromRepositoryDraw(quiz.Draw),
+		Adaptive:            quiz.Adaptive,
+		MaxQuestions:        quiz.MaxQuestions,
+		TargetError:         quiz.TargetError,
+		ReviewPolicy:        ReviewPolicy(quiz.ReviewPolicy),
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// geographyFixture holds a second quiz next to the default one, each with one question.
+var geographyFixture = testFixture{
+	Quizzes: []Quiz{{ID: 2, Name: "Geography"}},
+	Questions: []Question{
+		{ID: 1, Question: "What is 1 + 1?", Alternatives: []string{"1", "2"}, CorrectAnswer: 1},
+		{ID: 2, QuizID: 2, Question: "What is the capital of France?", Alternatives: []string{"Paris", "Rome"}, CorrectAnswer: 0},
+	},
+}
+
+func TestQuizService_QuizzesScopeQuestions(t *testing.T) {
+	svc, _ := newSeededService(t, geographyFixture)
+	ctx := context.Background()
+
+	quizzes, err := svc.GetQuizzes(ctx)
+	require.NoError(t, err)
+	require.Len(t, quizzes, 2, "The default quiz should be listed next to the new one")
+	assert.Equal(t, DefaultQuizID, quizzes[0].ID)
+
+	// Each quiz only serves its own questions
+	questions, err := svc.GetQuizQuestions(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, 2, questions[0].ID)
+
+	legacy, err := svc.GetQuestions(ctx)
+	require.NoError(t, err)
+	require.Len(t, legacy, 1, "The original endpoint should serve the default quiz only")
+	assert.Equal(t, 1, legacy[0].ID)
+
+	// Answers to another quiz's question are unknown here
+	_, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.ErrorIs(t, err, ErrUnknownQuestion)
+
+	// Replacing a question without naming a quiz keeps it where it is
+	updated, err := svc.UpdateQuestion(ctx, Question{ID: 2, Question: "What is the capital of Italy?", Alternatives: []string{"Paris", "Rome"}, CorrectAnswer: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.QuizID)
+	questions, err = svc.GetQuizQuestions(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.Equal(t, "What is the capital of Italy?", questions[0].Question)
+
+	_, err = svc.GetQuizQuestions(ctx, 99)
+	assert.ErrorIs(t, err, ErrQuizNotFound)
+	assert.ErrorIs(t, svc.AddQuestion(ctx, Question{ID: 3, QuizID: 99, Question: "Orphan?", Alternatives: []string{"Yes"}}), ErrQuizNotFound)
+}
+
+func TestQuizService_ComparisonIsPerQuiz(t *testing.T) {
+	svc, _ := newSeededService(t, geographyFixture)
+	ctx := context.Background()
+
+	// A perfect score on the default quiz must not count against the geography quiz
+	_, err := svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+
+	first, err := svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 2, Choice: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, Standing{Rank: 1, Of: 1, Percentile: 50}, first.Standing)
+
+	second, err := svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 2, Choice: 0}})
+	require.NoError(t, err)
+	assert.Equal(t, Standing{Rank: 1, Of: 2, BetterThan: 1, Percentile: 75}, second.Standing)
+}
+
+func TestQuizService_QuizSettingsOverrideDefaults(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc, _ := newSeededService(t, geographyFixture, WithClock(clock.Now))
+	ctx := context.Background()
+
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Geography", TimeLimitSeconds: 60, LatePolicy: LatePolicyAutoFinalize}))
+	assert.ErrorIs(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Geography", LatePolicy: "whenever"}), ErrInvalidQuiz)
+
+	// A timed quiz must be taken through an attempt, which inherits the quiz settings
+	_, err := svc.SubmitQuizAnswers(ctx, 2, nil)
+	assert.ErrorIs(t, err, ErrAttemptRequired)
+
+	attempt, err := svc.StartQuizAttempt(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempt.QuizID)
+	require.NotNil(t, attempt.Deadline)
+	assert.Equal(t, clock.Now().Add(time.Minute), *attempt.Deadline)
+	assert.Equal(t, LatePolicyAutoFinalize, attempt.LatePolicy)
+
+	// The default quiz is untouched
+	_, err = svc.SubmitAnswersByID(ctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestQuizService_DeleteQuiz(t *testing.T) {
+	svc, _ := newSeededService(t, geographyFixture)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.DeleteQuiz(ctx, DefaultQuizID), ErrDefaultQuiz)
+	assert.NoError(t, svc.DeleteQuiz(ctx, 2))
+	assert.ErrorIs(t, svc.DeleteQuiz(ctx, 2), ErrQuizNotFound)
+
+	// The quiz's questions go with it
+	assert.ErrorIs(t, svc.DeleteQuestion(ctx, 2), ErrQuestionNotFound)
+	assert.NoError(t, svc.DeleteQuestion(ctx, 1), "Questions of other quizzes should be kept")
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// ReviewPolicy decides when players may review a finished attempt's answers, explanations and feedback.
+type ReviewPolicy string
+
+const (
+	// ReviewImmediate shows the review as soon as the attempt is finished. It is the default.
+	ReviewImmediate ReviewPolicy = "immediate"
+	// ReviewAfterDeadline holds the review back until the attempt's deadline, or its expiry when the quiz is
+	// not timed, so the answers cannot be passed on while the attempt could still be taken.
+	ReviewAfterDeadline ReviewPolicy = "after-deadline"
+	// ReviewNever never shows the review.
+	ReviewNever ReviewPolicy = "never"
+)
+
+// Errors returned when an attempt cannot be reviewed.
+var (
+	ErrAttemptNotFinished = errors.New("attempt must be finished before it is reviewed")
+	ErrReviewUnavailable  = errors.New("the quiz does not allow reviewing this attempt")
+)
+
+// AttemptReview is a finished attempt with the answer key, for the player to learn from.
+type AttemptReview struct {
+	AttemptID  string           `json:"attempt_id"`
+	QuizID     int              `json:"quiz_id"`
+	Score      float64          `json:"score"`
+	MaxScore   float64          `json:"max_score"`
+	Percentage float64          `json:"percentage"`
+	Questions  []QuestionReview `json:"questions"`
+}
+
+// QuestionReview is one question of a reviewed attempt, as the attempt showed it. The answers and the
+// feedback use the attempt's alternative order.
+type QuestionReview struct {
+	PlayerQuestion
+	Status      string   `json:"status"`
+	Points      float64  `json:"points"`
+	Answer      *Answer  `json:"answer,omitempty"` // The player's answer; nil when the question was left unanswered
+	Correct     Answer   `json:"correct"`          // An answer that earns every point
+	Explanation string   `json:"explanation,omitempty"`
+	Feedback    []string `json:"feedback,omitempty"` // A remark on each alternative
+	// The explanation and feedback rendered from Markdown to sanitised HTML, safe to show as is
+	ExplanationHTML string   `json:"explanation_html,omitempty"`
+	FeedbackHTML    []string `json:"feedback_html,omitempty"`
+}
+
+// ReviewAttempt returns a finished attempt's questions with the player's answers, the correct answers and
+// the authors' explanations, when the quiz's review policy allows it. Questions deleted since are left out.
+func (q *QuizServiceImpl) ReviewAttempt(ctx context.Context, attemptID string) (AttemptReview, error) {
+	attempt, err := q.ownedAttempt(ctx, attemptID)
+	if err != nil {
+		return AttemptReview{}, err
+	}
+	if !attempt.Finished() {
+		return AttemptReview{}, ErrAttemptNotFinished
+	}
+
+	quizID := attempt.QuizID
+	if quizID == 0 {
+		quizID = DefaultQuizID
+	}
+	quiz, err := q.repo.GetQuiz(ctx, quizID)
+	if err != nil {
+		return AttemptReview{}, err
+	}
+	if err := reviewAvailable(ReviewPolicy(quiz.ReviewPolicy), attempt, q.now()); err != nil {
+		return AttemptReview{}, err
+	}
+
+	questions, err := q.attemptQuestions(ctx, attempt)
+	if err != nil {
+		return AttemptReview{}, err
+	}
+
+	results := make(map[int]repository.AttemptResult, len(attempt.Results))
+	for _, result := range attempt.Results {
+		results[result.QuestionID] = result
+	}
+	answers := attemptAnswers(attempt)
+	shuffler := attemptShuffler(attempt)
+
+	review := AttemptReview{
+		AttemptID:  attempt.ID,
+		QuizID:     quizID,
+		Score:      attempt.Score,
+		MaxScore:   attempt.MaxScore,
+		Percentage: percentage(attempt.Score, attempt.MaxScore),
+		Questions:  make([]QuestionReview, 0, len(questions)),
+	}
+	for _, question := range questions {
+		kind, ok := typeOf(question)
+		if !ok {
+			continue
+		}
+
+		result, graded := results[question.ID]
+		if !graded {
+			result = repository.AttemptResult{QuestionID: question.ID, Status: ResultMissing}
+		}
+		questionReview := QuestionReview{
+			PlayerQuestion: shuffler.present(question),
+			Status:         result.Status,
+			Points:         result.Points,
+			Correct:        shuffler.toShown(question, kind.key(question)),
+			Explanation:    question.Explanation,
+			Feedback:       shuffler.arrange(question, question.Feedback),
+
+			ExplanationHTML: renderMarkdown(question.Explanation),
+			FeedbackHTML:    shuffler.arrange(question, renderAll(question.Feedback)),
+		}
+		if answer, answered := answers[question.ID]; answered {
+			shown := shuffler.toShown(question, answer)
+			questionReview.Answer = &shown
+		}
+		review.Questions = append(review.Questions, questionReview)
+	}
+	return review, nil
+}
+
+// reviewAvailable reports whether the policy lets a finished attempt be reviewed at now.
+func reviewAvailable(policy ReviewPolicy, attempt repository.Attempt, now time.Time) error {
+	switch policy {
+	case "", ReviewImmediate:
+		return nil
+	case ReviewAfterDeadline:
+		deadline := attempt.Deadline
+		if deadline.IsZero() {
+			deadline = attempt.ExpiresAt
+		}
+		if now.Before(deadline) {
+			return fmt.Errorf("%w until %s", ErrReviewUnavailable, deadline.UTC().Format(time.RFC3339))
+		}
+		return nil
+	default:
+		return ErrReviewUnavailable
+	}
+}
+
+// validateReviewPolicy rejects review policies the service does not know; empty means ReviewImmediate.
+func validateReviewPolicy(policy ReviewPolicy) error {
+	switch policy {
+	case "", ReviewImmediate, ReviewAfterDeadline, ReviewNever:
+		return nil
+	default:
+		return ErrInvalidQuiz
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionTypes_Key(t *testing.T) {
+	number := 42.0
+	questions := []Question{
+		{ID: 1, Alternatives: []string{"a", "b"}, CorrectAnswer: 1},
+		{ID: 2, Type: QuestionTypeMulti, Alternatives: []string{"a", "b", "c"}, CorrectAnswers: []int{0, 2}},
+		{ID: 3, Type: QuestionTypeShortAnswer, AcceptedAnswers: []string{"Au", "Aurum"}},
+		{ID: 4, Type: QuestionTypeNumeric, NumericAnswer: &number},
+		{ID: 5, Type: QuestionTypeOrdering, Alternatives: []string{"b", "a"}, CorrectOrder: []int{1, 0}},
+		{ID: 6, Type: QuestionTypeMatching, Prompts: []string{"x", "y"}, Alternatives: []string{"Y", "X"}, CorrectMatches: []int{1, 0}},
+	}
+	for _, question := range questions {
+		kind, ok := typeOf(question)
+		require.True(t, ok)
+		key := kind.key(question)
+		assert.Equal(t, question.ID, key.QuestionID)
+		require.NoError(t, validateAnswer(question, key), "The key of question %d should be a valid answer", question.ID)
+		assert.Equal(t, 1.0, gradeQuestion(question, key), "The key of question %d should earn every point", question.ID)
+	}
+}
+
+// capitalsQuestions are two explained questions for quiz 2, which the review tests create with shuffled alternatives.
+var capitalsQuestions = []Question{
+	{
+		ID: 1, QuizID: 2, Question: "What is the capital of France?", Alternatives: []string{"Berlin", "Paris", "Rome", "Madrid"}, CorrectAnswer: 1,
+		Explanation: "Paris has been the capital since 987",
+		Feedback:    []string{"Berlin is in Germany", "", "Rome is in Italy", "Madrid is in Spain"},
+	},
+	{ID: 2, QuizID: 2, Question: "What is the capital of Japan?", Alternatives: []string{"Kyoto", "Tokyo"}, CorrectAnswer: 1},
+}
+
+func TestQuizService_ReviewAttempt(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newSeededService(t, testFixture{
+		Quizzes:   []Quiz{{ID: 2, Name: "Capitals", ShuffleAlternatives: true}},
+		Questions: capitalsQuestions,
+	})
+
+	attempt, err := svc.StartQuizAttempt(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, attempt.Questions, 2)
+	shown := attempt.Questions[indexOfQuestion(attempt.Questions, 1)].Alternatives
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: indexOf(shown, "Rome")}))
+
+	_, err = svc.ReviewAttempt(ctx, attempt.ID)
+	assert.ErrorIs(t, err, ErrAttemptNotFinished)
+
+	_, err = svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	review, err := svc.ReviewAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, review.QuizID)
+	assert.Equal(t, 0.0, review.Score)
+	require.Len(t, review.Questions, 2)
+
+	// The review shows the question as the attempt did, with the answers and feedback in the same order
+	france := review.Questions[indexOfQuestion(attempt.Questions, 1)]
+	assert.Equal(t, shown, france.Alternatives)
+	assert.Equal(t, ResultIncorrect, france.Status)
+	require.NotNil(t, france.Answer)
+	assert.Equal(t, "Rome", france.Alternatives[france.Answer.Choice])
+	assert.Equal(t, "Paris", france.Alternatives[france.Correct.Choice])
+	assert.Equal(t, "Paris has been the capital since 987", france.Explanation)
+	require.Len(t, france.Feedback, 4)
+	assert.Equal(t, "Rome is in Italy", france.Feedback[france.Answer.Choice])
+	assert.Equal(t, "", france.Feedback[france.Correct.Choice])
+	assert.Equal(t, "<p>Paris has been the capital since 987</p>", france.ExplanationHTML)
+	assert.Equal(t, "<p>Rome is in Italy</p>", france.FeedbackHTML[france.Answer.Choice], "Rendered feedback follows the shown order")
+
+	japan := review.Questions[indexOfQuestion(attempt.Questions, 2)]
+	assert.Equal(t, ResultMissing, japan.Status)
+	assert.Nil(t, japan.Answer)
+	assert.Equal(t, "Tokyo", japan.Alternatives[japan.Correct.Choice])
+	assert.Empty(t, japan.Explanation)
+	assert.Nil(t, japan.Feedback)
+
+	_, err = svc.ReviewAttempt(ctx, "missing")
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestQuizService_ReviewPolicy(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	finished := func(svc QuizService) string {
+		attempt, err := svc.StartQuizAttempt(ctx, 2)
+		require.NoError(t, err)
+		_, err = svc.FinishAttempt(ctx, attempt.ID)
+		require.NoError(t, err)
+		return attempt.ID
+	}
+
+	// After the deadline: untimed attempts can be reviewed once they would have expired
+	svc, _ := newSeededService(t, testFixture{
+		Quizzes:   []Quiz{{ID: 2, Name: "Capitals", ShuffleAlternatives: true, ReviewPolicy: ReviewAfterDeadline}},
+		Questions: capitalsQuestions,
+	}, WithClock(clock.Now), WithAttemptTTL(10*time.Minute))
+	id := finished(svc)
+	_, err := svc.ReviewAttempt(ctx, id)
+	assert.ErrorIs(t, err, ErrReviewUnavailable)
+	clock.Advance(10 * time.Minute)
+	_, err = svc.ReviewAttempt(ctx, id)
+	assert.NoError(t, err)
+
+	// Never
+	svc, _ = newSeededService(t, testFixture{
+		Quizzes:   []Quiz{{ID: 2, Name: "Capitals", ShuffleAlternatives: true, ReviewPolicy: ReviewNever}},
+		Questions: capitalsQuestions,
+	})
+	_, err = svc.ReviewAttempt(ctx, finished(svc))
+	assert.ErrorIs(t, err, ErrReviewUnavailable)
+
+	assert.ErrorIs(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Capitals", ReviewPolicy: "sometimes"}), ErrInvalidQuiz)
+	assert.ErrorIs(t, svc.AddQuestion(ctx, Question{
+		ID: 3, QuizID: 2, Question: "What is the capital of Peru?", Alternatives: []string{"Lima", "Cusco"}, Feedback: []string{"Right"},
+	}), ErrInvalidQuestion, "Feedback must cover every alternative")
+}
+
+// indexOfQuestion returns the position of the question with the given ID, or -1.
+func indexOfQuestion(questions []PlayerQuestion, id int) int {
+	for i, question := range questions {
+		if question.ID == id {
+			return i
+		}
+	}
+	return -1
+}
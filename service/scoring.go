@@ -0,0 +1,180 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// Scoring names the strategy a quiz uses to turn graded answers into a score.
+type Scoring string
+
+const (
+	// ScoringCount adds up the points of every question, one per question. It is the default.
+	ScoringCount Scoring = "count"
+	// ScoringWeighted multiplies every question's points by its weight.
+	ScoringWeighted Scoring = "weighted"
+	// ScoringNegative takes the quiz's penalty off for every wrong or unanswered question.
+	ScoringNegative Scoring = "negative"
+	// ScoringNoBlankPenalty takes the quiz's penalty off for every wrong answer; unanswered questions cost nothing.
+	ScoringNoBlankPenalty Scoring = "no-blank-penalty"
+	// ScoringTimeBonus adds a bonus for finishing a timed attempt early, up to the quiz's time bonus share of the score.
+	ScoringTimeBonus Scoring = "time-bonus"
+)
+
+// Defaults for quizzes that pick a strategy without setting its parameters.
+const (
+	DefaultPenalty   = 0.25
+	DefaultTimeBonus = 0.5
+)
+
+// ScoreSheet is what a Scorer works from: the graded questions and the settings of their quiz.
+type ScoreSheet struct {
+	Questions []Question
+	Results   []QuestionResult // One per question, in the same order
+	Penalty   float64          // Points a wrong answer costs under negative marking
+	TimeBonus float64          // Share of the score finishing instantly adds under time-bonus scoring
+	Elapsed   time.Duration    // How long the attempt took; zero for untimed submissions
+	TimeLimit time.Duration    // The attempt's time limit; zero when it had none
+}
+
+// Scorer turns graded answers into a score. Quizzes pick one by name; WithScorer adds custom strategies.
+type Scorer interface {
+	// Score returns the score the sheet earns and the score a perfect sheet would earn.
+	Score(sheet ScoreSheet) (score, maxScore float64)
+}
+
+// ScorerFunc adapts a function to the Scorer interface.
+type ScorerFunc func(sheet ScoreSheet) (score, maxScore float64)
+
+// Score calls f(sheet).
+func (f ScorerFunc) Score(sheet ScoreSheet) (score, maxScore float64) {
+	return f(sheet)
+}
+
+// ErrUnknownScoring is returned when a quiz names a scoring strategy the service does not know.
+var ErrUnknownScoring = fmt.Errorf("%w: unknown scoring strategy", ErrInvalidQuiz)
+
+// WithScorer registers a scoring strategy under a name quizzes can pick, replacing any built-in of that name.
+func WithScorer(name Scoring, scorer Scorer) Option {
+	return func(q *QuizServiceImpl) {
+		q.scorers[name] = scorer
+	}
+}
+
+// builtinScorers returns the strategies every service starts with.
+func builtinScorers() map[Scoring]Scorer {
+	return map[Scoring]Scorer{
+		ScoringCount:          ScorerFunc(countScore),
+		ScoringWeighted:       ScorerFunc(weightedScore),
+		ScoringNegative:       negativeMarking{blanks: true},
+		ScoringNoBlankPenalty: negativeMarking{},
+		ScoringTimeBonus:      ScorerFunc(timeBonusScore),
+	}
+}
+
+// scorer returns the strategy the quiz picked, or ErrUnknownScoring.
+func (q *QuizServiceImpl) scorer(quiz repository.Quiz) (Scorer, error) {
+	name := Scoring(quiz.Scoring)
+	if name == "" {
+		name = ScoringCount
+	}
+
+	scorer, ok := q.scorers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownScoring, name)
+	}
+	return scorer, nil
+}
+
+// validateScoring rejects strategies the service does not know.
+func (q *QuizServiceImpl) validateScoring(quiz Quiz) error {
+	_, err := q.scorer(toRepositoryQuiz(quiz))
+	return err
+}
+
+// scoreSheet collects graded results with the quiz's scoring parameters, filling in the defaults.
+func scoreSheet(quiz repository.Quiz, questions []Question, results []QuestionResult, elapsed, timeLimit time.Duration) ScoreSheet {
+	sheet := ScoreSheet{
+		Questions: questions,
+		Results:   results,
+		Penalty:   quiz.Penalty,
+		TimeBonus: quiz.TimeBonus,
+		Elapsed:   elapsed,
+		TimeLimit: timeLimit,
+	}
+	if sheet.Penalty == 0 {
+		sheet.Penalty = DefaultPenalty
+	}
+	if sheet.TimeBonus == 0 {
+		sheet.TimeBonus = DefaultTimeBonus
+	}
+	return sheet
+}
+
+// countScore awards every question's points, each question worth one.
+func countScore(sheet ScoreSheet) (float64, float64) {
+	score := 0.0
+	for _, result := range sheet.Results {
+		score += result.Points
+	}
+	return score, float64(len(sheet.Results))
+}
+
+// weightedScore awards every question's points times its weight.
+func weightedScore(sheet ScoreSheet) (float64, float64) {
+	score, maxScore := 0.0, 0.0
+	for i, result := range sheet.Results {
+		weight := questionWeight(sheet.Questions[i])
+		score += result.Points * weight
+		maxScore += weight
+	}
+	return score, maxScore
+}
+
+// negativeMarking takes the penalty off for every wrong answer and, with blanks, every unanswered question.
+// Partly right answers keep their points and cost nothing. The score never drops below zero.
+type negativeMarking struct {
+	blanks bool
+}
+
+func (n negativeMarking) Score(sheet ScoreSheet) (float64, float64) {
+	score, maxScore := countScore(sheet)
+	for _, result := range sheet.Results {
+		if result.Status == ResultIncorrect || (n.blanks && result.Status == ResultMissing) {
+			score -= sheet.Penalty
+		}
+	}
+	return math.Max(0, score), maxScore
+}
+
+// timeBonusScore adds the time bonus share of the count score, scaled by how much of the time limit was left.
+// Untimed submissions score as ScoringCount.
+func timeBonusScore(sheet ScoreSheet) (float64, float64) {
+	score, maxScore := countScore(sheet)
+	if sheet.TimeLimit <= 0 {
+		return score, maxScore
+	}
+
+	remaining := 1 - sheet.Elapsed.Seconds()/sheet.TimeLimit.Seconds()
+	remaining = math.Min(1, math.Max(0, remaining))
+	return score * (1 + sheet.TimeBonus*remaining), maxScore * (1 + sheet.TimeBonus)
+}
+
+// questionWeight returns the question's weight; zero counts as 1.
+func questionWeight(question Question) float64 {
+	if question.Weight == 0 {
+		return 1
+	}
+	return question.Weight
+}
+
+// percentage returns score as a percentage of maxScore, or zero when nothing could be scored.
+func percentage(score, maxScore float64) float64 {
+	if maxScore <= 0 {
+		return 0
+	}
+	return score / maxScore * 100
+}
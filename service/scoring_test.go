@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestScorers(t *testing.T) {
+	// One correct, one partly right, one wrong and one unanswered question
+	sheet := ScoreSheet{
+		Questions: []Question{{ID: 1, Weight: 3}, {ID: 2}, {ID: 3}, {ID: 4, Weight: 2}},
+		Results: []QuestionResult{
+			{QuestionID: 1, Status: ResultCorrect, Points: 1},
+			{QuestionID: 2, Status: ResultPartial, Points: 0.5},
+			{QuestionID: 3, Status: ResultIncorrect},
+			{QuestionID: 4, Status: ResultMissing},
+		},
+		Penalty:   0.25,
+		TimeBonus: 0.5,
+	}
+	scorers := builtinScorers()
+
+	tests := []struct {
+		scoring  Scoring
+		score    float64
+		maxScore float64
+	}{
+		{ScoringCount, 1.5, 4},
+		{ScoringWeighted, 3.5, 7},
+		{ScoringNegative, 1, 4},
+		{ScoringNoBlankPenalty, 1.25, 4},
+		{ScoringTimeBonus, 1.5, 4},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scoring), func(t *testing.T) {
+			score, maxScore := scorers[tt.scoring].Score(sheet)
+			assert.Equal(t, tt.score, score)
+			assert.Equal(t, tt.maxScore, maxScore)
+		})
+	}
+
+	// Negative marking never takes the score below zero
+	score, _ := scorers[ScoringNegative].Score(ScoreSheet{Results: []QuestionResult{{Status: ResultIncorrect}}, Penalty: 1})
+	assert.Equal(t, 0.0, score)
+
+	// The time bonus scales with the share of the time limit left
+	sheet.TimeLimit = 10 * time.Minute
+	sheet.Elapsed = 4 * time.Minute
+	score, maxScore := scorers[ScoringTimeBonus].Score(sheet)
+	assert.InDelta(t, 1.5*1.3, score, 1e-9)
+	assert.Equal(t, 6.0, maxScore)
+}
+
+func TestQuizService_QuizScoring(t *testing.T) {
+	ctx := context.Background()
+	svc := NewQuizService(repository.NewRepository(), WithScorer("double", ScorerFunc(func(sheet ScoreSheet) (float64, float64) {
+		score, maxScore := countScore(sheet)
+		return 2 * score, 2 * maxScore
+	})))
+
+	require.NoError(t, svc.CreateQuiz(ctx, Quiz{ID: 2, Name: "Weighted", Scoring: ScoringWeighted}))
+	require.NoError(t, svc.AddQuestion(ctx, Question{ID: 1, QuizID: 2, Question: "What is 1 + 1?", Alternatives: []string{"1", "2"}, CorrectAnswer: 1, Weight: 3}))
+	require.NoError(t, svc.AddQuestion(ctx, Question{ID: 2, QuizID: 2, Question: "What is 2 + 2?", Alternatives: []string{"3", "4"}, CorrectAnswer: 1}))
+
+	response, err := svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 0}})
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, response.Score)
+	assert.Equal(t, 4.0, response.MaxScore)
+	assert.Equal(t, 75.0, response.Percentage)
+
+	// Switching the strategy applies to the next submission; the parameters default when left zero
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Weighted", Scoring: ScoringNegative}))
+	response, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}, {QuestionID: 2, Choice: 0}})
+	require.NoError(t, err)
+	assert.Equal(t, 1-DefaultPenalty, response.Score)
+	assert.Equal(t, 2.0, response.MaxScore)
+
+	// Custom strategies are picked by name like the built-in ones
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Weighted", Scoring: "double"}))
+	response, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, response.Score)
+	assert.Equal(t, 50.0, response.Percentage)
+
+	assert.ErrorIs(t, svc.CreateQuiz(ctx, Quiz{ID: 3, Name: "Mystery", Scoring: "mystery"}), ErrInvalidQuiz)
+	assert.ErrorIs(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Weighted", Scoring: "mystery"}), ErrUnknownScoring)
+}
+
+func TestQuizService_TimeBonusScoring(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc, repo := newSeededService(t, arithmeticFixture, WithClock(clock.Now))
+	ctx := context.Background()
+
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: DefaultQuizID, Name: "Default quiz", TimeLimitSeconds: 100, Scoring: ScoringTimeBonus, TimeBonus: 1}))
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+	require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}))
+
+	// Finishing with a quarter of the time left adds a quarter of the time bonus
+	clock.Advance(75 * time.Second)
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, result.Score)
+	assert.Equal(t, 4.0, result.MaxScore)
+	assert.Equal(t, 62.5, result.Percentage)
+
+	// The maximum is stored with the score and kept on the finished attempt
+	scores, err := repo.GetAllScores(ctx)
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, 4.0, scores[0].MaxScore)
+
+	finished, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	require.NotNil(t, finished.Result)
+	assert.Equal(t, 62.5, finished.Result.Percentage)
+}
@@ -4,136 +4,597 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"fasttrack/quiz-app/repository"
 )
 
 // SubmitResponse holds the result of the quiz submission in the service layer.
 type SubmitResponse struct {
-	Score      int
-	Comparison string
+	Score      float64          `json:"score"`
+	MaxScore   float64          `json:"max_score"`
+	Percentage float64          `json:"percentage"` // Score as a share of MaxScore, comparable across quizzes
+	Comparison string           `json:"comparison"`
+	Standing   Standing         `json:"standing"`
+	Results    []QuestionResult `json:"results"`
+	// ElapsedSeconds is how long a timed attempt took; zero for untimed submissions.
+	ElapsedSeconds float64 `json:"elapsed_seconds,omitempty"`
+	// Ability is the ability estimated by an adaptive attempt.
+	Ability *AbilityEstimate `json:"ability,omitempty"`
 }
 
-// Question represents the question structure used across the service layer.
+// Answer is a player's answer to one question. Which field carries it depends on the question type:
+// Choice for single-choice, Choices for multi-select, Text for short-answer, Number for numeric,
+// Order for ordering and Matches for matching questions.
+type Answer struct {
+	QuestionID int      `json:"question_id"`
+	Choice     int      `json:"choice"`
+	Choices    []int    `json:"choices,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Number     *float64 `json:"number,omitempty"`
+	Order      []int    `json:"order,omitempty"`   // Every alternative's index, in the player's order
+	Matches    []int    `json:"matches,omitempty"` // For each prompt, an alternative's index or -1
+}
+
+// Result statuses reported per question in a SubmitResponse, next to ResultPartial.
+const (
+	ResultCorrect   = "correct"
+	ResultIncorrect = "incorrect"
+	ResultMissing   = "missing"
+)
+
+// QuestionResult reports how a single question was graded.
+type QuestionResult struct {
+	QuestionID int     `json:"question_id"`
+	Status     string  `json:"status"`
+	Points     float64 `json:"points"`
+	Hints      int     `json:"hints,omitempty"` // Hints revealed; their penalty is already taken off Points
+}
+
+// Question represents the author-facing question structure used across the service layer.
+// It carries the answer key and must only be returned to authors. Type is the discriminator:
+// it decides which of the answer key fields below apply.
 type Question struct {
-	ID            int      `json:"id"`
-	Question      string   `json:"question"`
-	Alternatives  []string `json:"alternatives"`
-	CorrectAnswer int      `json:"correct_answer"`
+	ID               int      `json:"id"`
+	QuizID           int      `json:"quiz_id,omitempty"` // Zero means the default quiz
+	Type             string   `json:"type,omitempty"`    // Empty means QuestionTypeSingle
+	Question         string   `json:"question"`
+	Alternatives     []string `json:"alternatives,omitempty"` // Choice questions
+	TimeLimitSeconds int      `json:"time_limit_seconds,omitempty"`
+	Weight           float64  `json:"weight,omitempty"` // Worth under ScoringWeighted; zero counts as 1
+	Pool             string   `json:"pool,omitempty"`   // Pool the quiz's draw rules pick the question from
+	// Difficulty and Discrimination are item response theory parameters for adaptive quizzes; CalibrateQuiz sets them
+	Difficulty     float64 `json:"difficulty,omitempty"`
+	Discrimination float64 `json:"discrimination,omitempty"`
+	// PinnedAlternatives keep their position when a quiz shuffles alternatives, e.g. "All of the above"
+	PinnedAlternatives []int `json:"pinned_alternatives,omitempty"`
+	// Shown when a finished attempt is reviewed; Feedback, when set, has a remark for each alternative
+	Explanation string   `json:"explanation,omitempty"`
+	Feedback    []string `json:"feedback,omitempty"`
+	// Hints players can reveal one at a time during an attempt; each takes HintPenalty, a share of the
+	// question's point, off what the question can earn
+	Hints       []string `json:"hints,omitempty"`
+	HintPenalty float64  `json:"hint_penalty,omitempty"`
+	// Single-choice questions
+	CorrectAnswer int `json:"correct_answer"`
+	// Multi-select questions
+	CorrectAnswers []int   `json:"correct_answers,omitempty"`
+	Grading        Grading `json:"grading,omitempty"` // Empty means GradingAllOrNothing
+	// Short-answer questions
+	AcceptedAnswers []string `json:"accepted_answers,omitempty"`
+	FuzzyDistance   int      `json:"fuzzy_distance,omitempty"` // Typos tolerated; zero requires an exact match
+	// Numeric questions
+	NumericAnswer *float64      `json:"numeric_answer,omitempty"`
+	Tolerance     float64       `json:"tolerance,omitempty"`
+	ToleranceMode ToleranceMode `json:"tolerance_mode,omitempty"` // Empty means ToleranceAbsolute
+	// Ordering questions; Grading may be GradingKendallTau
+	CorrectOrder []int `json:"correct_order,omitempty"` // Indices of the alternatives in the correct order
+	// Matching questions; Grading defaults to GradingPartial, one share per prompt
+	Prompts        []string `json:"prompts,omitempty"`
+	CorrectMatches []int    `json:"correct_matches,omitempty"` // For each prompt, the index of its alternative
+}
+
+// PlayerQuestion is the player-facing view of a question; it never includes the answer key.
+type PlayerQuestion struct {
+	ID           int      `json:"id"`
+	Type         string   `json:"type,omitempty"`
+	Question     string   `json:"question"`
+	Alternatives []string `json:"alternatives,omitempty"`
+	// The question, alternatives and prompts rendered from Markdown to sanitised HTML, safe to show as is
+	QuestionHTML     string   `json:"question_html,omitempty"`
+	AlternativesHTML []string `json:"alternatives_html,omitempty"`
+	Prompts          []string `json:"prompts,omitempty"` // Matching questions
+	PromptsHTML      []string `json:"prompts_html,omitempty"`
+	TimeLimitSeconds int      `json:"time_limit_seconds,omitempty"`
+	// HintCount hints can be revealed during an attempt, each taking HintPenalty off the question's point
+	HintCount   int     `json:"hint_count,omitempty"`
+	HintPenalty float64 `json:"hint_penalty,omitempty"`
+}
+
+// QuestionPatch holds the fields of a partial question update; nil fields are left unchanged.
+type QuestionPatch struct {
+	QuizID             *int           `json:"quiz_id"`
+	Type               *string        `json:"type"`
+	Question           *string        `json:"question"`
+	Alternatives       *[]string      `json:"alternatives"`
+	CorrectAnswer      *int           `json:"correct_answer"`
+	CorrectAnswers     *[]int         `json:"correct_answers"`
+	Grading            *Grading       `json:"grading"`
+	AcceptedAnswers    *[]string      `json:"accepted_answers"`
+	FuzzyDistance      *int           `json:"fuzzy_distance"`
+	NumericAnswer      *float64       `json:"numeric_answer"`
+	Tolerance          *float64       `json:"tolerance"`
+	ToleranceMode      *ToleranceMode `json:"tolerance_mode"`
+	CorrectOrder       *[]int         `json:"correct_order"`
+	Prompts            *[]string      `json:"prompts"`
+	CorrectMatches     *[]int         `json:"correct_matches"`
+	TimeLimitSeconds   *int           `json:"time_limit_seconds"`
+	Weight             *float64       `json:"weight"`
+	PinnedAlternatives *[]int         `json:"pinned_alternatives"`
+	Pool               *string        `json:"pool"`
+	Difficulty         *float64       `json:"difficulty"`
+	Discrimination     *float64       `json:"discrimination"`
+	Explanation        *string        `json:"explanation"`
+	Feedback           *[]string      `json:"feedback"`
+	Hints              *[]string      `json:"hints"`
+	HintPenalty        *float64       `json:"hint_penalty"`
 }
 
+// Errors returned by the service, re-exported from the repository so callers need not import it.
+var (
+	ErrQuestionNotFound = repository.ErrQuestionNotFound
+	ErrQuestionExists   = repository.ErrQuestionExists
+	ErrInvalidQuestion  = repository.ErrInvalidQuestion
+	ErrInvalidAnswer    = repository.ErrInvalidAnswer
+)
+
+// Errors returned when a submission cannot be graded.
+var (
+	ErrNoQuestions      = errors.New("no questions available")
+	ErrUnknownQuestion  = errors.New("answer references an unknown question")
+	ErrChoiceOutOfRange = errors.New("answer choice is out of range")
+	ErrDuplicateAnswer  = errors.New("question answered more than once")
+)
+
 // QuizService defines the business logic for the quiz.
 type QuizService interface {
-	GetQuestions(ctx context.Context) ([]Question, error)
+	GetQuestions(ctx context.Context) ([]PlayerQuestion, error)
+	GetAuthorQuestions(ctx context.Context) ([]Question, error)
 	SubmitAnswers(ctx context.Context, answers []int) (SubmitResponse, error)
+	SubmitAnswersByID(ctx context.Context, answers []Answer) (SubmitResponse, error)
 	AddQuestion(ctx context.Context, question Question) error
+	UpdateQuestion(ctx context.Context, question Question) (Question, error)
+	PatchQuestion(ctx context.Context, id int, patch QuestionPatch) (Question, error)
+	DeleteQuestion(ctx context.Context, id int) error
+
+	GetQuizzes(ctx context.Context) ([]Quiz, error)
+	GetQuiz(ctx context.Context, id int) (Quiz, error)
+	CreateQuiz(ctx context.Context, quiz Quiz) error
+	UpdateQuiz(ctx context.Context, quiz Quiz) error
+	DeleteQuiz(ctx context.Context, id int) error
+	GetQuizQuestions(ctx context.Context, quizID int) ([]PlayerQuestion, error)
+	GetQuizAuthorQuestions(ctx context.Context, quizID int) ([]Question, error)
+	GetScoreStatistics(ctx context.Context, quizID int, query StatisticsQuery) (Statistics, error)
+	GetItemAnalysis(ctx context.Context, quizID int) ([]ItemAnalysis, error)
+	CalibrateQuiz(ctx context.Context, quizID int) ([]ItemCalibration, error)
+	SubmitQuizAnswers(ctx context.Context, quizID int, answers []Answer) (SubmitResponse, error)
+
+	RegisterPlayer(ctx context.Context, name string) (PlayerRegistration, error)
+	AuthenticatePlayer(ctx context.Context, token string) (Player, error)
+	GetPlayerScores(ctx context.Context) ([]Score, error)
+	GetLeaderboard(ctx context.Context, quizID int, query LeaderboardQuery) (Leaderboard, error)
+	GetDueQuestions(ctx context.Context, quizID int, limit int) ([]DueQuestion, error)
+	RecordReview(ctx context.Context, quizID int, answer Answer) (Review, error)
+
+	StartAttempt(ctx context.Context) (Attempt, error)
+	StartQuizAttempt(ctx context.Context, quizID int) (Attempt, error)
+	GetAttempt(ctx context.Context, id string) (Attempt, error)
+	ServeQuestion(ctx context.Context, attemptID string, questionID int) (PlayerQuestion, error)
+	SaveAnswer(ctx context.Context, attemptID string, answer Answer) error
+	FinishAttempt(ctx context.Context, attemptID string) (SubmitResponse, error)
+	ReviewAttempt(ctx context.Context, attemptID string) (AttemptReview, error)
+	RevealHint(ctx context.Context, attemptID string, questionID int) (Hint, error)
+
+	UploadMedia(ctx context.Context, contentType string, content io.Reader) (Media, error)
+	OpenMedia(ctx context.Context, id string) (Media, io.ReadCloser, error)
 }
 
 type QuizServiceImpl struct {
-	repo repository.Repository
+	repo       repository.Repository
+	now        func() time.Time
+	attemptTTL time.Duration
+	timeLimit  time.Duration // Zero means the quiz is not timed
+	latePolicy LatePolicy
+	scorers    map[Scoring]Scorer
+	rankings   *rankings
+
+	media        repository.MediaStore // Nil when uploads are disabled
+	maxMediaSize int64
 }
 
-// NewQuizService creates a new instance of QuizService with the given repository.
-func NewQuizService(repo repository.Repository) QuizService {
-	return &QuizServiceImpl{repo: repo}
+// DefaultAttemptTTL is how long an attempt stays open unless configured with WithAttemptTTL.
+const DefaultAttemptTTL = time.Hour
+
+// Option configures a QuizServiceImpl.
+type Option func(*QuizServiceImpl)
+
+// WithClock replaces time.Now, so tests can control expiry and deadlines.
+func WithClock(now func() time.Time) Option {
+	return func(q *QuizServiceImpl) {
+		q.now = now
+	}
 }
 
-// GetQuestions fetches all the quiz questions from the repository and maps them to the service layer's question.
-func (q *QuizServiceImpl) GetQuestions(ctx context.Context) ([]Question, error) {
-	repoQuestions, err := q.repo.GetAllQuestions(ctx)
-	if err != nil {
-		return nil, err
+// WithAttemptTTL sets how long an attempt may stay open before it expires.
+func WithAttemptTTL(ttl time.Duration) Option {
+	return func(q *QuizServiceImpl) {
+		q.attemptTTL = ttl
 	}
+}
 
-	serviceQuestions := make([]Question, 0, len(repoQuestions))
+// NewQuizService creates a new instance of QuizService with the given repository.
+func NewQuizService(repo repository.Repository, opts ...Option) QuizService {
+	q := &QuizServiceImpl{
+		repo:       repo,
+		now:        time.Now,
+		attemptTTL: DefaultAttemptTTL,
+		latePolicy: LatePolicyReject,
 
-	// Map repository questions to service questions
-	for _, repoQuestion := range repoQuestions {
-		serviceQuestions = append(serviceQuestions, Question{
-			ID:            repoQuestion.ID,
-			Question:      repoQuestion.QuestionText,
-			Alternatives:  repoQuestion.Alternatives,
-			CorrectAnswer: repoQuestion.CorrectAnswer,
-		})
+		maxMediaSize: DefaultMaxMediaSize,
+		scorers:      builtinScorers(),
+		rankings:     newRankings(),
+	}
+	for _, opt := range opts {
+		opt(q)
 	}
+	return q
+}
+
+// GetQuestions fetches the default quiz's questions for players, without the answer keys.
+func (q *QuizServiceImpl) GetQuestions(ctx context.Context) ([]PlayerQuestion, error) {
+	return q.GetQuizQuestions(ctx, DefaultQuizID)
+}
 
-	return serviceQuestions, nil
+// GetAuthorQuestions fetches the default quiz's questions, including the answer keys.
+func (q *QuizServiceImpl) GetAuthorQuestions(ctx context.Context) ([]Question, error) {
+	return q.GetQuizAuthorQuestions(ctx, DefaultQuizID)
 }
 
-// SubmitAnswers checks the user's answers and calculates the score.
+// SubmitAnswers checks the user's answers to the default quiz by position and calculates the score.
+//
+// Deprecated: positions shift whenever questions are added or removed; use SubmitAnswersByID.
 func (q *QuizServiceImpl) SubmitAnswers(ctx context.Context, answers []int) (SubmitResponse, error) {
-	questions, err := q.GetQuestions(ctx)
+	quiz, questions, err := q.loadQuiz(ctx, DefaultQuizID)
 	if err != nil {
 		return SubmitResponse{}, err
 	}
-
-	// Return an error if no questions are available
-	if len(questions) == 0 {
-		return SubmitResponse{}, errors.New("no questions available")
+	if q.requiresAttempt(quiz, questions) {
+		return SubmitResponse{}, ErrAttemptRequired
 	}
 
-	correctCount := 0
+	// Map answers to questions by position; answers beyond the last question are ignored.
+	// Questions that cannot be answered with a single choice are left unanswered.
+	byID := make(map[int]Answer, len(answers))
+	for i, choice := range answers {
+		if i >= len(questions) {
+			break
+		}
 
-	for i, answer := range answers {
-		if i < len(questions) && answer == questions[i].CorrectAnswer {
-			correctCount++
+		kind, ok := typeOf(questions[i])
+		if !ok {
+			continue
+		}
+		if answer, ok := kind.fromChoice(questions[i].ID, choice); ok {
+			byID[questions[i].ID] = answer
 		}
 	}
 
-	// Calculate comparison against other users
-	scores, err := q.repo.GetAllScores(ctx)
+	return q.grade(ctx, quiz, questions, byID)
+}
+
+// SubmitAnswersByID checks the user's answers to the default quiz, keyed by question ID, and calculates the score.
+func (q *QuizServiceImpl) SubmitAnswersByID(ctx context.Context, answers []Answer) (SubmitResponse, error) {
+	return q.SubmitQuizAnswers(ctx, DefaultQuizID, answers)
+}
+
+// SubmitQuizAnswers checks the user's answers to one quiz, keyed by question ID, and calculates the score.
+// Unknown question IDs, duplicate answers and answers that do not fit their question are rejected.
+func (q *QuizServiceImpl) SubmitQuizAnswers(ctx context.Context, quizID int, answers []Answer) (SubmitResponse, error) {
+	quiz, questions, err := q.loadQuiz(ctx, quizID)
 	if err != nil {
 		return SubmitResponse{}, err
 	}
+	if q.requiresAttempt(quiz, questions) {
+		return SubmitResponse{}, ErrAttemptRequired
+	}
 
-	percentage, err := calculateComparison(scores, correctCount)
+	byQuestionID := make(map[int]Question, len(questions))
+	for _, question := range questions {
+		byQuestionID[question.ID] = question
+	}
+
+	byID := make(map[int]Answer, len(answers))
+	for _, answer := range answers {
+		question, exists := byQuestionID[answer.QuestionID]
+		if !exists {
+			return SubmitResponse{}, fmt.Errorf("%w: %d", ErrUnknownQuestion, answer.QuestionID)
+		}
+		if err := validateAnswer(question, answer); err != nil {
+			return SubmitResponse{}, err
+		}
+		if _, answered := byID[answer.QuestionID]; answered {
+			return SubmitResponse{}, fmt.Errorf("%w: %d", ErrDuplicateAnswer, answer.QuestionID)
+		}
+		byID[answer.QuestionID] = answer
+	}
+
+	return q.grade(ctx, quiz, questions, byID)
+}
+
+// grade scores the answers (question ID to answer) with the quiz's strategy, stores the score and ranks it
+// against previous quizzers. The score is attributed to the player identified in ctx, if any.
+func (q *QuizServiceImpl) grade(ctx context.Context, quiz repository.Quiz, questions []Question, answers map[int]Answer) (SubmitResponse, error) {
+	// Return an error if no questions are available
+	if len(questions) == 0 {
+		return SubmitResponse{}, ErrNoQuestions
+	}
+
+	scorer, err := q.scorer(quiz)
 	if err != nil {
 		return SubmitResponse{}, err
 	}
+	results := gradeAnswers(questions, answers, nil) // Hints are only revealed during attempts
+	score, maxScore := scorer.Score(scoreSheet(quiz, questions, results, 0, 0))
 
-	var comparison string
-	if percentage == -1 {
-		comparison = "You are the first to do the quiz"
-	} else {
-		comparison = fmt.Sprintf("You were better than %d%% of all quizzers", percentage)
+	playerID, _ := PlayerFromContext(ctx)
+	record := repository.ScoreRecord{
+		UserID:     playerID,
+		QuizID:     quiz.ID,
+		Score:      score,
+		MaxScore:   maxScore,
+		RecordedAt: q.now(),
 	}
 
-	// Store the score in the repository
-	err = q.repo.AddScore(ctx, correctCount)
+	// Store the score with its answers and rank it against the quiz's earlier results
+	standing, err := q.recordResult(ctx, repository.Result{
+		Score:   record,
+		Answers: answerRecords(quiz.ID, questions, answers, results, score, maxScore, record.RecordedAt),
+	})
 	if err != nil {
 		return SubmitResponse{}, err
 	}
 
 	return SubmitResponse{
-		Score:      correctCount,
-		Comparison: comparison,
+		Score:      score,
+		MaxScore:   maxScore,
+		Percentage: percentage(score, maxScore),
+		Comparison: standing.Message(),
+		Standing:   standing,
+		Results:    results,
 	}, nil
 }
 
 // AddQuestion converts the service layer question to the repository format and adds it.
 func (q *QuizServiceImpl) AddQuestion(ctx context.Context, question Question) error {
-	repoQuestion := repository.Question{
-		ID:            question.ID,
-		QuestionText:  question.Question,
-		Alternatives:  question.Alternatives,
-		CorrectAnswer: question.CorrectAnswer,
+	if err := validateAnswerKey(question); err != nil {
+		return err
 	}
-	return q.repo.AddQuestion(ctx, repoQuestion)
+	if err := q.checkMediaReferences(ctx, question); err != nil {
+		return err
+	}
+	return q.repo.AddQuestion(ctx, toRepositoryQuestion(question))
 }
 
-// calculateComparison compares the user's score against all other scores.
-func calculateComparison(scores []int, userScore int) (int, error) {
-	if len(scores) == 0 {
-		return -1, nil
+// UpdateQuestion replaces every field of an existing question.
+// A question sent without a quiz stays in the quiz it already belongs to.
+func (q *QuizServiceImpl) UpdateQuestion(ctx context.Context, question Question) (Question, error) {
+	if err := validateAnswerKey(question); err != nil {
+		return Question{}, err
 	}
-
-	betterCount := 0
-	for _, score := range scores {
-		if userScore > score {
-			betterCount++
+	if err := q.checkMediaReferences(ctx, question); err != nil {
+		return Question{}, err
+	}
+	if question.QuizID == 0 {
+		existing, err := q.repo.GetQuestionByID(ctx, question.ID)
+		if err != nil {
+			return Question{}, err
 		}
+		question.QuizID = existing.QuizID
+	}
+	if err := q.repo.UpdateQuestion(ctx, toRepositoryQuestion(question)); err != nil {
+		return Question{}, err
+	}
+	return question, nil
+}
+
+// PatchQuestion applies the non-nil fields of the patch to an existing question and returns the result.
+func (q *QuizServiceImpl) PatchQuestion(ctx context.Context, id int, patch QuestionPatch) (Question, error) {
+	repoQuestion, err := q.repo.GetQuestionByID(ctx, id)
+	if err != nil {
+		return Question{}, err
 	}
 
-	percentage := int(float64(betterCount) / float64(len(scores)) * 100)
-	return percentage, nil
+	question := fromRepositoryQuestion(repoQuestion)
+	if patch.QuizID != nil {
+		question.QuizID = *patch.QuizID
+	}
+	if patch.Type != nil {
+		question.Type = *patch.Type
+	}
+	if patch.Question != nil {
+		question.Question = *patch.Question
+	}
+	if patch.Alternatives != nil {
+		question.Alternatives = *patch.Alternatives
+	}
+	if patch.CorrectAnswer != nil {
+		question.CorrectAnswer = *patch.CorrectAnswer
+	}
+	if patch.CorrectAnswers != nil {
+		question.CorrectAnswers = *patch.CorrectAnswers
+	}
+	if patch.Grading != nil {
+		question.Grading = *patch.Grading
+	}
+	if patch.AcceptedAnswers != nil {
+		question.AcceptedAnswers = *patch.AcceptedAnswers
+	}
+	if patch.FuzzyDistance != nil {
+		question.FuzzyDistance = *patch.FuzzyDistance
+	}
+	if patch.NumericAnswer != nil {
+		question.NumericAnswer = patch.NumericAnswer
+	}
+	if patch.Tolerance != nil {
+		question.Tolerance = *patch.Tolerance
+	}
+	if patch.ToleranceMode != nil {
+		question.ToleranceMode = *patch.ToleranceMode
+	}
+	if patch.CorrectOrder != nil {
+		question.CorrectOrder = *patch.CorrectOrder
+	}
+	if patch.Prompts != nil {
+		question.Prompts = *patch.Prompts
+	}
+	if patch.CorrectMatches != nil {
+		question.CorrectMatches = *patch.CorrectMatches
+	}
+	if patch.TimeLimitSeconds != nil {
+		question.TimeLimitSeconds = *patch.TimeLimitSeconds
+	}
+	if patch.Weight != nil {
+		question.Weight = *patch.Weight
+	}
+	if patch.PinnedAlternatives != nil {
+		question.PinnedAlternatives = *patch.PinnedAlternatives
+	}
+	if patch.Pool != nil {
+		question.Pool = *patch.Pool
+	}
+	if patch.Difficulty != nil {
+		question.Difficulty = *patch.Difficulty
+	}
+	if patch.Discrimination != nil {
+		question.Discrimination = *patch.Discrimination
+	}
+	if patch.Explanation != nil {
+		question.Explanation = *patch.Explanation
+	}
+	if patch.Feedback != nil {
+		question.Feedback = *patch.Feedback
+	}
+	if patch.Hints != nil {
+		question.Hints = *patch.Hints
+	}
+	if patch.HintPenalty != nil {
+		question.HintPenalty = *patch.HintPenalty
+	}
+
+	if err := validateAnswerKey(question); err != nil {
+		return Question{}, err
+	}
+	if err := q.checkMediaReferences(ctx, question); err != nil {
+		return Question{}, err
+	}
+	if err := q.repo.UpdateQuestion(ctx, toRepositoryQuestion(question)); err != nil {
+		return Question{}, err
+	}
+	return question, nil
+}
+
+// DeleteQuestion removes a question by its ID.
+func (q *QuizServiceImpl) DeleteQuestion(ctx context.Context, id int) error {
+	return q.repo.DeleteQuestion(ctx, id)
+}
+
+// toRepositoryQuestion maps a service layer question to the repository format.
+func toRepositoryQuestion(question Question) repository.Question {
+	repoQuestion := repository.Question{
+		ID:           question.ID,
+		QuizID:       question.QuizID,
+		Type:         question.Type,
+		QuestionText: question.Question,
+		Alternatives: question.Alternatives,
+		TimeLimit:    time.Duration(question.TimeLimitSeconds) * time.Second,
+		Weight:       question.Weight,
+		Pool:         question.Pool,
+
+		Difficulty:         question.Difficulty,
+		Discrimination:     question.Discrimination,
+		PinnedAlternatives: question.PinnedAlternatives,
+		Explanation:        question.Explanation,
+		Feedback:           question.Feedback,
+		Hints:              question.Hints,
+		HintPenalty:        question.HintPenalty,
+		CorrectAnswer:      question.CorrectAnswer,
+		CorrectAnswers:     question.CorrectAnswers,
+		Grading:            string(question.Grading),
+		AcceptedAnswers:    question.AcceptedAnswers,
+		FuzzyDistance:      question.FuzzyDistance,
+		Tolerance:          question.Tolerance,
+		ToleranceMode:      string(question.ToleranceMode),
+		CorrectOrder:       question.CorrectOrder,
+		Prompts:            question.Prompts,
+		CorrectMatches:     question.CorrectMatches,
+	}
+	if question.NumericAnswer != nil {
+		repoQuestion.NumericAnswer = *question.NumericAnswer
+	}
+	return repoQuestion
+}
+
+// toPlayerQuestion strips the answer key from a question.
+func toPlayerQuestion(question Question) PlayerQuestion {
+	return PlayerQuestion{
+		ID:               question.ID,
+		Type:             question.Type,
+		Question:         question.Question,
+		Alternatives:     question.Alternatives,
+		QuestionHTML:     renderMarkdown(question.Question),
+		AlternativesHTML: renderAll(question.Alternatives),
+		Prompts:          question.Prompts,
+		PromptsHTML:      renderAll(question.Prompts),
+		TimeLimitSeconds: question.TimeLimitSeconds,
+		HintCount:        len(question.Hints),
+		HintPenalty:      question.HintPenalty,
+	}
+}
+
+// fromRepositoryQuestion maps a repository question to the service layer format.
+func fromRepositoryQuestion(repoQuestion repository.Question) Question {
+	question := Question{
+		ID:               repoQuestion.ID,
+		QuizID:           repoQuestion.QuizID,
+		Type:             repoQuestion.Type,
+		Question:         repoQuestion.QuestionText,
+		Alternatives:     repoQuestion.Alternatives,
+		TimeLimitSeconds: int(repoQuestion.TimeLimit / time.Second),
+		Weight:           repoQuestion.Weight,
+		Pool:             repoQuestion.Pool,
+
+		Difficulty:         repoQuestion.Difficulty,
+		Discrimination:     repoQuestion.Discrimination,
+		PinnedAlternatives: repoQuestion.PinnedAlternatives,
+		Explanation:        repoQuestion.Explanation,
+		Feedback:           repoQuestion.Feedback,
+		Hints:              repoQuestion.Hints,
+		HintPenalty:        repoQuestion.HintPenalty,
+		CorrectAnswer:      repoQuestion.CorrectAnswer,
+		CorrectAnswers:     repoQuestion.CorrectAnswers,
+		Grading:            Grading(repoQuestion.Grading),
+		AcceptedAnswers:    repoQuestion.AcceptedAnswers,
+		FuzzyDistance:      repoQuestion.FuzzyDistance,
+		Tolerance:          repoQuestion.Tolerance,
+		ToleranceMode:      ToleranceMode(repoQuestion.ToleranceMode),
+		CorrectOrder:       repoQuestion.CorrectOrder,
+		Prompts:            repoQuestion.Prompts,
+		CorrectMatches:     repoQuestion.CorrectMatches,
+	}
+	if repoQuestion.Type == QuestionTypeNumeric {
+		numericAnswer := repoQuestion.NumericAnswer
+		question.NumericAnswer = &numericAnswer
+	}
+	return question
 }
@@ -6,8 +6,42 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// testFixture is what a service test seeds its repository with.
+type testFixture struct {
+	Quizzes   []Quiz
+	Questions []Question
+	Users     []repository.User
+	Scores    []repository.ScoreRecord // Scores without a quiz belong to the default quiz
+}
+
+// newSeededService returns a service over a new in-memory repository holding the fixture.
+func newSeededService(t *testing.T, fixture testFixture, opts ...Option) (QuizService, repository.Repository) {
+	t.Helper()
+	ctx := context.Background()
+	repo := repository.NewRepository()
+	svc := NewQuizService(repo, opts...)
+
+	for _, quiz := range fixture.Quizzes {
+		require.NoError(t, svc.CreateQuiz(ctx, quiz))
+	}
+	for _, question := range fixture.Questions {
+		require.NoError(t, svc.AddQuestion(ctx, question))
+	}
+	for _, user := range fixture.Users {
+		require.NoError(t, repo.AddUser(ctx, user))
+	}
+	for _, record := range fixture.Scores {
+		if record.QuizID == 0 {
+			record.QuizID = DefaultQuizID
+		}
+		require.NoError(t, repo.AddScore(ctx, record))
+	}
+	return svc, repo
+}
+
 func TestQuizService_GetQuestions(t *testing.T) {
 	// Use the real repository
 	repo := repository.NewRepository()
@@ -90,7 +124,7 @@ func TestQuizService_SubmitAnswers(t *testing.T) {
 
 	// Assertions
 	assert.NoError(t, err)
-	assert.Equal(t, 1, submitResponse.Score, "The score should be 1")
+	assert.Equal(t, 1.0, submitResponse.Score, "The score should be 1")
 	assert.Contains(t, submitResponse.Comparison, "You were better than", "The comparison message should be generated")
 
 	// Verify that the score was added to the repository
@@ -110,3 +144,107 @@ func TestQuizService_SubmitAnswers_Error(t *testing.T) {
 	// Assertions
 	assert.Error(t, err, "Fetching questions should return an error when no questions exist")
 }
+
+func TestQuizService_PatchQuestion(t *testing.T) {
+	// Use the real repository
+	repo := repository.NewRepository()
+	svc := NewQuizService(repo)
+
+	err := svc.AddQuestion(context.Background(), Question{
+		ID:            1,
+		Question:      "What is the capital of Frence?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	})
+	assert.NoError(t, err)
+
+	// Only the question text changes
+	text := "What is the capital of France?"
+	patched, err := svc.PatchQuestion(context.Background(), 1, QuestionPatch{Question: &text})
+	assert.NoError(t, err, "Patching an existing question should not return an error")
+	assert.Equal(t, text, patched.Question, "The question text should be updated")
+	assert.Equal(t, []string{"Berlin", "Madrid", "Paris", "Rome"}, patched.Alternatives, "The alternatives should be untouched")
+	assert.Equal(t, 2, patched.CorrectAnswer, "The correct answer should be untouched")
+
+	// Shrinking the alternatives would leave the correct answer dangling
+	alternatives := []string{"Berlin", "Madrid"}
+	_, err = svc.PatchQuestion(context.Background(), 1, QuestionPatch{Alternatives: &alternatives})
+	assert.ErrorIs(t, err, ErrInvalidAnswer, "Invalidating the correct answer should be rejected")
+
+	// Patching a missing question
+	_, err = svc.PatchQuestion(context.Background(), 999, QuestionPatch{Question: &text})
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+}
+
+func TestQuizService_DeleteQuestion(t *testing.T) {
+	// Use the real repository
+	repo := repository.NewRepository()
+	svc := NewQuizService(repo)
+
+	err := svc.AddQuestion(context.Background(), Question{
+		ID:            1,
+		Question:      "What is the capital of France?",
+		Alternatives:  []string{"Berlin", "Madrid", "Paris", "Rome"},
+		CorrectAnswer: 2,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.DeleteQuestion(context.Background(), 1), "Deleting an existing question should not return an error")
+	assert.ErrorIs(t, svc.DeleteQuestion(context.Background(), 1), ErrQuestionNotFound)
+
+	questions, err := svc.GetQuestions(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, questions, "No questions should remain")
+}
+
+func TestQuizService_SubmitAnswersByID(t *testing.T) {
+	// Use the real repository
+	repo := repository.NewRepository()
+	svc := NewQuizService(repo)
+
+	for _, question := range []repository.Question{
+		{ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"1", "2", "3"}, CorrectAnswer: 1},
+		{ID: 5, QuestionText: "What is 2 + 2?", Alternatives: []string{"3", "4", "5"}, CorrectAnswer: 1},
+		{ID: 9, QuestionText: "What is 3 + 3?", Alternatives: []string{"5", "6", "7"}, CorrectAnswer: 1},
+	} {
+		assert.NoError(t, repo.AddQuestion(context.Background(), question))
+	}
+
+	// Answers arrive out of order; question 9 is skipped
+	submitResponse, err := svc.SubmitAnswersByID(context.Background(), []Answer{
+		{QuestionID: 5, Choice: 0},
+		{QuestionID: 1, Choice: 1},
+	})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, submitResponse.Score, "Only question 1 was answered correctly")
+	assert.Equal(t, []QuestionResult{
+		{QuestionID: 1, Status: ResultCorrect, Points: 1},
+		{QuestionID: 5, Status: ResultIncorrect},
+		{QuestionID: 9, Status: ResultMissing},
+	}, submitResponse.Results, "Each question should be reported by ID")
+}
+
+func TestQuizService_SubmitAnswersByID_Invalid(t *testing.T) {
+	// Use the real repository
+	repo := repository.NewRepository()
+	svc := NewQuizService(repo)
+
+	question := repository.Question{ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"1", "2", "3"}, CorrectAnswer: 1}
+	assert.NoError(t, repo.AddQuestion(context.Background(), question))
+
+	_, err := svc.SubmitAnswersByID(context.Background(), []Answer{{QuestionID: 42, Choice: 0}})
+	assert.ErrorIs(t, err, ErrUnknownQuestion, "Unknown question IDs should be rejected")
+
+	_, err = svc.SubmitAnswersByID(context.Background(), []Answer{{QuestionID: 1, Choice: 3}})
+	assert.ErrorIs(t, err, ErrChoiceOutOfRange, "Out-of-range choices should be rejected")
+
+	_, err = svc.SubmitAnswersByID(context.Background(), []Answer{{QuestionID: 1, Choice: 1}, {QuestionID: 1, Choice: 2}})
+	assert.ErrorIs(t, err, ErrDuplicateAnswer, "Answering a question twice should be rejected")
+
+	// Rejected submissions must not be recorded
+	scores, err := repo.GetAllScores(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, scores, "No score should be stored for rejected submissions")
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// newSeed returns a random seed for an attempt's shuffles.
+func newSeed() (int64, error) {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(buf[:])), nil
+}
+
+// shuffleQuestionIDs puts question IDs in the order the seed picks. The same seed always picks the same order.
+func shuffleQuestionIDs(ids []int, seed int64) {
+	random := mathrand.New(mathrand.NewSource(seed))
+	random.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+}
+
+// shuffleQuestions shuffles the question IDs with the seed and returns the questions in the same order.
+func shuffleQuestions(questions []Question, ids []int, seed int64) []Question {
+	byID := make(map[int]Question, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+
+	shuffleQuestionIDs(ids, seed)
+	shuffled := make([]Question, 0, len(ids))
+	for _, id := range ids {
+		shuffled = append(shuffled, byID[id])
+	}
+	return shuffled
+}
+
+// alternativeOrder returns the order an attempt shows a question's alternatives in: position i shows the
+// alternative whose canonical index is order[i]. Pinned alternatives keep their position. Every question
+// of an attempt gets its own order from the attempt's seed.
+func alternativeOrder(question Question, seed int64) []int {
+	order := make([]int, len(question.Alternatives))
+	for i := range order {
+		order[i] = i
+	}
+
+	pinned := make(map[int]bool, len(question.PinnedAlternatives))
+	for _, index := range question.PinnedAlternatives {
+		pinned[index] = true
+	}
+	free := make([]int, 0, len(order))
+	for i := range order {
+		if !pinned[i] {
+			free = append(free, i)
+		}
+	}
+
+	shuffled := append([]int(nil), free...)
+	random := mathrand.New(mathrand.NewSource(seed ^ int64(question.ID)*1_000_003))
+	random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	for i, position := range free {
+		order[position] = shuffled[i]
+	}
+	return order
+}
+
+// shuffler maps between what an attempt shows and the canonical alternative indices answers are stored in.
+// The zero shuffler, for attempts that do not shuffle alternatives, maps everything to itself.
+type shuffler struct {
+	seed    int64
+	enabled bool
+}
+
+// attemptShuffler returns the shuffler of a stored attempt.
+func attemptShuffler(attempt repository.Attempt) shuffler {
+	return shuffler{seed: attempt.Seed, enabled: attempt.ShuffleAlternatives}
+}
+
+// present returns the question as the attempt shows it, with its alternatives in the attempt's order.
+func (s shuffler) present(question Question) PlayerQuestion {
+	view := toPlayerQuestion(question)
+	view.Alternatives = s.arrange(question, question.Alternatives)
+	view.AlternativesHTML = s.arrange(question, view.AlternativesHTML)
+	return view
+}
+
+// arrange puts values, one for each of the question's alternatives, in the order the attempt shows the alternatives.
+func (s shuffler) arrange(question Question, values []string) []string {
+	if !s.enabled || len(values) == 0 || len(values) != len(question.Alternatives) {
+		return values
+	}
+
+	order := alternativeOrder(question, s.seed)
+	arranged := make([]string, len(order))
+	for position, index := range order {
+		arranged[position] = values[index]
+	}
+	return arranged
+}
+
+// toCanonical maps an answer given against the shown alternatives to their canonical indices.
+// The answer must already be valid for the question.
+func (s shuffler) toCanonical(question Question, answer Answer) Answer {
+	if !s.enabled || len(question.Alternatives) == 0 {
+		return answer
+	}
+	kind, ok := typeOf(question)
+	if !ok {
+		return answer
+	}
+
+	order := alternativeOrder(question, s.seed)
+	return kind.mapAlternatives(answer, func(position int) int { return lookup(order, position) })
+}
+
+// toShown maps an answer stored with canonical indices to the positions the attempt shows them at.
+func (s shuffler) toShown(question Question, answer Answer) Answer {
+	if !s.enabled || len(question.Alternatives) == 0 {
+		return answer
+	}
+	kind, ok := typeOf(question)
+	if !ok {
+		return answer
+	}
+
+	order := alternativeOrder(question, s.seed)
+	positions := make([]int, len(order))
+	for position, index := range order {
+		positions[index] = position
+	}
+	return kind.mapAlternatives(answer, func(index int) int { return lookup(positions, index) })
+}
+
+// lookup returns mapping[i], or i itself when the question changed since the answer was saved and i is out of range.
+func lookup(mapping []int, i int) int {
+	if i < 0 || i >= len(mapping) {
+		return i
+	}
+	return mapping[i]
+}
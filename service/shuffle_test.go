@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestAlternativeOrder(t *testing.T) {
+	question := Question{
+		ID:                 7,
+		Alternatives:       []string{"a", "b", "c", "d", "e", "All of the above"},
+		PinnedAlternatives: []int{5},
+	}
+
+	// The same seed always gives the same order, and every alternative appears once
+	order := alternativeOrder(question, 42)
+	assert.Equal(t, order, alternativeOrder(question, 42))
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5}, order)
+
+	// Pinned alternatives never move, whatever the seed
+	for seed := int64(0); seed < 50; seed++ {
+		assert.Equal(t, 5, alternativeOrder(question, seed)[5])
+	}
+}
+
+func TestShuffler_RoundTrip(t *testing.T) {
+	shuffle := shuffler{seed: 99, enabled: true}
+	for _, tc := range []struct {
+		name     string
+		question Question
+		answer   Answer
+	}{
+		{"single", Question{ID: 1, Alternatives: []string{"a", "b", "c", "d"}}, Answer{QuestionID: 1, Choice: 2}},
+		{"multi", Question{ID: 2, Type: QuestionTypeMulti, Alternatives: []string{"a", "b", "c", "d"}}, Answer{QuestionID: 2, Choices: []int{0, 3}}},
+		{"ordering", Question{ID: 3, Type: QuestionTypeOrdering, Alternatives: []string{"a", "b", "c"}}, Answer{QuestionID: 3, Order: []int{2, 0, 1}}},
+		{"matching", Question{ID: 4, Type: QuestionTypeMatching, Prompts: []string{"x", "y"}, Alternatives: []string{"a", "b", "c"}}, Answer{QuestionID: 4, Matches: []int{1, -1}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			canonical := shuffle.toCanonical(tc.question, tc.answer)
+			assert.Equal(t, tc.answer, shuffle.toShown(tc.question, canonical))
+
+			// The shown alternative and the canonical one are the same text
+			shown := shuffle.present(tc.question)
+			if tc.answer.Choices != nil {
+				for i, position := range tc.answer.Choices {
+					assert.Equal(t, shown.Alternatives[position], tc.question.Alternatives[canonical.Choices[i]])
+				}
+			}
+		})
+	}
+
+	// The zero shuffler leaves everything alone
+	question := Question{ID: 1, Alternatives: []string{"a", "b", "c"}}
+	assert.Equal(t, question.Alternatives, shuffler{}.present(question).Alternatives)
+	assert.Equal(t, Answer{QuestionID: 1, Choice: 2}, shuffler{}.toCanonical(question, Answer{QuestionID: 1, Choice: 2}))
+}
+
+func TestQuizService_ShuffledAttempt(t *testing.T) {
+	repo := repository.NewRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.AddQuiz(ctx, repository.Quiz{ID: 2, Name: "Shuffled", ShuffleQuestions: true, ShuffleAlternatives: true}))
+	canonical := []string{"zero", "one", "two", "three", "None of these"}
+	for id := 1; id <= 6; id++ {
+		require.NoError(t, repo.AddQuestion(ctx, repository.Question{
+			ID: id, QuizID: 2, QuestionText: "Pick one", Alternatives: canonical, CorrectAnswer: id % 4, PinnedAlternatives: []int{4},
+		}))
+	}
+	svc := NewQuizService(repo)
+
+	attempt, err := svc.StartQuizAttempt(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, attempt.Questions, 6)
+	stored, err := repo.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stored.Seed, attempt.Seed)
+	assert.True(t, stored.ShuffleAlternatives)
+
+	// Answer every question correctly by picking the shown position of the correct alternative
+	for _, question := range attempt.Questions {
+		assert.Equal(t, "None of these", question.Alternatives[4], "Pinned alternatives keep their position")
+		served, err := svc.ServeQuestion(ctx, attempt.ID, question.ID)
+		require.NoError(t, err)
+		assert.Equal(t, question.Alternatives, served.Alternatives, "Serving a question again shows the same order")
+
+		position := indexOf(question.Alternatives, canonical[question.ID%4])
+		require.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: question.ID, Choice: position}))
+	}
+
+	// Resuming shows the saved answers at the positions they were given at
+	resumed, err := svc.GetAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, attempt.Questions, resumed.Questions)
+	for i, answer := range resumed.Answers {
+		question := resumed.Questions[i]
+		assert.Equal(t, canonical[question.ID%4], question.Alternatives[answer.Choice])
+	}
+
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, result.Score)
+
+	// Without an attempt there is no order to answer against, so shuffled quizzes cannot be submitted directly
+	_, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.ErrorIs(t, err, ErrAttemptRequired)
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Shuffled", ShuffleAlternatives: true}))
+	_, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.ErrorIs(t, err, ErrAttemptRequired)
+	require.NoError(t, svc.UpdateQuiz(ctx, Quiz{ID: 2, Name: "Shuffled"}))
+	_, err = svc.SubmitQuizAnswers(ctx, 2, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.NoError(t, err, "Unshuffled quizzes can still be submitted directly")
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
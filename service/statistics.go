@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// DefaultHistogramBuckets is the number of histogram buckets statistics use when the query sets none.
+const DefaultHistogramBuckets = 10
+
+// maxHistogramBuckets bounds the histogram so a query cannot ask for an arbitrarily large response.
+const maxHistogramBuckets = 100
+
+// StatisticsQuery selects the scores of a quiz to summarise.
+type StatisticsQuery struct {
+	From    time.Time // Only scores recorded at or after From; zero means from the first score
+	To      time.Time // Only scores recorded before To; zero means up to now
+	Buckets int       // Histogram buckets; zero means DefaultHistogramBuckets
+}
+
+// Statistics summarises the recorded scores of one quiz, each as a percentage of its maximum score.
+// Scores recorded before maxima were tracked are left out.
+type Statistics struct {
+	QuizID    int               `json:"quiz_id"`
+	From      *time.Time        `json:"from,omitempty"`
+	To        *time.Time        `json:"to,omitempty"`
+	Count     int               `json:"count"`
+	Mean      float64           `json:"mean"`
+	Median    float64           `json:"median"`
+	StdDev    float64           `json:"std_dev"` // Population standard deviation
+	Min       float64           `json:"min"`
+	Max       float64           `json:"max"`
+	Q1        float64           `json:"q1"`
+	Q3        float64           `json:"q3"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// HistogramBucket counts the scores between two percentages of the maximum score. Buckets include their lower
+// bound and exclude their upper one, except the last, which also holds perfect scores.
+type HistogramBucket struct {
+	FromPercent float64 `json:"from_percent"`
+	ToPercent   float64 `json:"to_percent"`
+	Count       int     `json:"count"`
+}
+
+// ErrInvalidStatistics is returned for statistics queries with an empty time range or an unusable bucket count.
+var ErrInvalidStatistics = errors.New("invalid statistics query")
+
+// GetScoreStatistics summarises the scores of a quiz. The repository does the aggregation, so the scores
+// themselves are never loaded into the service.
+func (q *QuizServiceImpl) GetScoreStatistics(ctx context.Context, quizID int, query StatisticsQuery) (Statistics, error) {
+	if query.Buckets == 0 {
+		query.Buckets = DefaultHistogramBuckets
+	}
+	if query.Buckets < 0 || query.Buckets > maxHistogramBuckets {
+		return Statistics{}, fmt.Errorf("%w: buckets must be between 1 and %d", ErrInvalidStatistics, maxHistogramBuckets)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return Statistics{}, fmt.Errorf("%w: from must be before to", ErrInvalidStatistics)
+	}
+
+	if _, err := q.repo.GetQuiz(ctx, quizID); err != nil {
+		return Statistics{}, err
+	}
+	stats, err := q.repo.GetScoreStats(ctx, repository.ScoreFilter{
+		QuizID:  quizID,
+		From:    query.From,
+		To:      query.To,
+		Buckets: query.Buckets,
+	})
+	if err != nil {
+		return Statistics{}, err
+	}
+
+	statistics := Statistics{
+		QuizID:    quizID,
+		Count:     stats.Count,
+		Mean:      stats.Mean,
+		Median:    stats.Median,
+		StdDev:    stats.StdDev,
+		Min:       stats.Min,
+		Max:       stats.Max,
+		Q1:        stats.Q1,
+		Q3:        stats.Q3,
+		Histogram: make([]HistogramBucket, 0, len(stats.Histogram)),
+	}
+	if !query.From.IsZero() {
+		statistics.From = &query.From
+	}
+	if !query.To.IsZero() {
+		statistics.To = &query.To
+	}
+
+	width := 100 / float64(len(stats.Histogram))
+	for i, count := range stats.Histogram {
+		statistics.Histogram = append(statistics.Histogram, HistogramBucket{
+			FromPercent: float64(i) * width,
+			ToPercent:   float64(i+1) * width,
+			Count:       count,
+		})
+	}
+	return statistics, nil
+}
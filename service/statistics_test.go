@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestQuizService_GetScoreStatistics(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	repo := repository.NewRepository()
+	for i, score := range []float64{0, 1, 2, 3, 4} {
+		require.NoError(t, repo.AddScore(ctx, repository.ScoreRecord{
+			QuizID: DefaultQuizID, Score: score, MaxScore: 4, RecordedAt: base.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+	svc := NewQuizService(repo)
+
+	statistics, err := svc.GetScoreStatistics(ctx, DefaultQuizID, StatisticsQuery{Buckets: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 5, statistics.Count)
+	assert.Equal(t, []float64{50, 50, 0, 100, 25, 75}, []float64{statistics.Mean, statistics.Median, statistics.Min, statistics.Max, statistics.Q1, statistics.Q3})
+	assert.InDelta(t, 35.355, statistics.StdDev, 1e-3)
+	assert.Equal(t, []HistogramBucket{
+		{FromPercent: 0, ToPercent: 25, Count: 1},
+		{FromPercent: 25, ToPercent: 50, Count: 1},
+		{FromPercent: 50, ToPercent: 75, Count: 1},
+		{FromPercent: 75, ToPercent: 100, Count: 2},
+	}, statistics.Histogram)
+	assert.Nil(t, statistics.From)
+
+	// The range keeps its start and drops its end
+	from, to := base.Add(time.Hour), base.Add(3*time.Hour)
+	statistics, err = svc.GetScoreStatistics(ctx, DefaultQuizID, StatisticsQuery{From: from, To: to})
+	require.NoError(t, err)
+	assert.Equal(t, 2, statistics.Count)
+	assert.Equal(t, 37.5, statistics.Mean)
+	assert.Equal(t, &from, statistics.From)
+	assert.Equal(t, &to, statistics.To)
+	assert.Len(t, statistics.Histogram, DefaultHistogramBuckets)
+}
+
+func TestQuizService_GetScoreStatistics_InvalidQuery(t *testing.T) {
+	svc := NewQuizService(repository.NewRepository())
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, query := range []StatisticsQuery{{Buckets: -1}, {Buckets: 101}, {From: now, To: now}, {From: now, To: now.Add(-time.Hour)}} {
+		_, err := svc.GetScoreStatistics(ctx, DefaultQuizID, query)
+		assert.ErrorIs(t, err, ErrInvalidStatistics, "%+v", query)
+	}
+
+	_, err := svc.GetScoreStatistics(ctx, 999, StatisticsQuery{})
+	assert.ErrorIs(t, err, ErrQuizNotFound)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"fasttrack/quiz-app/repository"
+)
+
+// LatePolicy decides what happens when an answer arrives after an attempt's deadline.
+type LatePolicy string
+
+const (
+	// LatePolicyReject rejects late answers; the player can still finish with the answers saved in time.
+	LatePolicyReject LatePolicy = "reject"
+	// LatePolicyAutoFinalize finishes the attempt at its deadline as soon as anything late reaches the server.
+	LatePolicyAutoFinalize LatePolicy = "auto-finalize"
+)
+
+// Errors returned when a timed quiz is answered too late, or a quiz that needs attempts is bypassed.
+var (
+	ErrDeadlinePassed  = errors.New("deadline has passed")
+	ErrAttemptRequired = errors.New("timed, pooled, adaptive and shuffled quizzes must be taken through an attempt")
+)
+
+// WithTimeLimit sets how long a player has to finish an attempt; zero disables the quiz deadline.
+func WithTimeLimit(limit time.Duration) Option {
+	return func(q *QuizServiceImpl) {
+		q.timeLimit = limit
+	}
+}
+
+// WithLatePolicy sets how answers that arrive after the quiz deadline are handled.
+func WithLatePolicy(policy LatePolicy) Option {
+	return func(q *QuizServiceImpl) {
+		q.latePolicy = policy
+	}
+}
+
+// quizTimeLimit returns the quiz's own time limit, or the server default when it has none.
+func (q *QuizServiceImpl) quizTimeLimit(quiz repository.Quiz) time.Duration {
+	if quiz.TimeLimit > 0 {
+		return quiz.TimeLimit
+	}
+	return q.timeLimit
+}
+
+// quizLatePolicy returns the quiz's own late policy, or the server default when it has none.
+func (q *QuizServiceImpl) quizLatePolicy(quiz repository.Quiz) LatePolicy {
+	if quiz.LatePolicy != "" {
+		return LatePolicy(quiz.LatePolicy)
+	}
+	return q.latePolicy
+}
+
+// isTimed reports whether the quiz or any of its questions has a time limit.
+// Timed quizzes can only be taken through attempts, where the server keeps the clock.
+func (q *QuizServiceImpl) isTimed(quiz repository.Quiz, questions []Question) bool {
+	if q.quizTimeLimit(quiz) > 0 {
+		return true
+	}
+	for _, question := range questions {
+		if question.TimeLimitSeconds > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// deadlinePassed reports whether a timed attempt's deadline has been reached.
+func deadlinePassed(attempt repository.Attempt, now time.Time) bool {
+	return !attempt.Deadline.IsZero() && !now.Before(attempt.Deadline)
+}
+
+// autoFinalizes reports whether the attempt should be finished at its deadline because of its late policy.
+func autoFinalizes(attempt repository.Attempt, now time.Time) bool {
+	return !attempt.Finished() && LatePolicy(attempt.LatePolicy) == LatePolicyAutoFinalize && deadlinePassed(attempt, now)
+}
+
+// questionTimeUp reports whether a question's own time limit has run out.
+// The clock starts when the question was served, or when the attempt started if it never was.
+func questionTimeUp(attempt repository.Attempt, question repository.Question, now time.Time) bool {
+	if question.TimeLimit <= 0 {
+		return false
+	}
+
+	start, served := attempt.ServedAt[question.ID]
+	if !served {
+		start = attempt.StartedAt
+	}
+	return !now.Before(start.Add(question.TimeLimit))
+}
+
+// checkOpen returns the error for interacting with an attempt that is no longer open.
+func checkOpen(attempt repository.Attempt, now time.Time) error {
+	switch {
+	case attempt.Finished():
+		return ErrAttemptFinished
+	case deadlinePassed(attempt, now):
+		return ErrDeadlinePassed
+	case !now.Before(attempt.ExpiresAt):
+		return ErrAttemptExpired
+	default:
+		return nil
+	}
+}
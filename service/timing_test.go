@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fasttrack/quiz-app/repository"
+)
+
+func TestQuizService_TimeLimit_Reject(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc, repo := newSeededService(t, arithmeticFixture, WithClock(clock.Now), WithTimeLimit(10*time.Minute), WithLatePolicy(LatePolicyReject))
+	ctx := context.Background()
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, attempt.Deadline, "A timed attempt should report its deadline")
+	assert.Equal(t, clock.Now().Add(10*time.Minute), *attempt.Deadline)
+
+	clock.Advance(4 * time.Minute)
+	assert.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+
+	// A late answer is rejected but the attempt stays open for finishing
+	clock.Advance(7 * time.Minute)
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}), ErrDeadlinePassed)
+
+	result, err := svc.FinishAttempt(ctx, attempt.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, result.Score, "Only the answer saved in time should count")
+	assert.Equal(t, (10 * time.Minute).Seconds(), result.ElapsedSeconds, "A late finish counts as finishing at the deadline")
+
+	// The elapsed time is stored next to the score
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	require.Len(t, scores, 1)
+	assert.Equal(t, 10*time.Minute, scores[0].Elapsed)
+}
+
+func TestQuizService_TimeLimit_AutoFinalize(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc, repo := newSeededService(t, arithmeticFixture, WithClock(clock.Now), WithTimeLimit(10*time.Minute), WithLatePolicy(LatePolicyAutoFinalize))
+	ctx := context.Background()
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}))
+
+	// The late answer is refused and the attempt is finished at its deadline
+	clock.Advance(15 * time.Minute)
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}), ErrDeadlinePassed)
+
+	finished, err := svc.GetAttempt(ctx, attempt.ID)
+	assert.NoError(t, err)
+	assert.True(t, finished.Finished, "The attempt should have been finalised")
+	require.NotNil(t, finished.Result)
+	assert.Equal(t, 1.0, finished.Result.Score)
+
+	scores, err := repo.GetAllScores(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, scores, 1, "Finalising should record the score once")
+
+	// Even an untouched attempt is finalised when it is read after the deadline
+	untouched, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+	clock.Advance(time.Hour)
+	resumed, err := svc.GetAttempt(ctx, untouched.ID)
+	assert.NoError(t, err)
+	assert.True(t, resumed.Finished, "Reading a late attempt should finalise it")
+}
+
+func TestQuizService_QuestionTimeLimit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	repo := repository.NewRepository()
+	svc := NewQuizService(repo, WithClock(clock.Now))
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddQuestion(ctx, repository.Question{
+		ID: 1, QuestionText: "What is 1 + 1?", Alternatives: []string{"1", "2"}, CorrectAnswer: 1, TimeLimit: 30 * time.Second,
+	}))
+	require.NoError(t, repo.AddQuestion(ctx, repository.Question{
+		ID: 2, QuestionText: "What is 2 + 2?", Alternatives: []string{"3", "4"}, CorrectAnswer: 1, TimeLimit: 30 * time.Second,
+	}))
+
+	attempt, err := svc.StartAttempt(ctx)
+	require.NoError(t, err)
+
+	// The timer of a served question starts when it is served
+	clock.Advance(5 * time.Minute)
+	question, err := svc.ServeQuestion(ctx, attempt.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, question.TimeLimitSeconds)
+
+	clock.Advance(20 * time.Second)
+	assert.NoError(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 1}), "Answering within 30 seconds should succeed")
+
+	clock.Advance(20 * time.Second)
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 1, Choice: 0}), ErrDeadlinePassed, "Changing the answer after 30 seconds should fail")
+
+	// A question that was never served is timed from the start of the attempt
+	assert.ErrorIs(t, svc.SaveAnswer(ctx, attempt.ID, Answer{QuestionID: 2, Choice: 1}), ErrDeadlinePassed)
+
+	// Timed quizzes cannot be bypassed with the stateless submission
+	_, err = svc.SubmitAnswersByID(ctx, []Answer{{QuestionID: 1, Choice: 1}})
+	assert.ErrorIs(t, err, ErrAttemptRequired)
+}